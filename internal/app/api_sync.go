@@ -0,0 +1,128 @@
+package app
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/service"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	"github.com/cuiyuanxin/kunpeng/pkg/logger"
+	"github.com/swaggo/swag"
+	"go.uber.org/zap"
+)
+
+// syncAPIs 收集已注册的路由，并按配置在启动时同步到API表
+func (a *App) syncAPIs() {
+	summaries := swaggerSummaries()
+
+	var routes []*dto.RouteInfo
+	for _, r := range a.engine.Routes() {
+		// 仅同步业务接口，忽略swagger、健康检查等路由
+		if !strings.HasPrefix(r.Path, "/api/") {
+			continue
+		}
+
+		name := summaries[r.Method+" "+swaggerPath(r.Path)]
+		if name == "" {
+			name = r.Method + " " + r.Path
+		}
+
+		routes = append(routes, &dto.RouteInfo{
+			Method: r.Method,
+			Path:   r.Path,
+			Group:  routeGroup(r.Path),
+			Name:   name,
+		})
+	}
+
+	apiService := service.GetAPIService()
+	apiService.SetRoutes(routes)
+
+	if !config.GetAppConfig().APISync {
+		return
+	}
+
+	resp, err := apiService.SyncRoutes(false)
+	if err != nil {
+		logger.GetLogger().Error("同步路由到API表失败", zap.Error(err))
+		return
+	}
+
+	logger.GetLogger().Info("同步路由到API表完成",
+		zap.Int("新增", len(resp.Added)),
+		zap.Int("失效", len(resp.Stale)),
+		zap.Int("恢复", len(resp.Recovered)),
+	)
+}
+
+// swaggerSummaries 从swagger文档中读取接口摘要，键为 "METHOD /path/{param}"
+func swaggerSummaries() map[string]string {
+	summaries := make(map[string]string)
+
+	doc, err := swag.ReadDoc()
+	if err != nil {
+		return summaries
+	}
+
+	var swagger struct {
+		Paths map[string]map[string]struct {
+			Summary string `json:"summary"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal([]byte(doc), &swagger); err != nil {
+		logger.GetLogger().Warn("解析swagger文档失败", zap.Error(err))
+		return summaries
+	}
+
+	for path, operations := range swagger.Paths {
+		for method, operation := range operations {
+			summaries[strings.ToUpper(method)+" "+path] = operation.Summary
+		}
+	}
+
+	return summaries
+}
+
+// swaggerPath 将gin路由路径转换为swagger路径，如 /users/:id 转换为 /users/{id}
+func swaggerPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// routeGroup 根据路径获取API分组，如 /api/v1/users/:id 的分组为 users
+func routeGroup(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		// 跳过 api 前缀和版本号
+		if i == 0 && segment == "api" {
+			continue
+		}
+		if i == 1 && segments[0] == "api" && isVersion(segment) {
+			continue
+		}
+		if segment == "" || strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			break
+		}
+		return segment
+	}
+	return "default"
+}
+
+// isVersion 判断路径片段是否为版本号，如 v1
+func isVersion(segment string) bool {
+	if len(segment) < 2 || segment[0] != 'v' {
+		return false
+	}
+	for _, ch := range segment[1:] {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -11,10 +11,13 @@ import (
 
 	"github.com/cuiyuanxin/kunpeng/internal/middleware"
 	"github.com/cuiyuanxin/kunpeng/internal/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/task"
 	"github.com/cuiyuanxin/kunpeng/pkg/casbin"
 	"github.com/cuiyuanxin/kunpeng/pkg/config"
 	"github.com/cuiyuanxin/kunpeng/pkg/database"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
 	"github.com/cuiyuanxin/kunpeng/pkg/logger"
+	"github.com/cuiyuanxin/kunpeng/pkg/revocation"
 	"github.com/cuiyuanxin/kunpeng/pkg/tracer"
 	"github.com/cuiyuanxin/kunpeng/pkg/validator"
 	"github.com/gin-gonic/gin"
@@ -37,6 +40,11 @@ func New(configPath string) *App {
 	// 初始化日志
 	_ = logger.Init()
 
+	// 初始化JWT签名密钥
+	if err := jwt.Init(); err != nil {
+		panic(fmt.Sprintf("初始化JWT签名密钥失败: %v", err))
+	}
+
 	// 初始化数据库
 	if err := database.Init(); err != nil {
 		panic(fmt.Sprintf("初始化数据库失败: %v", err))
@@ -45,6 +53,11 @@ func New(configPath string) *App {
 	// 初始化仓储层
 	repository.Init()
 
+	// 初始化token撤销存储
+	if err := revocation.Init(repository.GetTokenBlacklistRepository()); err != nil {
+		panic(fmt.Sprintf("初始化token撤销存储失败: %v", err))
+	}
+
 	// 初始化验证器（使用i18n支持）
 	validator.InitI18n()
 
@@ -53,6 +66,11 @@ func New(configPath string) *App {
 		panic(fmt.Sprintf("初始化Casbin失败: %v", err))
 	}
 
+	// 启动定时任务
+	if err := task.Start(); err != nil {
+		panic(fmt.Sprintf("启动定时任务失败: %v", err))
+	}
+
 	// 初始化链路追踪（如果启用）
 	if config.GetAppConfig().TraceEnable {
 		tracer.Init()
@@ -74,6 +92,11 @@ func New(configPath string) *App {
 	// 创建Gin引擎
 	engine := gin.New()
 
+	// 仅信任配置的代理转发的客户端IP请求头，登录锁定、验证码发送等按IP的限制依赖真实的客户端IP
+	if err := engine.SetTrustedProxies(config.GetServerConfig().TrustedProxies); err != nil {
+		panic(fmt.Sprintf("设置可信代理失败: %v", err))
+	}
+
 	// 注册中间件
 	registerMiddlewares(engine)
 
@@ -87,6 +110,9 @@ func (a *App) Run() {
 	// 注册路由
 	a.RegisterRoutes()
 
+	// 同步路由到API表
+	a.syncAPIs()
+
 	// 获取服务器配置
 	serverConfig := config.Get().Server
 
@@ -148,6 +174,15 @@ func (a *App) gracefulShutdown() {
 		logger.GetLogger().Info("服务器已正常关闭")
 	}
 
+	// 停止定时任务，等待正在执行的任务结束
+	task.Stop()
+
+	// 停止策略变更监听
+	casbin.Close()
+
+	// 停止token撤销记录同步
+	revocation.Close()
+
 	// 关闭数据库连接
 	logger.GetLogger().Info("正在关闭数据库连接...")
 	database.Close()
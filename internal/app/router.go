@@ -4,6 +4,7 @@ import (
 	"github.com/cuiyuanxin/kunpeng/internal/controller"
 	"github.com/cuiyuanxin/kunpeng/internal/middleware"
 	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -23,33 +24,70 @@ func (a *App) RegisterRoutes() {
 		})
 	})
 
+	// JWT验证公钥
+	a.engine.GET("/.well-known/jwks.json", controller.GetJWKSController().GetJWKS)
+
 	// API v1
 	v1 := a.engine.Group("/api/v1")
 	{
 		// 无需认证的接口
+		v1.GET("/captcha", controller.GetCaptchaController().GetCaptcha)
+		v1.POST("/sms/code", controller.GetCaptchaController().SendSMSCode)
 		v1.POST("/login", controller.GetUserController().Login)
+		v1.POST("/login/2fa", controller.GetUserController().LoginTwoFactor)
+		v1.POST("/login/2fa/setup", controller.GetUserController().SetupLoginTwoFactor)
+		v1.GET("/login/oidc", controller.GetOIDCController().Authorize)
+		v1.POST("/login/oidc", controller.GetUserController().LoginOIDC)
 		v1.POST("/refresh-token", controller.GetUserController().RefreshToken)
 		v1.POST("/logout", controller.GetUserController().Logout)
+		v1.POST("/password/forgot", controller.GetPasswordController().Forgot)
+		v1.POST("/password/reset", controller.GetPasswordController().Reset)
+
+		// 修改密码接口同时接受修改密码凭证，必须修改密码的用户登录后只能调用该接口
+		v1.PUT("/users/password", middleware.JWT(jwt.PasswordTokenType), controller.GetUserController().ChangePassword)
+
+		// 需要认证的接口（仅校验登录状态，所有登录用户可访问）
+		login := v1.Group("")
+		login.Use(middleware.JWT())
+		{
+			login.GET("/user/info", controller.GetUserController().GetUserInfo)
+			login.GET("/menus/user", controller.GetMenuController().GetUserMenuTree)
+			login.GET("/user/2fa", controller.GetTwoFactorController().GetStatus)
+			login.POST("/user/2fa/setup", controller.GetTwoFactorController().Setup)
+			login.POST("/user/2fa/enable", controller.GetTwoFactorController().Enable)
+			login.POST("/user/2fa/disable", controller.GetTwoFactorController().Disable)
+			login.POST("/user/2fa/recovery-codes", controller.GetTwoFactorController().RegenerateRecoveryCodes)
+			login.GET("/user/sessions", controller.GetSessionController().GetSessions)
+			login.DELETE("/user/sessions/:id", controller.GetSessionController().RevokeSession)
+			login.GET("/user/oidc", controller.GetOIDCController().GetIdentity)
+			login.POST("/user/oidc/authorize", controller.GetOIDCController().LinkAuthorize)
+			login.POST("/user/oidc/link", controller.GetOIDCController().Link)
+			login.DELETE("/user/oidc", controller.GetOIDCController().Unlink)
+			login.POST("/user/app-secret/rotate", controller.GetAppSecretController().Rotate)
+		}
 
-		// 需要认证的接口
+		// 需要认证及接口权限校验的接口，服务端之间的调用可使用AppKey/AppSecret签名代替Bearer token
 		auth := v1.Group("")
-		auth.Use(middleware.JWT())
+		auth.Use(middleware.JWTOrSignature(), middleware.Casbin())
 		{
 			// 用户相关接口
-			auth.GET("/user/info", controller.GetUserController().GetUserInfo)
 			auth.GET("/users", controller.GetUserController().GetUserList)
 			auth.GET("/users/:id", controller.GetUserController().GetUserByID)
-			auth.POST("/users", controller.GetUserController().CreateUser)
-			auth.PUT("/users", controller.GetUserController().UpdateUser)
-			auth.DELETE("/users/:id", controller.GetUserController().DeleteUser)
-			auth.DELETE("/users/batch", controller.GetUserController().BatchDeleteUser)
-			auth.PUT("/users/status", controller.GetUserController().ChangeUserStatus)
-			auth.PUT("/users/:id/password/reset", controller.GetUserController().ResetUserPassword)
-			auth.PUT("/users/password", controller.GetUserController().ChangePassword)
+			auth.POST("/users", middleware.RequirePerm("system:user:add"), controller.GetUserController().CreateUser)
+			auth.PUT("/users", middleware.RequirePerm("system:user:edit"), controller.GetUserController().UpdateUser)
+			auth.DELETE("/users/:id", middleware.RequirePerm("system:user:remove"), controller.GetUserController().DeleteUser)
+			auth.DELETE("/users/batch", middleware.RequirePerm("system:user:remove"), controller.GetUserController().BatchDeleteUser)
+			auth.PUT("/users/status", middleware.RequirePerm("system:user:edit"), controller.GetUserController().ChangeUserStatus)
+			auth.PUT("/users/:id/password/reset", middleware.RequirePerm("system:user:resetPwd"), controller.GetUserController().ResetUserPassword)
+			auth.PUT("/users/:id/2fa/reset", middleware.RequirePerm("system:user:reset2fa"), controller.GetTwoFactorController().ResetUserTwoFactor)
+			auth.GET("/users/:id/sessions", middleware.RequirePerm("system:user:forceLogout"), controller.GetSessionController().GetUserSessions)
+			auth.DELETE("/users/:id/sessions", middleware.RequirePerm("system:user:forceLogout"), controller.GetSessionController().ForceLogout)
+			auth.PUT("/users/:id/app-secret/reset", middleware.RequirePerm("system:user:resetSecret"), controller.GetAppSecretController().ResetUserSecret)
 
 			// 角色相关接口
 			auth.GET("/roles", controller.GetRoleController().GetRoleList)
 			auth.GET("/roles/all", controller.GetRoleController().GetAllRoles)
+			auth.GET("/roles/tree", controller.GetRoleController().GetRoleTree)
 			auth.GET("/roles/:id", controller.GetRoleController().GetRoleByID)
 			auth.POST("/roles", controller.GetRoleController().CreateRole)
 			auth.PUT("/roles", controller.GetRoleController().UpdateRole)
@@ -60,11 +98,13 @@ func (a *App) RegisterRoutes() {
 			auth.PUT("/roles/menus", controller.GetRoleController().AssignMenus)
 			auth.GET("/roles/:id/apis", controller.GetRoleController().GetRoleAPIs)
 			auth.PUT("/roles/apis", controller.GetRoleController().UpdateRoleAPIs)
+			auth.GET("/roles/:id/data-scope", controller.GetRoleController().GetRoleDataScope)
+			auth.PUT("/roles/data-scope", controller.GetRoleController().UpdateRoleDataScope)
+			auth.POST("/roles/policies/rebuild", controller.GetRoleController().RebuildPolicies)
 
 			// 菜单相关接口
 			auth.GET("/menus", controller.GetMenuController().GetMenuList)
 			auth.GET("/menus/tree", controller.GetMenuController().GetMenuTree)
-			auth.GET("/menus/user", controller.GetMenuController().GetUserMenuTree)
 			auth.GET("/menus/:id", controller.GetMenuController().GetMenuByID)
 			auth.POST("/menus", controller.GetMenuController().CreateMenu)
 			auth.PUT("/menus", controller.GetMenuController().UpdateMenu)
@@ -78,6 +118,11 @@ func (a *App) RegisterRoutes() {
 			auth.PUT("/apis", controller.GetAPIController().UpdateAPI)
 			auth.DELETE("/apis/:id", controller.GetAPIController().DeleteAPI)
 			auth.DELETE("/apis/batch", controller.GetAPIController().BatchDeleteAPI)
+			auth.GET("/apis/sync", controller.GetAPIController().GetRouteSyncDiff)
+			auth.POST("/apis/sync", controller.GetAPIController().SyncRoutes)
+
+			// 权限相关接口
+			auth.GET("/permissions/explain", controller.GetPermissionController().Explain)
 
 			// 部门相关接口
 			auth.GET("/depts", controller.GetDeptController().GetDeptList)
@@ -119,12 +164,23 @@ func (a *App) RegisterRoutes() {
 			auth.DELETE("/login-logs/batch", controller.GetLoginLogController().BatchDeleteLoginLog)
 			auth.POST("/login-logs/clean", controller.GetLoginLogController().CleanOldLogs)
 
+			// 登录锁定相关接口
+			auth.GET("/login-locks", controller.GetLoginLockController().GetLoginLockList)
+			auth.DELETE("/login-locks/:id", middleware.RequirePerm("system:user:unlock"), controller.GetLoginLockController().Unlock)
+
 			// 操作日志相关接口
 			auth.GET("/operation-logs", controller.GetOperationLogController().GetOperationLogList)
 			auth.GET("/operation-logs/:id", controller.GetOperationLogController().GetOperationLogByID)
 			auth.DELETE("/operation-logs/:id", controller.GetOperationLogController().DeleteOperationLog)
 			auth.DELETE("/operation-logs/batch", controller.GetOperationLogController().BatchDeleteOperationLog)
 			auth.POST("/operation-logs/clean", controller.GetOperationLogController().CleanOldLogs)
+
+			// 定时任务相关接口
+			auth.GET("/jobs", controller.GetJobController().GetJobList)
+			auth.GET("/jobs/logs", controller.GetJobController().GetJobLogList)
+			auth.POST("/jobs/:name/run", middleware.RequirePerm("monitor:job:run"), controller.GetJobController().RunJob)
+			auth.PUT("/jobs/:name/pause", middleware.RequirePerm("monitor:job:edit"), controller.GetJobController().PauseJob)
+			auth.PUT("/jobs/:name/resume", middleware.RequirePerm("monitor:job:edit"), controller.GetJobController().ResumeJob)
 		}
 	}
 }
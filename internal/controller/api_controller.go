@@ -3,6 +3,7 @@ package controller
 import (
 	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
 	"github.com/cuiyuanxin/kunpeng/internal/service"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
 	"github.com/cuiyuanxin/kunpeng/pkg/response"
 	"github.com/cuiyuanxin/kunpeng/pkg/validator"
 	"github.com/gin-gonic/gin"
@@ -150,7 +151,7 @@ func (c *APIController) UpdateAPI(ctx *gin.Context) {
 	}
 
 	// 调用服务
-	err := service.GetAPIService().UpdateAPI(&req)
+	err := service.GetAPIService().UpdateAPI(jwt.GetUserID(ctx), &req)
 	if err != nil {
 		response.FailWithError(ctx, err)
 		return
@@ -179,7 +180,7 @@ func (c *APIController) DeleteAPI(ctx *gin.Context) {
 	}
 
 	// 调用服务
-	err := service.GetAPIService().DeleteAPI(req.ID)
+	err := service.GetAPIService().DeleteAPI(jwt.GetUserID(ctx), req.ID)
 	if err != nil {
 		response.FailWithError(ctx, err)
 		return
@@ -209,7 +210,7 @@ func (c *APIController) BatchDeleteAPI(ctx *gin.Context) {
 	}
 
 	// 调用服务
-	err := service.GetAPIService().BatchDeleteAPI(req.IDs)
+	err := service.GetAPIService().BatchDeleteAPI(jwt.GetUserID(ctx), req.IDs)
 	if err != nil {
 		response.FailWithError(ctx, err)
 		return
@@ -217,3 +218,47 @@ func (c *APIController) BatchDeleteAPI(ctx *gin.Context) {
 
 	response.Ok(ctx)
 }
+
+// GetRouteSyncDiff 获取路由同步差异
+// @Summary 获取路由同步差异
+// @Description 对比已注册路由与API表，返回待新增、待标记失效和待恢复的API，不修改数据
+// @Tags API管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=dto.APISyncResp} "成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/apis/sync [get]
+func (c *APIController) GetRouteSyncDiff(ctx *gin.Context) {
+	// 调用服务
+	resp, err := service.GetAPIService().SyncRoutes(true)
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
+
+// SyncRoutes 同步路由到API表
+// @Summary 同步路由到API表
+// @Description 新增缺失的API，标记路由已不存在的API为失效
+// @Tags API管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=dto.APISyncResp} "成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/apis/sync [post]
+func (c *APIController) SyncRoutes(ctx *gin.Context) {
+	// 调用服务
+	resp, err := service.GetAPIService().SyncRoutes(false)
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/service"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/cuiyuanxin/kunpeng/pkg/response"
+	"github.com/cuiyuanxin/kunpeng/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// AppSecretController AppSecret控制器
+type AppSecretController struct{}
+
+// Rotate 轮换当前用户的AppSecret
+// @Summary 轮换AppSecret
+// @Description 校验当前密码或双因素认证动态码后为当前用户生成新的AppSecret，AppKey保持不变。新的AppSecret仅返回一次，配置了过渡期时原AppSecret在过渡期内仍可用于签名；修改密码、被强制下线等使登录失效的操作会同时使AppSecret失效，需重新轮换
+// @Tags 签名认证
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param data body dto.AppSecretRotateReq true "轮换AppSecret请求"
+// @Success 200 {object} response.Response{data=dto.AppSecretResp} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/user/app-secret/rotate [post]
+func (c *AppSecretController) Rotate(ctx *gin.Context) {
+	var req dto.AppSecretRotateReq
+	if err := validator.BindAndValidateJSONI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	resp, err := service.GetAPISignatureService().RotateSecret(jwt.GetUserID(ctx), &req)
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
+
+// ResetUserSecret 重置用户的AppSecret
+// @Summary 重置用户的AppSecret
+// @Description 管理员重置用户的AppSecret，用于AppSecret泄露等情况。原AppSecret立即失效，新的AppSecret不返回，用户需自行轮换后才能继续使用签名认证
+// @Tags 签名认证
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "禁止访问"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/users/{id}/app-secret/reset [put]
+func (c *AppSecretController) ResetUserSecret(ctx *gin.Context) {
+	var req dto.IDReq
+	if err := validator.BindAndValidateUriI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	// 内置用户仅允许本人或超级管理员操作，且用户需在数据权限范围内
+	if err := service.GetUserService().CheckUserManageable(jwt.GetUserID(ctx), req.ID); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	if err := service.GetAPISignatureService().ResetSecret(req.ID); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.Ok(ctx)
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/service"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/response"
+	"github.com/cuiyuanxin/kunpeng/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// CaptchaController 验证码控制器
+type CaptchaController struct{}
+
+// GetCaptcha 获取图片验证码
+// @Summary 获取图片验证码
+// @Description 生成Base64编码的PNG图片验证码，验证码在有效期内只能使用一次
+// @Tags 验证码
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=dto.CaptchaResp} "成功"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/captcha [get]
+func (c *CaptchaController) GetCaptcha(ctx *gin.Context) {
+	resp, err := service.GetCaptchaService().Generate()
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
+
+// SendSMSCode 发送登录短信验证码
+// @Summary 发送登录短信验证码
+// @Description 向手机号发送6位数字登录验证码，同一手机号需间隔一段时间才能重新发送，手机号和IP在统计周期内的发送次数有上限
+// @Tags 验证码
+// @Accept json
+// @Produce json
+// @Param data body dto.SMSCodeReq true "发送短信验证码请求"
+// @Success 200 {object} response.Response{data=dto.SMSCodeResp} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/sms/code [post]
+func (c *CaptchaController) SendSMSCode(ctx *gin.Context) {
+	var req dto.SMSCodeReq
+	if err := validator.BindAndValidateJSONI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	// 自定义验证手机号格式（支持多语言）
+	if err := req.Validate(); err != nil {
+		response.FailWithMessage(ctx, kperrors.ErrParam, err.Error())
+		return
+	}
+
+	resp, err := service.GetSMSService().SendCode(&req, model.SMSSceneLogin, ctx.ClientIP())
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
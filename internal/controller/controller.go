@@ -2,6 +2,11 @@ package controller
 
 import (
 	"sync"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/service"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/gin-gonic/gin"
 )
 
 var (
@@ -14,6 +19,16 @@ var (
 	dictController         DictController
 	loginLogController     LoginLogController
 	operationLogController OperationLogController
+	permissionController   PermissionController
+	captchaController      CaptchaController
+	twoFactorController    TwoFactorController
+	sessionController      SessionController
+	jwksController         JWKSController
+	jobController          JobController
+	loginLockController    LoginLockController
+	passwordController     PasswordController
+	oidcController         OIDCController
+	appSecretController    AppSecretController
 	once                   sync.Once
 )
 
@@ -71,6 +86,66 @@ func GetOperationLogController() *OperationLogController {
 	return &operationLogController
 }
 
+// GetPermissionController 获取权限控制器
+func GetPermissionController() *PermissionController {
+	once.Do(initController)
+	return &permissionController
+}
+
+// GetCaptchaController 获取验证码控制器
+func GetCaptchaController() *CaptchaController {
+	once.Do(initController)
+	return &captchaController
+}
+
+// GetTwoFactorController 获取双因素认证控制器
+func GetTwoFactorController() *TwoFactorController {
+	once.Do(initController)
+	return &twoFactorController
+}
+
+// GetSessionController 获取登录会话控制器
+func GetSessionController() *SessionController {
+	once.Do(initController)
+	return &sessionController
+}
+
+// GetJWKSController 获取JWT公钥控制器
+func GetJWKSController() *JWKSController {
+	once.Do(initController)
+	return &jwksController
+}
+
+// GetJobController 获取定时任务控制器
+func GetJobController() *JobController {
+	once.Do(initController)
+	return &jobController
+}
+
+// GetLoginLockController 获取登录锁定控制器
+func GetLoginLockController() *LoginLockController {
+	once.Do(initController)
+	return &loginLockController
+}
+
+// GetPasswordController 获取找回密码控制器
+func GetPasswordController() *PasswordController {
+	once.Do(initController)
+	return &passwordController
+}
+
+// GetOIDCController 获取单点登录控制器
+func GetOIDCController() *OIDCController {
+	once.Do(initController)
+	return &oidcController
+}
+
+// GetAppSecretController 获取AppSecret控制器
+func GetAppSecretController() *AppSecretController {
+	once.Do(initController)
+	return &appSecretController
+}
+
 // initController 初始化控制器
 func initController() {
 	userController = UserController{}
@@ -82,4 +157,19 @@ func initController() {
 	dictController = DictController{}
 	loginLogController = LoginLogController{}
 	operationLogController = OperationLogController{}
+	permissionController = PermissionController{}
+	captchaController = CaptchaController{}
+	twoFactorController = TwoFactorController{}
+	sessionController = SessionController{}
+	jwksController = JWKSController{}
+	jobController = JobController{}
+	loginLockController = LoginLockController{}
+	passwordController = PasswordController{}
+	oidcController = OIDCController{}
+	appSecretController = AppSecretController{}
+}
+
+// getDataScope 获取当前登录用户的数据权限范围
+func getDataScope(ctx *gin.Context) (*dto.DataScope, error) {
+	return service.GetRoleService().GetDataScope(jwt.GetUserID(ctx))
 }
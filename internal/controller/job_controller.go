@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/task"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/cuiyuanxin/kunpeng/pkg/response"
+	"github.com/cuiyuanxin/kunpeng/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// JobController 定时任务控制器
+type JobController struct{}
+
+// GetJobList 获取定时任务列表
+// @Summary 获取定时任务列表
+// @Description 获取已注册的定时任务及其状态、下一次执行时间和最近一次执行结果
+// @Tags 定时任务
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=[]dto.JobResp} "成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/jobs [get]
+func (c *JobController) GetJobList(ctx *gin.Context) {
+	jobs, err := task.GetScheduler().List()
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, jobs)
+}
+
+// RunJob 立即执行定时任务
+// @Summary 立即执行定时任务
+// @Description 在当前实例上异步执行一次定时任务，已暂停的任务也可执行，执行结果可在执行记录中查看
+// @Tags 定时任务
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "任务名称"
+// @Success 200 {object} response.Response "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/jobs/{name}/run [post]
+func (c *JobController) RunJob(ctx *gin.Context) {
+	var req dto.JobNameReq
+	if err := validator.BindAndValidateUriI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	if err := task.GetScheduler().Trigger(req.Name, jwt.GetUsername(ctx)); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithMessage(ctx, "任务已开始执行")
+}
+
+// PauseJob 暂停定时任务
+// @Summary 暂停定时任务
+// @Description 暂停定时任务的计划执行，对全部实例生效
+// @Tags 定时任务
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "任务名称"
+// @Success 200 {object} response.Response "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/jobs/{name}/pause [put]
+func (c *JobController) PauseJob(ctx *gin.Context) {
+	var req dto.JobNameReq
+	if err := validator.BindAndValidateUriI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	if err := task.GetScheduler().Pause(req.Name); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.Ok(ctx)
+}
+
+// ResumeJob 恢复定时任务
+// @Summary 恢复定时任务
+// @Description 恢复已暂停的定时任务，对全部实例生效
+// @Tags 定时任务
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "任务名称"
+// @Success 200 {object} response.Response "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/jobs/{name}/resume [put]
+func (c *JobController) ResumeJob(ctx *gin.Context) {
+	var req dto.JobNameReq
+	if err := validator.BindAndValidateUriI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	if err := task.GetScheduler().Resume(req.Name); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.Ok(ctx)
+}
+
+// GetJobLogList 获取定时任务执行记录
+// @Summary 获取定时任务执行记录
+// @Description 分页查询定时任务的执行记录
+// @Tags 定时任务
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param page_num query int true "页码" default(1)
+// @Param page_size query int true "每页数量" default(10)
+// @Param job_name query string false "任务名称"
+// @Param status query int false "执行状态 0:失败 1:成功 2:执行中"
+// @Param trigger query string false "触发方式 schedule, manual"
+// @Param begin_time query string false "开始时间"
+// @Param end_time query string false "结束时间"
+// @Success 200 {object} response.Response{data=dto.PageResp{list=[]dto.JobLogResp}} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/jobs/logs [get]
+func (c *JobController) GetJobLogList(ctx *gin.Context) {
+	req := &dto.JobLogListReq{}
+	if err := validator.BindAndValidateQueryI18n(ctx, req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	result, err := task.GetScheduler().GetLogList(req)
+	if err != nil {
+		response.FailWithCode(ctx, kperrors.ErrJobLogGetList)
+		return
+	}
+
+	response.OkWithData(ctx, result)
+}
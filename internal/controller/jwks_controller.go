@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSController JWT公钥控制器
+type JWKSController struct{}
+
+// GetJWKS 获取JWT验证公钥
+// @Summary 获取JWT验证公钥
+// @Description 以JWKS格式返回全部验证公钥，供其他服务按token header中的kid验证签名。使用HS256签名时返回空列表。按RFC 7517直接返回JWKS，不使用统一响应格式
+// @Tags 认证
+// @Produce json
+// @Success 200 {object} jwt.JWKS "成功"
+// @Router /.well-known/jwks.json [get]
+func (c *JWKSController) GetJWKS(ctx *gin.Context) {
+	// 允许下游服务短时间缓存，密钥轮换时新旧公钥会同时发布
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, jwt.GetJWKS())
+}
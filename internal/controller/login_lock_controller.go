@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/service"
+	"github.com/cuiyuanxin/kunpeng/pkg/response"
+	"github.com/cuiyuanxin/kunpeng/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// LoginLockController 登录锁定控制器
+type LoginLockController struct{}
+
+// GetLoginLockList 获取登录锁定列表
+// @Summary 获取登录锁定列表
+// @Description 分页查询因登录失败次数过多而处于锁定中的账号及IP
+// @Tags 登录锁定
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param page_num query int true "页码" default(1)
+// @Param page_size query int true "每页数量" default(10)
+// @Param scope query string false "锁定范围 account:账号 ip:IP"
+// @Param subject query string false "账号或IP"
+// @Success 200 {object} response.Response{data=dto.PageResp{list=[]dto.LoginLockResp}} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/login-locks [get]
+func (c *LoginLockController) GetLoginLockList(ctx *gin.Context) {
+	req := &dto.LoginLockListReq{}
+	if err := validator.BindAndValidateQueryI18n(ctx, req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	result, err := service.GetLoginAttemptService().GetLockedList(req)
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, result)
+}
+
+// Unlock 解除登录锁定
+// @Summary 解除登录锁定
+// @Description 解除账号或IP的登录锁定并清零失败次数
+// @Tags 登录锁定
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "锁定记录ID"
+// @Success 200 {object} response.Response "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/login-locks/{id} [delete]
+func (c *LoginLockController) Unlock(ctx *gin.Context) {
+	var req dto.IDReq
+	if err := validator.BindAndValidateUriI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	if err := service.GetLoginAttemptService().Unlock(req.ID); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.Ok(ctx)
+}
@@ -48,6 +48,14 @@ func (c *LoginLogController) GetLoginLogList(ctx *gin.Context) {
 		req.PageSize = 10
 	}
 
+	// 按当前用户的数据权限过滤
+	dataScope, err := getDataScope(ctx)
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+	req.DataScope = dataScope
+
 	// 获取登录日志列表
 	result, err := service.GetLoginLogService().GetLoginLogList(req)
 	if err != nil {
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/service"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/cuiyuanxin/kunpeng/pkg/response"
+	"github.com/cuiyuanxin/kunpeng/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCController 单点登录控制器
+type OIDCController struct{}
+
+// Authorize 获取单点登录授权地址
+// @Summary 获取单点登录授权地址
+// @Description 生成使用PKCE的授权码模式授权地址，前端跳转到该地址，身份提供方回调后调用单点登录接口完成登录
+// @Tags 单点登录
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=dto.OIDCAuthorizeResp} "成功"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/login/oidc [get]
+func (c *OIDCController) Authorize(ctx *gin.Context) {
+	resp, err := service.GetOIDCService().Authorize(0, ctx.ClientIP())
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
+
+// GetIdentity 获取当前用户的单点登录身份关联状态
+// @Summary 获取身份关联状态
+// @Description 获取是否启用单点登录及当前用户是否已关联身份提供方的身份
+// @Tags 单点登录
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=dto.OIDCIdentityResp} "成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/user/oidc [get]
+func (c *OIDCController) GetIdentity(ctx *gin.Context) {
+	resp, err := service.GetOIDCService().GetIdentity(jwt.GetUserID(ctx))
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
+
+// LinkAuthorize 获取关联身份的授权地址
+// @Summary 获取关联身份的授权地址
+// @Description 生成授权地址，身份提供方回调后调用关联身份接口，将身份关联到当前用户
+// @Tags 单点登录
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=dto.OIDCAuthorizeResp} "成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/user/oidc/authorize [post]
+func (c *OIDCController) LinkAuthorize(ctx *gin.Context) {
+	resp, err := service.GetOIDCService().Authorize(jwt.GetUserID(ctx), ctx.ClientIP())
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
+
+// Link 关联身份
+// @Summary 关联身份
+// @Description 提交身份提供方回调返回的授权码和state，将身份关联到当前用户，关联后可使用单点登录
+// @Tags 单点登录
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param data body dto.OIDCCallbackReq true "单点登录回调请求"
+// @Success 200 {object} response.Response "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/user/oidc/link [post]
+func (c *OIDCController) Link(ctx *gin.Context) {
+	var req dto.OIDCCallbackReq
+	if err := validator.BindAndValidateJSONI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	if err := service.GetOIDCService().Link(jwt.GetUserID(ctx), &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.Ok(ctx)
+}
+
+// Unlink 解除身份关联
+// @Summary 解除身份关联
+// @Description 解除当前用户关联的身份，未设置密码的账号不能解除
+// @Tags 单点登录
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response "成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/user/oidc [delete]
+func (c *OIDCController) Unlink(ctx *gin.Context) {
+	if err := service.GetOIDCService().Unlink(jwt.GetUserID(ctx)); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.Ok(ctx)
+}
@@ -22,6 +22,14 @@ func (c *OperationLogController) GetOperationLogList(ctx *gin.Context) {
 		return
 	}
 
+	// 按当前用户的数据权限过滤
+	dataScope, err := getDataScope(ctx)
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+	req.DataScope = dataScope
+
 	operationLogService := service.GetOperationLogService()
 	result, err := operationLogService.GetOperationLogList(&req)
 	if err != nil {
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/service"
+	"github.com/cuiyuanxin/kunpeng/pkg/response"
+	"github.com/cuiyuanxin/kunpeng/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordController 找回密码控制器
+type PasswordController struct{}
+
+// Forgot 发送重置密码邮件
+// @Summary 发送重置密码邮件
+// @Description 向用户名或邮箱对应账号绑定的邮箱发送一次性重置链接，账号是否存在均返回相同结果，邮件语言按Accept-Language选择
+// @Tags 找回密码
+// @Accept json
+// @Produce json
+// @Param data body dto.PasswordForgotReq true "找回密码请求"
+// @Success 200 {object} response.Response{data=dto.PasswordForgotResp} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/password/forgot [post]
+func (c *PasswordController) Forgot(ctx *gin.Context) {
+	var req dto.PasswordForgotReq
+	if err := validator.BindAndValidateJSONI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	resp, err := service.GetPasswordResetService().Forgot(&req, ctx.ClientIP(), ctx.GetHeader("Accept-Language"))
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
+
+// Reset 通过邮件中的凭证重置密码
+// @Summary 重置密码
+// @Description 校验重置凭证并设置新密码，凭证只能使用一次，重置成功后用户的全部登录会话失效
+// @Tags 找回密码
+// @Accept json
+// @Produce json
+// @Param data body dto.PasswordResetReq true "重置密码请求"
+// @Success 200 {object} response.Response "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/password/reset [post]
+func (c *PasswordController) Reset(ctx *gin.Context) {
+	var req dto.PasswordResetReq
+	if err := validator.BindAndValidateJSONI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	if err := service.GetPasswordResetService().Reset(&req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.Ok(ctx)
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/service"
+	"github.com/cuiyuanxin/kunpeng/pkg/response"
+	"github.com/cuiyuanxin/kunpeng/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionController 权限控制器
+type PermissionController struct{}
+
+// Explain 权限判定说明
+// @Summary 权限判定说明
+// @Description 按用户或角色评估指定接口的访问权限，返回判定结果、命中的策略、角色链、数据权限以及API是否禁用
+// @Tags 权限管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id query int false "用户ID，与角色ID二选一"
+// @Param role_id query int false "角色ID"
+// @Param method query string true "请求方法"
+// @Param path query string true "请求路径"
+// @Success 200 {object} response.Response{data=dto.PermissionExplainResp} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/permissions/explain [get]
+func (c *PermissionController) Explain(ctx *gin.Context) {
+	var req dto.PermissionExplainReq
+	if err := validator.BindAndValidateQueryI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	// 调用服务
+	resp, err := service.GetPermissionService().Explain(&req)
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
 	"github.com/cuiyuanxin/kunpeng/internal/service"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
 	"github.com/cuiyuanxin/kunpeng/pkg/response"
 	"github.com/cuiyuanxin/kunpeng/pkg/validator"
 	"github.com/gin-gonic/gin"
@@ -46,6 +47,28 @@ func (c *RoleController) GetRoleList(ctx *gin.Context) {
 	response.OkWithData(ctx, resp)
 }
 
+// GetRoleTree 获取角色树
+// @Summary 获取角色树
+// @Description 获取角色继承关系树
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=[]dto.RoleTreeResp} "成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/roles/tree [get]
+func (c *RoleController) GetRoleTree(ctx *gin.Context) {
+	// 调用服务
+	resp, err := service.GetRoleService().GetRoleTree()
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
+
 // GetAllRoles 获取所有角色
 // @Summary 获取所有角色
 // @Description 获取所有角色
@@ -148,7 +171,7 @@ func (c *RoleController) UpdateRole(ctx *gin.Context) {
 	}
 
 	// 调用服务
-	err := service.GetRoleService().UpdateRole(&req)
+	err := service.GetRoleService().UpdateRole(jwt.GetUserID(ctx), &req)
 	if err != nil {
 		response.FailWithError(ctx, err)
 		return
@@ -237,7 +260,7 @@ func (c *RoleController) ChangeRoleStatus(ctx *gin.Context) {
 	}
 
 	// 调用服务
-	err := service.GetRoleService().ChangeRoleStatus(&req)
+	err := service.GetRoleService().ChangeRoleStatus(jwt.GetUserID(ctx), &req)
 	if err != nil {
 		response.FailWithError(ctx, err)
 		return
@@ -355,7 +378,88 @@ func (c *RoleController) UpdateRoleAPIs(ctx *gin.Context) {
 	}
 
 	// 调用服务
-	err := service.GetRoleService().UpdateRoleAPIs(&req)
+	err := service.GetRoleService().UpdateRoleAPIs(jwt.GetUserID(ctx), &req)
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.Ok(ctx)
+}
+
+// RebuildPolicies 重建权限策略
+// @Summary 重建权限策略
+// @Description 根据角色API关联重建所有Casbin权限策略
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response "成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/roles/policies/rebuild [post]
+func (c *RoleController) RebuildPolicies(ctx *gin.Context) {
+	// 调用服务
+	err := service.GetRoleService().RebuildPolicies()
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.Ok(ctx)
+}
+
+// GetRoleDataScope 获取角色数据权限
+// @Summary 获取角色数据权限
+// @Description 获取角色数据权限范围及自定义数据权限的部门
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "角色ID"
+// @Success 200 {object} response.Response{data=dto.RoleDataScopeResp} "成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/roles/{id}/data-scope [get]
+func (c *RoleController) GetRoleDataScope(ctx *gin.Context) {
+	var req dto.IDReq
+	if err := validator.BindAndValidateUri(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	// 调用服务层获取角色数据权限
+	resp, err := service.GetRoleService().GetRoleDataScope(req.ID)
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
+
+// UpdateRoleDataScope 更新角色数据权限
+// @Summary 更新角色数据权限
+// @Description 更新角色数据权限范围，1:全部 2:自定义部门 3:本部门 4:本部门及以下 5:仅本人
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param data body dto.RoleDataScopeReq true "更新角色数据权限请求"
+// @Success 200 {object} response.Response "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/roles/data-scope [put]
+func (c *RoleController) UpdateRoleDataScope(ctx *gin.Context) {
+	var req dto.RoleDataScopeReq
+	if err := validator.BindAndValidateJSON(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	// 调用服务
+	err := service.GetRoleService().UpdateRoleDataScope(&req)
 	if err != nil {
 		response.FailWithError(ctx, err)
 		return
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/service"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/cuiyuanxin/kunpeng/pkg/response"
+	"github.com/cuiyuanxin/kunpeng/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// SessionController 登录会话控制器
+type SessionController struct{}
+
+// GetSessions 获取当前用户的登录会话
+// @Summary 获取当前用户的登录会话
+// @Description 获取当前用户未撤销且未过期的登录会话，当前请求所属的会话标记为current
+// @Tags 登录会话
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=[]dto.UserSessionResp} "成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/user/sessions [get]
+func (c *SessionController) GetSessions(ctx *gin.Context) {
+	sessions, err := service.GetSessionService().ListUserSessions(jwt.GetUserID(ctx), jwt.GetSessionID(ctx))
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, sessions)
+}
+
+// RevokeSession 注销当前用户的登录会话
+// @Summary 注销当前用户的登录会话
+// @Description 注销当前用户的指定登录会话，该会话签发的token立即失效，可用于下线其他设备
+// @Tags 登录会话
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "会话ID"
+// @Success 200 {object} response.Response "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 404 {object} response.Response "会话不存在"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/user/sessions/{id} [delete]
+func (c *SessionController) RevokeSession(ctx *gin.Context) {
+	var req dto.IDReq
+	if err := validator.BindAndValidateUriI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	if err := service.GetSessionService().RevokeUserSession(jwt.GetUserID(ctx), req.ID, "用户注销登录会话"); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.Ok(ctx)
+}
+
+// GetUserSessions 获取指定用户的登录会话
+// @Summary 获取指定用户的登录会话
+// @Description 管理员查看指定用户未撤销且未过期的登录会话
+// @Tags 登录会话
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=[]dto.UserSessionResp} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "禁止访问"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/users/{id}/sessions [get]
+func (c *SessionController) GetUserSessions(ctx *gin.Context) {
+	var req dto.IDReq
+	if err := validator.BindAndValidateUriI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	// 内置用户仅允许本人或超级管理员操作，且用户需在数据权限范围内
+	if err := service.GetUserService().CheckUserManageable(jwt.GetUserID(ctx), req.ID); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	sessions, err := service.GetSessionService().ListUserSessions(req.ID, jwt.GetSessionID(ctx))
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, sessions)
+}
+
+// ForceLogout 强制用户下线
+// @Summary 强制用户下线
+// @Description 管理员撤销指定用户的全部登录会话，该用户已签发的token立即失效，需重新登录
+// @Tags 登录会话
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "禁止访问"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/users/{id}/sessions [delete]
+func (c *SessionController) ForceLogout(ctx *gin.Context) {
+	var req dto.IDReq
+	if err := validator.BindAndValidateUriI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	// 内置用户仅允许本人或超级管理员操作，且用户需在数据权限范围内
+	if err := service.GetUserService().CheckUserManageable(jwt.GetUserID(ctx), req.ID); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	if err := service.GetSessionService().RevokeUserSessions(req.ID, "管理员强制下线"); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.Ok(ctx)
+}
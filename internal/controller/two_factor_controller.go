@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/service"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/cuiyuanxin/kunpeng/pkg/response"
+	"github.com/cuiyuanxin/kunpeng/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorController 双因素认证控制器
+type TwoFactorController struct{}
+
+// GetStatus 获取当前用户的双因素认证状态
+// @Summary 获取双因素认证状态
+// @Description 获取当前用户是否已启用双因素认证、所属角色是否要求启用及剩余恢复码数量
+// @Tags 双因素认证
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=dto.TwoFactorStatusResp} "成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/user/2fa [get]
+func (c *TwoFactorController) GetStatus(ctx *gin.Context) {
+	resp, err := service.GetTwoFactorService().GetStatus(jwt.GetUserID(ctx))
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
+
+// Setup 获取TOTP密钥
+// @Summary 获取TOTP密钥
+// @Description 生成新的TOTP密钥及otpauth URI，使用验证器应用绑定后调用启用接口确认
+// @Tags 双因素认证
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=dto.TwoFactorSetupResp} "成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/user/2fa/setup [post]
+func (c *TwoFactorController) Setup(ctx *gin.Context) {
+	resp, err := service.GetTwoFactorService().Setup(jwt.GetUserID(ctx))
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
+
+// Enable 启用双因素认证
+// @Summary 启用双因素认证
+// @Description 校验验证器动态码后启用双因素认证，返回的恢复码仅显示一次
+// @Tags 双因素认证
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param data body dto.TwoFactorCodeReq true "动态码请求"
+// @Success 200 {object} response.Response{data=dto.TwoFactorRecoveryCodesResp} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/user/2fa/enable [post]
+func (c *TwoFactorController) Enable(ctx *gin.Context) {
+	var req dto.TwoFactorCodeReq
+	if err := validator.BindAndValidateJSONI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	resp, err := service.GetTwoFactorService().Enable(jwt.GetUserID(ctx), req.Code)
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
+
+// Disable 关闭双因素认证
+// @Summary 关闭双因素认证
+// @Description 校验动态码或恢复码后关闭双因素认证，所属角色要求双因素认证时不能关闭
+// @Tags 双因素认证
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param data body dto.TwoFactorCodeReq true "动态码请求"
+// @Success 200 {object} response.Response "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/user/2fa/disable [post]
+func (c *TwoFactorController) Disable(ctx *gin.Context) {
+	var req dto.TwoFactorCodeReq
+	if err := validator.BindAndValidateJSONI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	if err := service.GetTwoFactorService().Disable(jwt.GetUserID(ctx), req.Code); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.Ok(ctx)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// @Summary 重新生成恢复码
+// @Description 校验验证器动态码后重新生成恢复码，原有恢复码全部失效
+// @Tags 双因素认证
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param data body dto.TwoFactorCodeReq true "动态码请求"
+// @Success 200 {object} response.Response{data=dto.TwoFactorRecoveryCodesResp} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/user/2fa/recovery-codes [post]
+func (c *TwoFactorController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	var req dto.TwoFactorCodeReq
+	if err := validator.BindAndValidateJSONI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	resp, err := service.GetTwoFactorService().RegenerateRecoveryCodes(jwt.GetUserID(ctx), req.Code)
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.OkWithData(ctx, resp)
+}
+
+// ResetUserTwoFactor 重置用户的双因素认证
+// @Summary 重置用户的双因素认证
+// @Description 管理员重置用户的双因素认证，用于用户丢失验证器且没有可用恢复码的情况，用户需重新绑定
+// @Tags 双因素认证
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "禁止访问"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/users/{id}/2fa/reset [put]
+func (c *TwoFactorController) ResetUserTwoFactor(ctx *gin.Context) {
+	var req dto.IDReq
+	if err := validator.BindAndValidateUriI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	// 内置用户仅允许本人或超级管理员操作，且用户需在数据权限范围内
+	if err := service.GetUserService().CheckUserManageable(jwt.GetUserID(ctx), req.ID); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	if err := service.GetTwoFactorService().Reset(req.ID); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	response.Ok(ctx)
+}
@@ -45,8 +45,8 @@ func (c *UserController) Login(ctx *gin.Context) {
 	}
 
 	// 调用服务
-	realIP := utils.GetRealIP(ctx.Request)
-	resp, err := service.GetUserService().Login(&req, realIP)
+	realIP := ctx.ClientIP()
+	resp, err := service.GetUserService().Login(&req, realIP, ctx.Request.UserAgent())
 	if err != nil {
 		response.FailWithError(ctx, err)
 		// 记录登录失败日志
@@ -54,9 +54,135 @@ func (c *UserController) Login(ctx *gin.Context) {
 		return
 	}
 
-	// 从access token中解析用户ID记录登录成功日志
-	claims, _ := jwt.ParseToken(resp.AccessToken)
-	go c.recordLoginLog(claims.UserID, req.Account, ctx, 1, "登录成功")
+	// 需要双因素认证时，完成第二步认证后再记录登录成功日志
+	if resp.TwoFactorRequired {
+		response.OkWithData(ctx, resp)
+		return
+	}
+
+	// 从access token或修改密码凭证中解析用户ID记录登录成功日志
+	token := resp.AccessToken
+	if resp.PasswordChangeRequired {
+		token = resp.PasswordChangeToken
+	}
+	var userID uint
+	if claims, err := jwt.ParseToken(token); err == nil {
+		userID = claims.UserID
+	}
+	go c.recordLoginLog(userID, req.Account, ctx, 1, "登录成功")
+
+	response.OkWithData(ctx, resp)
+}
+
+// LoginTwoFactor 双因素认证登录
+// @Summary 双因素认证登录
+// @Description 登录返回 two_factor_required 时，使用 two_factor_token 和验证器动态码（或恢复码）完成登录。需要先绑定验证器时，校验动态码的同时完成绑定并返回恢复码
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param data body dto.TwoFactorLoginReq true "双因素认证登录请求"
+// @Success 200 {object} response.Response{data=dto.UserLoginResp} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/login/2fa [post]
+func (c *UserController) LoginTwoFactor(ctx *gin.Context) {
+	var req dto.TwoFactorLoginReq
+	if err := validator.BindAndValidateJSONI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	// 从登录第二步凭证中解析用户信息用于记录登录日志
+	var userID uint
+	var username string
+	if claims, err := jwt.ParseToken(req.TwoFactorToken); err == nil {
+		userID, username = claims.UserID, claims.Username
+	}
+
+	// 调用服务
+	resp, err := service.GetUserService().LoginTwoFactor(&req, ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		response.FailWithError(ctx, err)
+		// 记录登录失败日志
+		go c.recordLoginLog(userID, username, ctx, 0, err.Error())
+		return
+	}
+
+	go c.recordLoginLog(userID, username, ctx, 1, "登录成功")
+
+	response.OkWithData(ctx, resp)
+}
+
+// LoginOIDC 单点登录
+// @Summary 单点登录
+// @Description 身份提供方回调后提交授权码和state完成登录，返回结果与账号登录相同。身份未关联账号时按配置关联已有用户或自动创建用户
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param data body dto.OIDCCallbackReq true "单点登录回调请求"
+// @Success 200 {object} response.Response{data=dto.UserLoginResp} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/login/oidc [post]
+func (c *UserController) LoginOIDC(ctx *gin.Context) {
+	var req dto.OIDCCallbackReq
+	if err := validator.BindAndValidateJSONI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	resp, err := service.GetUserService().LoginOIDC(&req, ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		response.FailWithError(ctx, err)
+		// 记录登录失败日志
+		go c.recordLoginLog(0, "", ctx, 0, err.Error())
+		return
+	}
+
+	// 需要双因素认证时，完成第二步认证后再记录登录成功日志
+	if resp.TwoFactorRequired {
+		response.OkWithData(ctx, resp)
+		return
+	}
+
+	// 从access token或修改密码凭证中解析用户信息记录登录成功日志
+	token := resp.AccessToken
+	if resp.PasswordChangeRequired {
+		token = resp.PasswordChangeToken
+	}
+	var userID uint
+	var username string
+	if claims, err := jwt.ParseToken(token); err == nil {
+		userID, username = claims.UserID, claims.Username
+	}
+	go c.recordLoginLog(userID, username, ctx, 1, "单点登录成功")
+
+	response.OkWithData(ctx, resp)
+}
+
+// SetupLoginTwoFactor 登录时绑定验证器
+// @Summary 登录时绑定验证器
+// @Description 登录返回 two_factor_setup 时，使用 two_factor_token 获取TOTP密钥，绑定验证器后调用双因素认证登录接口完成登录
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param data body dto.TwoFactorTokenReq true "双因素认证凭证请求"
+// @Success 200 {object} response.Response{data=dto.TwoFactorSetupResp} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "内部服务器错误"
+// @Router /api/v1/login/2fa/setup [post]
+func (c *UserController) SetupLoginTwoFactor(ctx *gin.Context) {
+	var req dto.TwoFactorTokenReq
+	if err := validator.BindAndValidateJSONI18n(ctx, &req); err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+
+	resp, err := service.GetUserService().SetupLoginTwoFactor(&req)
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
 
 	response.OkWithData(ctx, resp)
 }
@@ -81,9 +207,15 @@ func (c *UserController) RefreshToken(ctx *gin.Context) {
 	}
 
 	// 调用服务刷新token
-	resp, err := service.GetUserService().RefreshToken(&req)
+	resp, err := service.GetUserService().RefreshToken(&req, ctx.ClientIP())
 	if err != nil {
 		response.FailWithError(ctx, err)
+		// 已轮换的refresh token被再次使用，记录安全事件
+		if kperrors.IsCode(err, kperrors.ErrAuthTokenReuse) {
+			if claims, parseErr := jwt.ParseToken(req.RefreshToken); parseErr == nil {
+				go c.recordLoginLog(claims.UserID, claims.Username, ctx, 0, "安全事件：检测到refresh token重复使用，已撤销该登录的全部token")
+			}
+		}
 		return
 	}
 
@@ -106,8 +238,18 @@ func (c *UserController) Logout(ctx *gin.Context) {
 	authorization := ctx.GetHeader("Authorization")
 	token := jwt.ExtractTokenFromHeader(authorization)
 	if token != "" {
+		// 退出登录接口无需认证，从token中解析用户信息及登录会话
+		claims, parseErr := jwt.ParseToken(token)
+		if parseErr == nil {
+			userID, username = claims.UserID, claims.Username
+		}
+
 		tokenBlacklistService := service.GetTokenBlacklistService()
 		err := tokenBlacklistService.BlacklistToken(token, userID, username, "用户主动退出登录")
+		if err == nil && parseErr == nil {
+			// 撤销当前登录会话，使同一次登录签发的refresh token一并失效
+			err = service.GetSessionService().RevokeSession(claims.FamilyID, userID, username, "用户主动退出登录")
+		}
 		if err != nil {
 			// 记录错误但不影响退出登录流程
 			go c.recordLoginLog(userID, username, ctx, 0, "退出登录成功，但token黑名单添加失败")
@@ -148,29 +290,17 @@ func (c *UserController) getUserInfo(ctx *gin.Context) (uint, string) {
 
 // recordLoginLog 记录登录日志
 func (c *UserController) recordLoginLog(userID uint, username string, ctx *gin.Context, status int8, message string) {
-	userAgent := ctx.Request.UserAgent()
-
-	// 简单解析User-Agent（实际项目中可以使用专门的库如ua-parser）
-	browser := "Unknown"
-	os := "Unknown"
-	device := "Unknown"
-
-	// 简单的User-Agent解析逻辑
-	if userAgent != "" {
-		// 这里可以使用更复杂的解析逻辑或第三方库
-		browser = "Browser" // 简化处理
-		os = "OS"           // 简化处理
-		device = "Device"   // 简化处理
-	}
+	// 简单解析User-Agent
+	ua := utils.ParseUserAgent(ctx.Request.UserAgent())
 
 	loginLogService := service.GetLoginLogService()
 	loginLogService.RecordLoginLog(
 		userID,
 		username,
 		ctx.ClientIP(),
-		browser,
-		os,
-		device,
+		ua.Browser,
+		ua.OS,
+		ua.Device,
 		"", // location 可以根据IP获取地理位置
 		status,
 		message,
@@ -229,6 +359,14 @@ func (c *UserController) GetUserList(ctx *gin.Context) {
 		return
 	}
 
+	// 按当前用户的数据权限过滤
+	dataScope, err := getDataScope(ctx)
+	if err != nil {
+		response.FailWithError(ctx, err)
+		return
+	}
+	req.DataScope = dataScope
+
 	// 调用服务
 	resp, err := service.GetUserService().GetUserList(&req)
 	if err != nil {
@@ -259,7 +397,7 @@ func (c *UserController) GetUserByID(ctx *gin.Context) {
 	}
 
 	// 调用服务
-	resp, err := service.GetUserService().GetUserByID(req.ID)
+	resp, err := service.GetUserService().GetUserByID(jwt.GetUserID(ctx), req.ID)
 	if err != nil {
 		response.FailWithError(ctx, err)
 		return
@@ -289,7 +427,7 @@ func (c *UserController) CreateUser(ctx *gin.Context) {
 	}
 
 	// 调用服务
-	id, err := service.GetUserService().CreateUser(&req)
+	id, err := service.GetUserService().CreateUser(jwt.GetUserID(ctx), &req)
 	if err != nil {
 		response.FailWithError(ctx, err)
 		return
@@ -319,7 +457,7 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 	}
 
 	// 调用服务
-	err := service.GetUserService().UpdateUser(&req)
+	err := service.GetUserService().UpdateUser(jwt.GetUserID(ctx), &req)
 	if err != nil {
 		response.FailWithError(ctx, err)
 		return
@@ -348,7 +486,7 @@ func (c *UserController) DeleteUser(ctx *gin.Context) {
 	}
 
 	// 调用服务
-	err := service.GetUserService().DeleteUser(req.ID)
+	err := service.GetUserService().DeleteUser(jwt.GetUserID(ctx), req.ID)
 	if err != nil {
 		response.FailWithError(ctx, err)
 		return
@@ -378,7 +516,7 @@ func (c *UserController) BatchDeleteUser(ctx *gin.Context) {
 	}
 
 	// 调用服务
-	err := service.GetUserService().BatchDeleteUser(req.IDs)
+	err := service.GetUserService().BatchDeleteUser(jwt.GetUserID(ctx), req.IDs)
 	if err != nil {
 		response.FailWithError(ctx, err)
 		return
@@ -408,7 +546,7 @@ func (c *UserController) ChangeUserStatus(ctx *gin.Context) {
 	}
 
 	// 调用服务
-	err := service.GetUserService().ChangeUserStatus(&req)
+	err := service.GetUserService().ChangeUserStatus(jwt.GetUserID(ctx), &req)
 	if err != nil {
 		response.FailWithError(ctx, err)
 		return
@@ -419,13 +557,13 @@ func (c *UserController) ChangeUserStatus(ctx *gin.Context) {
 
 // ResetUserPassword 重置用户密码
 // @Summary 重置用户密码
-// @Description 重置用户密码
+// @Description 重置为随机生成的一次性密码，用户使用该密码登录后必须修改密码
 // @Tags 用户管理
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path int true "用户ID"
-// @Success 200 {object} response.Response "成功"
+// @Success 200 {object} response.Response{data=dto.UserResetPasswordResp} "成功"
 // @Failure 401 {object} response.Response "未授权"
 // @Failure 500 {object} response.Response "内部服务器错误"
 // @Router /api/v1/users/{id}/password/reset [put]
@@ -437,18 +575,18 @@ func (c *UserController) ResetUserPassword(ctx *gin.Context) {
 	}
 
 	// 调用服务
-	err := service.GetUserService().ResetUserPassword(req.ID)
+	otp, err := service.GetUserService().ResetUserPassword(jwt.GetUserID(ctx), req.ID)
 	if err != nil {
 		response.FailWithError(ctx, err)
 		return
 	}
 
-	response.Ok(ctx)
+	response.OkWithData(ctx, dto.UserResetPasswordResp{Password: otp})
 }
 
 // ChangePassword 修改密码
 // @Summary 修改密码
-// @Description 修改密码
+// @Description 修改密码，新密码需符合密码策略。必须修改密码的用户使用登录返回的修改密码凭证调用，修改后需重新登录
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"time"
+)
+
+// APINonceRepository 签名请求随机串仓储接口
+type APINonceRepository interface {
+	// 记录AppKey使用的随机串，随机串已被使用过时返回false
+	Use(appKey, nonce string, expiresAt time.Time) (bool, error)
+
+	// 清理过期的随机串记录
+	CleanExpired() error
+}
@@ -30,4 +30,7 @@ type DeptRepository interface {
 
 	// 检查部门是否有用户
 	HasUsers(id uint) (bool, error)
+
+	// 获取部门及其所有下级部门ID
+	FindChildIDs(id uint) ([]uint, error)
 }
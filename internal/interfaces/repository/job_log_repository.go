@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+)
+
+// JobLogRepository 定时任务执行记录仓储接口
+type JobLogRepository interface {
+	// 创建执行记录
+	Create(log *model.JobLog) error
+
+	// 更新执行结果
+	Update(log *model.JobLog) error
+
+	// 查询执行记录列表
+	FindList(req *dto.JobLogListReq) ([]*model.JobLog, int64, error)
+
+	// 获取任务最近一次执行记录
+	FindLatest(jobName string) (*model.JobLog, error)
+
+	// 清理旧执行记录（保留指定天数）
+	CleanOldLogs(days int) error
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+// JobRepository 定时任务仓储接口
+type JobRepository interface {
+	// 获取全部任务的状态
+	FindAll() ([]*model.Job, error)
+
+	// 根据名称获取任务
+	FindByName(name string) (*model.Job, error)
+
+	// 保存任务状态，任务不存在时创建
+	SaveStatus(name string, status int8) error
+}
@@ -1,22 +1,28 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
 )
 
 // LoginAttemptRepository 登录尝试记录仓储接口
 type LoginAttemptRepository interface {
-	// 根据账号和IP获取登录尝试记录
-	FindByAccountAndIP(account, ip string) (*model.LoginAttempt, error)
+	// 根据计数范围和账号或IP获取登录尝试记录
+	FindBySubject(scope, subject string) (*model.LoginAttempt, error)
+
+	// 根据ID获取登录尝试记录
+	FindByID(id uint) (*model.LoginAttempt, error)
 
-	// 根据账号获取登录尝试记录
-	FindByAccount(account string) (*model.LoginAttempt, error)
+	// 查询锁定中的登录尝试记录
+	FindLockedList(req *dto.LoginLockListReq) ([]*model.LoginAttempt, int64, error)
 
-	// 创建登录尝试记录
-	Create(attempt *model.LoginAttempt) error
+	// 累计一次登录失败，记录不存在时创建，返回累计后的记录
+	IncrFailure(scope, subject, account, ip string, resetBefore time.Time) (*model.LoginAttempt, error)
 
-	// 更新登录尝试记录
-	Update(attempt *model.LoginAttempt) error
+	// 锁定登录尝试记录，记录已处于锁定中时不重复锁定
+	Block(id uint, duration time.Duration) error
 
 	// 删除登录尝试记录
 	Delete(id uint) error
@@ -19,6 +19,9 @@ type MenuRepository interface {
 	// 获取用户菜单树
 	FindUserMenuTree(userID uint) ([]*dto.MenuTreeResp, error)
 
+	// 获取用户的权限标识
+	FindUserPermissions(userID uint) ([]string, error)
+
 	// 创建菜单
 	Create(menu *model.Menu) error
 
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+// OIDCStateRepository 单点登录授权请求记录仓储接口
+type OIDCStateRepository interface {
+	// 创建授权请求记录
+	Create(state *model.OIDCState) error
+
+	// 根据state获取授权请求记录
+	FindByState(state string) (*model.OIDCState, error)
+
+	// 将授权请求记录标记为已使用，已使用过时返回false
+	MarkUsed(id uint) (bool, error)
+
+	// 清理在指定时间之前过期的授权请求记录
+	CleanExpired(before time.Time) error
+}
+
+// UserIdentityRepository 用户外部身份仓储接口
+type UserIdentityRepository interface {
+	// 根据身份提供方及用户唯一标识获取外部身份
+	FindBySubject(issuer, subject string) (*model.UserIdentity, error)
+
+	// 获取用户在身份提供方关联的外部身份
+	FindByUserID(userID uint, issuer string) (*model.UserIdentity, error)
+
+	// 创建外部身份
+	Create(identity *model.UserIdentity) error
+
+	// 删除用户在身份提供方关联的外部身份
+	Delete(userID uint, issuer string) error
+
+	// 更新最近登录时间及邮箱
+	UpdateLogin(id uint, email string) error
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+// PasswordHistoryRepository 密码历史仓储接口
+type PasswordHistoryRepository interface {
+	// 创建密码历史记录
+	Create(history *model.PasswordHistory) error
+
+	// 获取用户最近的密码历史记录，按设置时间倒序
+	FindRecent(userID uint, limit int) ([]*model.PasswordHistory, error)
+
+	// 删除用户最近keep条之外的密码历史记录
+	DeleteExceptRecent(userID uint, keep int) error
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+// PasswordResetRepository 找回密码请求记录仓储接口
+type PasswordResetRepository interface {
+	// 创建找回密码请求记录
+	Create(reset *model.PasswordReset) error
+
+	// 获取账号最近一次找回密码请求记录
+	FindLatest(account string) (*model.PasswordReset, error)
+
+	// 根据重置凭证的jti获取找回密码请求记录
+	FindByJTI(jti string) (*model.PasswordReset, error)
+
+	// 获取用户最近一次已发送邮件的找回密码请求记录
+	FindLatestByUserID(userID uint) (*model.PasswordReset, error)
+
+	// 统计用户自指定时间以来已发送邮件的请求次数
+	CountByUserID(userID uint, since time.Time) (int64, error)
+
+	// 统计IP自指定时间以来的请求次数
+	CountByIP(ip string, since time.Time) (int64, error)
+
+	// 将请求记录标记为已使用，已被使用时返回false
+	MarkUsed(id uint) (bool, error)
+
+	// 清理在指定时间之前过期的重置密码记录
+	CleanExpired(before time.Time) error
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+// RefreshTokenRepository refresh token签发记录仓储接口
+type RefreshTokenRepository interface {
+	// 创建refresh token签发记录
+	Create(token *model.RefreshToken) error
+
+	// 根据jti获取refresh token签发记录
+	FindByJTI(jti string) (*model.RefreshToken, error)
+
+	// 将refresh token标记为已轮换，已轮换或已撤销时返回false
+	MarkUsed(jti string) (bool, error)
+
+	// 撤销token族中的全部refresh token
+	RevokeFamily(familyID string) error
+
+	// 清理在指定时间之前过期的refresh token签发记录
+	CleanExpired(before time.Time) error
+}
@@ -42,4 +42,28 @@ type RoleRepository interface {
 
 	// 更新角色API
 	UpdateRoleAPIs(roleID uint, apiIDs []uint) error
+
+	// 根据角色API关联和用户角色关联重建所有权限策略
+	RebuildPolicies() error
+
+	// 根据ID列表获取角色
+	FindByIDs(ids []uint) ([]*model.Role, error)
+
+	// 获取用户的所有角色
+	FindByUserID(userID uint) ([]*model.Role, error)
+
+	// 获取角色树
+	FindTree() ([]*dto.RoleTreeResp, error)
+
+	// 检查角色是否有子角色
+	HasChildren(id uint) (bool, error)
+
+	// 获取用户的有效角色（包括继承的上级角色）
+	FindEffectiveByUserID(userID uint) ([]*model.Role, error)
+
+	// 获取角色自定义数据权限的部门
+	GetRoleDepts(roleID uint) ([]uint, error)
+
+	// 更新角色数据权限
+	UpdateDataScope(roleID uint, dataScope int8, deptIDs []uint) error
 }
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+// SMSCodeRepository 短信验证码仓储接口
+type SMSCodeRepository interface {
+	// 创建短信验证码记录
+	Create(code *model.SMSCode) error
+
+	// 获取手机号在指定场景下最近一次发送的验证码
+	FindLatest(mobile, scene string) (*model.SMSCode, error)
+
+	// 统计手机号自指定时间以来的发送次数
+	CountByMobile(mobile string, since time.Time) (int64, error)
+
+	// 统计IP自指定时间以来的发送次数
+	CountByIP(ip string, since time.Time) (int64, error)
+
+	// 占用一次校验次数，次数已达到上限时返回false
+	UseAttempt(id uint, maxAttempts int) (bool, error)
+
+	// 将验证码标记为已使用，验证码已被使用时返回false
+	MarkUsed(id uint) (bool, error)
+
+	// 清理在指定时间之前过期的短信验证码记录
+	CleanExpired(before time.Time) error
+}
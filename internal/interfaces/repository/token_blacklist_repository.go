@@ -1,19 +1,24 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/cuiyuanxin/kunpeng/internal/model"
 )
 
 // TokenBlacklistRepository token黑名单仓储接口
 type TokenBlacklistRepository interface {
-	// 添加token到黑名单
+	// 添加token到黑名单，撤销key已存在时更新原记录
 	Create(blacklist *model.TokenBlacklist) error
 
-	// 检查token是否在黑名单中
-	IsTokenBlacklisted(token string) (bool, error)
+	// 检查撤销key是否存在未过期的黑名单记录
+	ExistsKey(key string) (bool, error)
+
+	// 查询since之后新增或更新且未过期的撤销key，since为零值时查询全部
+	FindActiveKeys(since time.Time) ([]string, error)
 
-	// 根据token查找黑名单记录
-	FindByToken(token string) (*model.TokenBlacklist, error)
+	// 根据ID查找黑名单记录
+	FindByID(id uint) (*model.TokenBlacklist, error)
 
 	// 清理过期的黑名单记录
 	CleanExpired() error
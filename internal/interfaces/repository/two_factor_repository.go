@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+// TwoFactorRepository 双因素认证仓储接口
+type TwoFactorRepository interface {
+	// 根据用户ID获取双因素认证信息
+	FindByUserID(userID uint) (*model.UserTwoFactor, error)
+
+	// 保存双因素认证信息
+	Save(twoFactor *model.UserTwoFactor) error
+
+	// 记录校验通过的时间步，时间步不大于已记录的值时返回false
+	ConsumeStep(userID uint, step int64) (bool, error)
+
+	// 删除用户的双因素认证信息及恢复码
+	DeleteByUserID(userID uint) error
+
+	// 替换用户的恢复码
+	ReplaceRecoveryCodes(userID uint, codeHashes []string) error
+
+	// 使用恢复码，恢复码不存在或已使用时返回false
+	UseRecoveryCode(userID uint, codeHash string) (bool, error)
+
+	// 统计用户未使用的恢复码数量
+	CountRecoveryCodes(userID uint) (int64, error)
+
+	// 检查用户是否拥有要求双因素认证的启用状态的角色
+	IsRequiredByRole(userID uint) (bool, error)
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/cuiyuanxin/kunpeng/internal/model"
 	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
 )
@@ -16,14 +18,29 @@ type UserRepository interface {
 	// 根据手机号获取用户
 	FindByMobile(mobile string) (*model.User, error)
 
+	// 根据邮箱获取用户
+	FindByEmail(email string) (*model.User, error)
+
+	// 根据AppKey获取用户
+	FindByAppKey(appKey string) (*model.User, error)
+
 	// 获取用户列表
 	FindList(req *dto.UserPageReq) ([]*model.User, int64, error)
 
 	// 创建用户
 	Create(user *model.User) error
 
-	// 更新用户
-	Update(user *model.User) error
+	// 更新用户的指定字段
+	Update(user *model.User, columns ...string) error
+
+	// 更新用户的最近登录时间及IP
+	UpdateLoginInfo(id uint, loginIP string, loginTime time.Time) error
+
+	// 创建用户并关联角色
+	CreateWithRoles(user *model.User, roleIDs []uint) error
+
+	// 更新用户的指定字段并重新关联角色
+	UpdateWithRoles(user *model.User, roleIDs []uint, columns ...string) error
 
 	// 删除用户
 	Delete(id uint) error
@@ -34,12 +51,27 @@ type UserRepository interface {
 	// 更新用户状态
 	UpdateStatus(id uint, status int) error
 
-	// 重置用户密码
+	// 重置用户密码，用户下次登录时必须修改密码
 	ResetPassword(id uint, password string) error
 
-	// 更新用户密码
+	// 更新用户密码，同时清除必须修改密码标记
 	UpdatePassword(id uint, password string) error
 
+	// 更新用户的AppSecret，原AppSecret在prevUntil之前仍然有效，prevUntil为nil时立即失效
+	UpdateAppSecret(id uint, appSecret, prevAppSecret string, prevUntil *time.Time) error
+
+	// 重置用户的AppSecret，原AppSecret立即失效，用户需重新轮换后才能使用签名认证
+	ResetAppSecret(id uint, appSecret string) error
+
 	// 根据角色ID查找用户
 	FindByRoleID(roleID uint) error
+
+	// 获取用户的角色ID列表
+	FindRoleIDs(userID uint) ([]uint, error)
+
+	// 获取用户的token版本号
+	FindTokenVersion(id uint) (uint, error)
+
+	// 递增用户的token版本号，返回递增后的版本号
+	IncrTokenVersion(id uint) (uint, error)
 }
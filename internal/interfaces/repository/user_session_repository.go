@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+// UserSessionRepository 用户登录会话仓储接口
+type UserSessionRepository interface {
+	// 创建会话
+	Create(session *model.UserSession) error
+
+	// 根据ID获取会话
+	FindByID(id uint) (*model.UserSession, error)
+
+	// 根据会话ID获取会话
+	FindBySessionID(sessionID string) (*model.UserSession, error)
+
+	// 获取用户未撤销且未过期的会话，按最近活跃时间倒序
+	FindActiveByUserID(userID uint) ([]*model.UserSession, error)
+
+	// 刷新token后更新会话的jti、访问信息及过期时间
+	UpdateRefresh(sessionID string, jti string, ip string, expiresAt time.Time) error
+
+	// 更新会话的最近活跃时间及访问IP
+	Touch(sessionID string, ip string, lastSeenAt time.Time) error
+
+	// 撤销会话，已撤销时返回false
+	Revoke(sessionID string, reason string) (bool, error)
+
+	// 撤销用户的全部会话，返回撤销的会话数量
+	RevokeByUserID(userID uint, reason string) (int64, error)
+
+	// 清理在指定时间之前过期的登录会话
+	CleanExpired(before time.Time) error
+}
@@ -20,11 +20,17 @@ type APIService interface {
 	CreateAPI(req *dto.APICreateReq) (uint, error)
 
 	// UpdateAPI 更新API
-	UpdateAPI(req *dto.APIUpdateReq) error
+	UpdateAPI(operatorID uint, req *dto.APIUpdateReq) error
 
 	// DeleteAPI 删除API
-	DeleteAPI(id uint) error
+	DeleteAPI(operatorID uint, id uint) error
 
 	// BatchDeleteAPI 批量删除API
-	BatchDeleteAPI(ids []uint) error
+	BatchDeleteAPI(operatorID uint, ids []uint) error
+
+	// SetRoutes 设置已注册的路由
+	SetRoutes(routes []*dto.RouteInfo)
+
+	// SyncRoutes 将已注册的路由同步到API表，dryRun为true时仅返回差异
+	SyncRoutes(dryRun bool) (*dto.APISyncResp, error)
 }
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/pkg/signature"
+)
+
+// APISignatureService AppKey/AppSecret签名认证服务接口
+type APISignatureService interface {
+	// Verify 校验签名请求的时间戳、签名及随机串，返回AppKey所属的用户
+	Verify(req *signature.Request, sign string) (*model.User, error)
+
+	// RotateSecret 校验当前密码或双因素认证动态码后为用户生成新的AppSecret，AppKey保持不变
+	RotateSecret(userID uint, req *dto.AppSecretRotateReq) (*dto.AppSecretResp, error)
+
+	// ResetSecret 重置用户的AppSecret，原AppSecret立即失效，新的AppSecret不返回，用户需自行轮换
+	ResetSecret(userID uint) error
+
+	// CleanupExpiredNonces 清理过期的随机串记录
+	CleanupExpiredNonces() error
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+)
+
+// CaptchaService 图片验证码服务接口
+type CaptchaService interface {
+	// Generate 生成图片验证码
+	Generate() (*dto.CaptchaResp, error)
+
+	// Verify 校验图片验证码，验证码只能使用一次
+	Verify(captchaID, captcha string) (bool, error)
+
+	// IsRequired 检查账号登录是否需要图片验证码
+	IsRequired(account, ip string) (bool, error)
+}
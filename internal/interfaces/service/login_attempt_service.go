@@ -1,17 +1,17 @@
 package service
 
 import (
-	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
 )
 
 // LoginAttemptService 登录尝试服务接口
 type LoginAttemptService interface {
-	// CheckAndRecordAttempt 检查并记录登录尝试
-	// 返回是否被拉黑和错误信息
+	// CheckAndRecordAttempt 记录登录结果，失败时分别累计账号和IP的失败次数，达到阈值后锁定
+	// 返回本次失败是否导致锁定和错误信息
 	CheckAndRecordAttempt(account, ip string, success bool) (blocked bool, err error)
 
-	// IsBlocked 检查账号是否被拉黑
-	IsBlocked(account, ip string) (bool, error)
+	// CheckLocked 检查账号或IP是否处于锁定中，锁定时返回包含剩余锁定时间的错误
+	CheckLocked(account, ip string) error
 
 	// ResetAttempts 重置账号的登录尝试记录
 	ResetAttempts(account string) error
@@ -19,6 +19,12 @@ type LoginAttemptService interface {
 	// CleanupExpiredRecords 清理过期的登录尝试记录
 	CleanupExpiredRecords() error
 
-	// GetAttempt 获取登录尝试记录
-	GetAttempt(account, ip string) (*model.LoginAttempt, error)
+	// GetFailedAttempts 获取账号及IP的连续登录失败次数，取两者中较大的值
+	GetFailedAttempts(account, ip string) (int, error)
+
+	// GetLockedList 获取锁定中的账号及IP列表
+	GetLockedList(req *dto.LoginLockListReq) (*dto.PageResp, error)
+
+	// Unlock 解除锁定并清零失败次数
+	Unlock(id uint) error
 }
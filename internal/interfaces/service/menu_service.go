@@ -16,6 +16,12 @@ type MenuService interface {
 	// GetUserMenuTree 获取用户菜单树
 	GetUserMenuTree(userID uint) ([]*dto.MenuTreeResp, error)
 
+	// GetUserPermissions 获取用户的权限标识
+	GetUserPermissions(userID uint) ([]string, error)
+
+	// HasPermission 检查用户是否拥有指定权限标识
+	HasPermission(userID uint, permission string) (bool, error)
+
 	// GetMenuByID 根据ID获取菜单
 	GetMenuByID(id uint) (*model.Menu, error)
 
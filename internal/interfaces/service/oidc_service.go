@@ -0,0 +1,27 @@
+package service
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+)
+
+// OIDCService 单点登录服务接口
+type OIDCService interface {
+	// Authorize 发起授权请求，userID为0时用于登录，否则用于当前用户关联身份
+	Authorize(userID uint, clientIP string) (*dto.OIDCAuthorizeResp, error)
+
+	// Authenticate 校验登录回调并返回身份关联的用户，按配置关联已有用户或自动创建用户
+	Authenticate(req *dto.OIDCCallbackReq) (*model.User, error)
+
+	// Link 校验关联回调并将身份关联到当前用户
+	Link(userID uint, req *dto.OIDCCallbackReq) error
+
+	// Unlink 解除当前用户关联的身份
+	Unlink(userID uint) error
+
+	// GetIdentity 获取当前用户的身份关联状态
+	GetIdentity(userID uint) (*dto.OIDCIdentityResp, error)
+
+	// CleanupExpiredStates 清理过期的授权请求记录
+	CleanupExpiredStates() error
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+)
+
+// PasswordResetService 找回密码服务接口
+type PasswordResetService interface {
+	// Forgot 向账号绑定的邮箱发送重置密码邮件，lang为邮件使用的语言
+	Forgot(req *dto.PasswordForgotReq, clientIP string, lang string) (*dto.PasswordForgotResp, error)
+
+	// Reset 校验重置凭证并设置新密码，成功后撤销用户的全部登录会话
+	Reset(req *dto.PasswordResetReq) error
+
+	// CleanupExpiredTokens 清理过期的重置密码记录，统计发送次数所需的记录保留到统计时间窗口结束
+	CleanupExpiredTokens() error
+}
@@ -0,0 +1,11 @@
+package service
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+)
+
+// PermissionService 权限服务接口
+type PermissionService interface {
+	// Explain 说明用户或角色访问指定接口的权限判定过程
+	Explain(req *dto.PermissionExplainReq) (*dto.PermissionExplainResp, error)
+}
@@ -10,6 +10,9 @@ type RoleService interface {
 	// GetRoleList 获取角色列表
 	GetRoleList(req *dto.RolePageReq) (*dto.PageResp, error)
 
+	// GetRoleTree 获取角色树
+	GetRoleTree() ([]*dto.RoleTreeResp, error)
+
 	// GetAllRoles 获取所有角色
 	GetAllRoles() ([]*model.Role, error)
 
@@ -20,7 +23,7 @@ type RoleService interface {
 	CreateRole(req *dto.RoleCreateReq) (uint, error)
 
 	// UpdateRole 更新角色
-	UpdateRole(req *dto.RoleUpdateReq) error
+	UpdateRole(operatorID uint, req *dto.RoleUpdateReq) error
 
 	// DeleteRole 删除角色
 	DeleteRole(id uint) error
@@ -29,7 +32,7 @@ type RoleService interface {
 	BatchDeleteRole(ids []uint) error
 
 	// ChangeRoleStatus 修改角色状态
-	ChangeRoleStatus(req *dto.StatusReq) error
+	ChangeRoleStatus(operatorID uint, req *dto.StatusReq) error
 
 	// GetRoleMenus 获取角色菜单
 	GetRoleMenus(roleID uint) (*dto.MenuRoleResp, error)
@@ -41,5 +44,23 @@ type RoleService interface {
 	GetRoleAPIs(roleID uint) (*dto.APIRoleResp, error)
 
 	// UpdateRoleAPIs 更新角色API
-	UpdateRoleAPIs(req *dto.RoleAPIReq) error
+	UpdateRoleAPIs(operatorID uint, req *dto.RoleAPIReq) error
+
+	// RebuildPolicies 根据角色API关联和用户角色关联重建所有权限策略
+	RebuildPolicies() error
+
+	// GetUserRoles 获取用户的所有角色
+	GetUserRoles(userID uint) ([]*model.Role, error)
+
+	// GetEffectiveRoles 获取用户的有效角色，包括继承的上级角色
+	GetEffectiveRoles(userID uint) ([]*model.Role, error)
+
+	// GetRoleDataScope 获取角色数据权限
+	GetRoleDataScope(roleID uint) (*dto.RoleDataScopeResp, error)
+
+	// UpdateRoleDataScope 更新角色数据权限
+	UpdateRoleDataScope(req *dto.RoleDataScopeReq) error
+
+	// GetDataScope 合并用户有效角色的数据权限，计算用户可访问的数据范围
+	GetDataScope(userID uint) (*dto.DataScope, error)
 }
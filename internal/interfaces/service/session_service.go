@@ -0,0 +1,39 @@
+package service
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+)
+
+// SessionService 用户登录会话服务接口
+type SessionService interface {
+	// CreateSession 登录成功后创建会话，会话ID为token对所属的token族ID
+	CreateSession(userID uint, username string, tokenPair *jwt.TokenPair, clientIP string, userAgent string) error
+
+	// RefreshSession 刷新token后更新会话的jti、访问IP及过期时间
+	RefreshSession(tokenPair *jwt.TokenPair, clientIP string) error
+
+	// TouchSession 按间隔异步更新会话的最近活跃时间及访问IP，会话的撤销由token族黑名单及token版本号校验
+	TouchSession(sessionID string, clientIP string)
+
+	// ListUserSessions 获取用户的有效会话，currentSessionID对应的会话标记为当前会话
+	ListUserSessions(userID uint, currentSessionID string) ([]*dto.UserSessionResp, error)
+
+	// RevokeUserSession 撤销用户的指定会话，会话不属于该用户时返回不存在
+	RevokeUserSession(userID uint, id uint, reason string) error
+
+	// RevokeUserSessions 撤销用户的全部会话
+	RevokeUserSessions(userID uint, reason string) error
+
+	// RevokeSession 根据会话ID撤销会话，同时撤销该会话的refresh token及已签发的access token
+	RevokeSession(sessionID string, userID uint, username string, reason string) error
+
+	// CheckTokenVersion 校验token签发时的版本号是否与用户当前版本号一致，使用本地缓存避免每个请求查询数据库
+	CheckTokenVersion(userID uint, version uint) error
+
+	// RevokeUserTokens 递增用户的token版本号使此前签发的全部token失效，重置AppSecret，并撤销用户的全部会话
+	RevokeUserTokens(userID uint, reason string) error
+
+	// CleanupExpiredSessions 清理过期的登录会话及refresh token签发记录
+	CleanupExpiredSessions() error
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+)
+
+// SMSService 短信验证码服务接口
+type SMSService interface {
+	// SendCode 发送短信验证码，受重发间隔及手机号、IP发送次数限制
+	SendCode(req *dto.SMSCodeReq, scene, clientIP string) (*dto.SMSCodeResp, error)
+
+	// VerifyCode 校验短信验证码，校验通过后验证码失效
+	VerifyCode(mobile, scene, code string) error
+
+	// CleanupExpiredCodes 清理过期的短信验证码记录，统计发送次数所需的记录保留到统计时间窗口结束
+	CleanupExpiredCodes() error
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"time"
+
 	"github.com/cuiyuanxin/kunpeng/internal/model"
 )
 
@@ -12,6 +14,12 @@ type TokenBlacklistService interface {
 	// 检查token是否在黑名单中
 	IsTokenBlacklisted(token string) (bool, error)
 
+	// 撤销token族，该族中的全部token均失效
+	BlacklistFamily(familyID string, userID uint, username string, reason string, expiresAt time.Time) error
+
+	// 检查token族是否已被撤销
+	IsFamilyBlacklisted(familyID string) (bool, error)
+
 	// 根据用户ID获取黑名单记录
 	GetUserBlacklistTokens(userID uint) ([]*model.TokenBlacklist, error)
 
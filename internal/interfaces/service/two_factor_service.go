@@ -0,0 +1,29 @@
+package service
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+)
+
+// TwoFactorService 双因素认证服务接口
+type TwoFactorService interface {
+	// GetStatus 获取用户的双因素认证状态
+	GetStatus(userID uint) (*dto.TwoFactorStatusResp, error)
+
+	// Setup 生成新的TOTP密钥，确认动态码后才会启用
+	Setup(userID uint) (*dto.TwoFactorSetupResp, error)
+
+	// Enable 校验动态码并启用双因素认证，返回恢复码
+	Enable(userID uint, code string) (*dto.TwoFactorRecoveryCodesResp, error)
+
+	// Disable 校验动态码或恢复码后关闭双因素认证
+	Disable(userID uint, code string) error
+
+	// RegenerateRecoveryCodes 校验动态码后重新生成恢复码，原有恢复码全部失效
+	RegenerateRecoveryCodes(userID uint, code string) (*dto.TwoFactorRecoveryCodesResp, error)
+
+	// Verify 校验动态码或恢复码
+	Verify(userID uint, code string) error
+
+	// Reset 管理员重置用户的双因素认证，用户需重新绑定
+	Reset(userID uint) error
+}
@@ -8,10 +8,19 @@ import (
 // UserService 用户服务接口
 type UserService interface {
 	// Login 用户登录
-	Login(req *dto.UserLoginReq, clientIP string) (*dto.UserLoginResp, error)
+	Login(req *dto.UserLoginReq, clientIP string, userAgent string) (*dto.UserLoginResp, error)
+
+	// LoginOIDC 单点登录
+	LoginOIDC(req *dto.OIDCCallbackReq, clientIP string, userAgent string) (*dto.UserLoginResp, error)
+
+	// LoginTwoFactor 双因素认证登录
+	LoginTwoFactor(req *dto.TwoFactorLoginReq, clientIP string, userAgent string) (*dto.UserLoginResp, error)
+
+	// SetupLoginTwoFactor 登录时绑定验证器
+	SetupLoginTwoFactor(req *dto.TwoFactorTokenReq) (*dto.TwoFactorSetupResp, error)
 
 	// RefreshToken 刷新token
-	RefreshToken(req *dto.RefreshTokenReq) (*dto.UserLoginResp, error)
+	RefreshToken(req *dto.RefreshTokenReq, clientIP string) (*dto.UserLoginResp, error)
 
 	// GetUserInfo 获取用户信息
 	GetUserInfo(userID uint) (*dto.UserInfoResp, error)
@@ -20,25 +29,28 @@ type UserService interface {
 	GetUserList(req *dto.UserPageReq) (*dto.PageResp, error)
 
 	// GetUserByID 根据ID获取用户
-	GetUserByID(id uint) (*model.User, error)
+	GetUserByID(operatorID uint, id uint) (*model.User, error)
 
 	// CreateUser 创建用户
-	CreateUser(req *dto.UserCreateReq) (uint, error)
+	CreateUser(operatorID uint, req *dto.UserCreateReq) (uint, error)
 
 	// UpdateUser 更新用户
-	UpdateUser(req *dto.UserUpdateReq) error
+	UpdateUser(operatorID uint, req *dto.UserUpdateReq) error
 
 	// DeleteUser 删除用户
-	DeleteUser(id uint) error
+	DeleteUser(operatorID uint, id uint) error
 
 	// BatchDeleteUser 批量删除用户
-	BatchDeleteUser(ids []uint) error
+	BatchDeleteUser(operatorID uint, ids []uint) error
 
 	// ChangeUserStatus 修改用户状态
-	ChangeUserStatus(req *dto.StatusReq) error
+	ChangeUserStatus(operatorID uint, req *dto.StatusReq) error
+
+	// CheckUserManageable 检查操作者是否可以管理指定用户，内置用户仅允许本人或超级管理员操作
+	CheckUserManageable(operatorID uint, userID uint) error
 
-	// ResetUserPassword 重置用户密码
-	ResetUserPassword(id uint) error
+	// ResetUserPassword 重置用户密码，返回随机生成的一次性密码
+	ResetUserPassword(operatorID uint, id uint) (string, error)
 
 	// ChangePassword 修改密码
 	ChangePassword(userID uint, req *dto.UserChangePasswordReq) error
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/service"
+	"github.com/cuiyuanxin/kunpeng/pkg/casbin"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/cuiyuanxin/kunpeng/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Casbin 权限中间件，需在JWT中间件之后使用
+func Casbin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		casbinConfig := config.GetCasbinConfig()
+
+		// 未启用权限校验时直接放行
+		if !casbinConfig.Enable {
+			c.Next()
+			return
+		}
+
+		// 获取当前用户
+		userID := jwt.GetUserID(c)
+		if userID == 0 {
+			response.FailWithCode(c, kperrors.ErrPermDenied)
+			c.Abort()
+			return
+		}
+
+		// 获取用户的有效角色，包括继承的上级角色，禁用的角色不参与权限校验
+		roles, err := service.GetRoleService().GetEffectiveRoles(userID)
+		if err != nil {
+			response.Fail(c, err)
+			c.Abort()
+			return
+		}
+
+		roleCodes := make([]string, 0, len(roles))
+		isSuperAdmin := false
+		for _, role := range roles {
+			roleCodes = append(roleCodes, role.Code)
+			if casbinConfig.SuperAdmin != "" && role.Code == casbinConfig.SuperAdmin {
+				isSuperAdmin = true
+			}
+		}
+
+		// 没有有效角色时拒绝访问，区分角色被禁用和未分配角色
+		if len(roleCodes) == 0 {
+			userRoles, _ := service.GetRoleService().GetUserRoles(userID)
+			if len(userRoles) > 0 {
+				response.FailWithCode(c, kperrors.ErrPermRoleDisable)
+			} else {
+				response.FailWithCode(c, kperrors.ErrPermDenied)
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set("role_codes", roleCodes)
+
+		// 超级管理员角色跳过权限校验
+		if isSuperAdmin {
+			c.Next()
+			return
+		}
+
+		// 以用户为主体校验请求路径和方法，通过角色分组继承各角色的权限
+		ok, err := casbin.Enforce(casbin.UserSubject(userID), c.Request.URL.Path, c.Request.Method)
+		if err != nil {
+			response.Fail(c, err)
+			c.Abort()
+			return
+		}
+		if !ok {
+			response.FailWithCode(c, kperrors.ErrPermDenied)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
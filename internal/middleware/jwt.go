@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"slices"
+
 	"github.com/cuiyuanxin/kunpeng/internal/service"
 	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
 	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
@@ -9,7 +11,8 @@ import (
 )
 
 // JWT 中间件，用于验证JWT令牌
-func JWT() gin.HandlerFunc {
+// 默认只接受access token，allowedTypes为额外接受的token类型
+func JWT(allowedTypes ...jwt.TokenType) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 获取Authorization头
 		authHeader := c.GetHeader("Authorization")
@@ -35,8 +38,8 @@ func JWT() gin.HandlerFunc {
 			return
 		}
 
-		// 验证token类型必须是access token
-		if claims.TokenType != jwt.AccessTokenType {
+		// 验证token类型必须是access token或额外接受的类型
+		if claims.TokenType != jwt.AccessTokenType && !slices.Contains(allowedTypes, claims.TokenType) {
 			response.FailWithCode(c, kperrors.ErrInvalidToken)
 			c.Abort()
 			return
@@ -56,11 +59,36 @@ func JWT() gin.HandlerFunc {
 			return
 		}
 
+		// 检查token所属的token族是否已被撤销
+		isRevoked, err := tokenBlacklistService.IsFamilyBlacklisted(claims.FamilyID)
+		if err != nil {
+			response.FailWithCode(c, kperrors.ErrSystem)
+			c.Abort()
+			return
+		}
+		if isRevoked {
+			response.FailWithCode(c, kperrors.ErrInvalidToken)
+			c.Abort()
+			return
+		}
+
+		// 检查用户的token版本号，修改密码、重置密码、禁用或变更角色后此前签发的token全部失效
+		sessionService := service.GetSessionService()
+		if err := sessionService.CheckTokenVersion(claims.UserID, claims.Version); err != nil {
+			response.Fail(c, err)
+			c.Abort()
+			return
+		}
+
+		// 记录登录会话的最近活跃时间，会话被撤销时其token族已加入黑名单
+		sessionService.TouchSession(claims.FamilyID, c.ClientIP())
+
 		// 将用户信息存储到上下文
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
-		c.Set("role_id", claims.RoleID)
+		c.Set("role_ids", claims.RoleIDs)
 		c.Set("app_key", claims.AppKey)
+		c.Set("session_id", claims.FamilyID)
 
 		c.Next()
 	}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/service"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/cuiyuanxin/kunpeng/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePerm 按钮权限中间件，校验当前用户是否拥有指定权限标识，需在JWT中间件之后使用
+// 例如：auth.POST("/users", middleware.RequirePerm("system:user:add"), handler)
+func RequirePerm(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 未启用权限校验时直接放行
+		if !config.GetCasbinConfig().Enable {
+			c.Next()
+			return
+		}
+
+		userID := jwt.GetUserID(c)
+		if userID == 0 {
+			response.FailWithCode(c, kperrors.ErrPermDenied)
+			c.Abort()
+			return
+		}
+
+		ok, err := service.GetMenuService().HasPermission(userID, permission)
+		if err != nil {
+			response.Fail(c, err)
+			c.Abort()
+			return
+		}
+		if !ok {
+			response.FailWithCode(c, kperrors.ErrPermButton)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/cuiyuanxin/kunpeng/internal/service"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/response"
+	"github.com/cuiyuanxin/kunpeng/pkg/signature"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultSignatureMaxBodySize 参与签名的请求体默认最大字节数
+const defaultSignatureMaxBodySize = 10 << 20
+
+// Signature 签名认证中间件，使用AppKey/AppSecret签名代替Bearer token，用于服务端之间的调用
+// 签名内容为请求方法、路径、规范化的查询参数、AppKey、时间戳、随机串及请求体的SHA256
+func Signature() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		maxBodySize := config.GetAPISignatureConfig().MaxBodySize
+		if maxBodySize <= 0 {
+			maxBodySize = defaultSignatureMaxBodySize
+		}
+
+		// 读取请求体计算摘要后放回，后续处理器仍可正常绑定参数
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize))
+			if err != nil {
+				response.Fail(c, kperrors.New(kperrors.ErrSignature, err).WithMessage("请求体过大或读取失败"))
+				c.Abort()
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		req := &signature.Request{
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.EscapedPath(),
+			Query:     signature.CanonicalQuery(c.Request.URL.Query()),
+			AppKey:    c.GetHeader(signature.HeaderAppKey),
+			Timestamp: c.GetHeader(signature.HeaderTimestamp),
+			Nonce:     c.GetHeader(signature.HeaderNonce),
+			BodyHash:  signature.HashBody(body),
+		}
+		user, err := service.GetAPISignatureService().Verify(req, c.GetHeader(signature.HeaderSignature))
+		if err != nil {
+			response.Fail(c, err)
+			c.Abort()
+			return
+		}
+
+		// 将用户信息存储到上下文，与JWT中间件保持一致
+		c.Set("user_id", user.ID)
+		c.Set("username", user.Username)
+		c.Set("app_key", user.AppKey)
+
+		c.Next()
+	}
+}
+
+// JWTOrSignature 认证中间件，请求携带AppKey请求头时使用签名认证，否则使用JWT认证
+func JWTOrSignature() gin.HandlerFunc {
+	jwtHandler := JWT()
+	signatureHandler := Signature()
+	return func(c *gin.Context) {
+		if c.GetHeader(signature.HeaderAppKey) != "" {
+			signatureHandler(c)
+			return
+		}
+		jwtHandler(c)
+	}
+}
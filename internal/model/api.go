@@ -13,7 +13,8 @@ type API struct {
 	Name      string         `gorm:"size:100;not null" json:"name"`
 	Method    string         `gorm:"size:10;not null" json:"method"`
 	Path      string         `gorm:"size:100;not null" json:"path"`
-	Status    int8           `gorm:"default:1" json:"status"` // 0:禁用 1:启用
+	Status    int8           `gorm:"default:1" json:"status"`    // 0:禁用 1:启用
+	Stale     bool           `gorm:"default:false" json:"stale"` // 对应路由是否已不存在
 	Remark    string         `gorm:"size:255" json:"remark"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
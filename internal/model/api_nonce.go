@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+)
+
+// APINonce 签名请求使用过的随机串，用于防止请求被重放
+type APINonce struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	AppKey    string    `gorm:"size:50;not null;uniqueIndex:idx_app_key_nonce" json:"app_key"`
+	Nonce     string    `gorm:"size:64;not null;uniqueIndex:idx_app_key_nonce" json:"nonce"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"` // 过期时间，超过时钟偏差后请求时间戳已不再有效，记录可被清理
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 表名
+func (APINonce) TableName() string {
+	return "kp_api_nonce"
+}
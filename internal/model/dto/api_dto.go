@@ -38,3 +38,18 @@ type APIPageReq struct {
 type APIRoleResp struct {
 	APIIDs []uint `json:"api_ids"`
 }
+
+// RouteInfo 路由信息
+type RouteInfo struct {
+	Method string `json:"method" example:"GET"`
+	Path   string `json:"path" example:"/api/v1/users"`
+	Group  string `json:"group" example:"users"`
+	Name   string `json:"name" example:"获取用户列表"`
+}
+
+// APISyncResp 路由同步差异响应
+type APISyncResp struct {
+	Added     []*RouteInfo `json:"added"`     // 路由存在但API表中缺失，将被新增
+	Stale     []*RouteInfo `json:"stale"`     // API表中存在但路由已不存在，将被标记为失效
+	Recovered []*RouteInfo `json:"recovered"` // 已标记失效但路由重新存在，将取消失效标记
+}
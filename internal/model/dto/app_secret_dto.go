@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"time"
+)
+
+// AppSecretRotateReq 轮换AppSecret请求，需校验当前密码或双因素认证动态码
+type AppSecretRotateReq struct {
+	Password string `json:"password"`              // 当前密码
+	Code     string `json:"code" example:"123456"` // 双因素认证动态码或恢复码，已启用双因素认证时可代替当前密码
+}
+
+// AppSecretResp 轮换AppSecret响应，新的AppSecret仅返回一次
+type AppSecretResp struct {
+	AppKey        string     `json:"app_key"`
+	AppSecret     string     `json:"app_secret"`
+	PrevExpiresAt *time.Time `json:"prev_expires_at,omitempty"` // 原AppSecret的失效时间，配置了过渡期时返回
+}
@@ -1,5 +1,13 @@
 package dto
 
+import (
+	"errors"
+	"regexp"
+
+	"github.com/cuiyuanxin/kunpeng/pkg/constants"
+	"github.com/cuiyuanxin/kunpeng/pkg/i18n"
+)
+
 // PageReq 分页请求
 type PageReq struct {
 	PageNum   int    `form:"page_num" binding:"required,min=1" example:"1"`
@@ -23,6 +31,26 @@ type CaptchaResp struct {
 	CaptchaBase64 string `json:"captcha_base64"` // 验证码Base64
 }
 
+// SMSCodeReq 发送短信验证码请求
+type SMSCodeReq struct {
+	Mobile string `json:"mobile" binding:"required" example:"13800138000"` // 手机号
+}
+
+// Validate 自定义验证方法
+func (req *SMSCodeReq) Validate() error {
+	matched, _ := regexp.MatchString(constants.MobileRegex, req.Mobile)
+	if !matched {
+		return errors.New(i18n.TWithField("validator.mobile", "mobile"))
+	}
+	return nil
+}
+
+// SMSCodeResp 发送短信验证码响应
+type SMSCodeResp struct {
+	ExpiresIn int64 `json:"expires_in"` // 验证码有效期（秒）
+	Cooldown  int64 `json:"cooldown"`   // 重新发送的等待时间（秒）
+}
+
 // IDReq ID请求
 type IDReq struct {
 	ID uint `uri:"id" binding:"required" example:"1"` // ID
@@ -38,3 +66,11 @@ type StatusReq struct {
 	ID     uint `json:"id" binding:"required" example:"1"`     // ID
 	Status int8 `json:"status" binding:"required" example:"1"` // 状态
 }
+
+// DataScope 数据权限范围，根据当前用户的角色计算得出，为nil时不限制
+type DataScope struct {
+	All     bool   `json:"all"`      // 是否可访问全部数据
+	Self    bool   `json:"self"`     // 是否可访问本人数据
+	UserID  uint   `json:"user_id"`  // 当前用户ID
+	DeptIDs []uint `json:"dept_ids"` // 可访问的部门ID列表
+}
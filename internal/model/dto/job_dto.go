@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+// JobResp 定时任务响应
+type JobResp struct {
+	Name        string     `json:"name"`         // 任务名称
+	Description string     `json:"description"`  // 任务说明
+	Spec        string     `json:"spec"`         // cron表达式
+	Status      int8       `json:"status"`       // 0:已暂停 1:正常
+	Running     bool       `json:"running"`      // 是否正在执行
+	NextRunAt   *time.Time `json:"next_run_at"`  // 下一次计划执行时间，已暂停时为空
+	LastRunAt   *time.Time `json:"last_run_at"`  // 最近一次执行时间
+	LastStatus  *int8      `json:"last_status"`  // 最近一次执行状态 0:失败 1:成功 2:执行中
+	LastMessage string     `json:"last_message"` // 最近一次执行结果
+}
+
+// JobNameReq 定时任务名称请求
+type JobNameReq struct {
+	Name string `uri:"name" binding:"required" example:"token_cleanup"` // 任务名称
+}
+
+// JobLogListReq 定时任务执行记录列表请求
+type JobLogListReq struct {
+	PageNum   int    `form:"page_num" binding:"required,min=1" example:"1"`
+	PageSize  int    `form:"page_size" binding:"required,min=1,max=100" example:"10"`
+	JobName   string `form:"job_name" json:"job_name"` // 任务名称
+	Status    *int8  `form:"status" json:"status"`     // 执行状态
+	Trigger   string `form:"trigger" json:"trigger"`   // 触发方式 schedule, manual
+	BeginTime string `form:"begin_time" example:"2023-01-01 00:00:00"`
+	EndTime   string `form:"end_time" example:"2023-12-31 23:59:59"`
+}
+
+// JobLogResp 定时任务执行记录响应
+type JobLogResp struct {
+	ID         uint       `json:"id"`
+	JobName    string     `json:"job_name"`    // 任务名称
+	Trigger    string     `json:"trigger"`     // 触发方式 schedule, manual
+	Operator   string     `json:"operator"`    // 手动触发的操作人
+	Status     int8       `json:"status"`      // 执行状态
+	StatusName string     `json:"status_name"` // 执行状态名称
+	Message    string     `json:"message"`     // 执行结果或错误信息
+	StartedAt  time.Time  `json:"started_at"`  // 开始时间
+	FinishedAt *time.Time `json:"finished_at"` // 结束时间
+	Duration   int64      `json:"duration"`    // 耗时（毫秒）
+}
+
+// ToJobLogResp 转换为定时任务执行记录响应
+func ToJobLogResp(log *model.JobLog) *JobLogResp {
+	resp := &JobLogResp{
+		ID:         log.ID,
+		JobName:    log.JobName,
+		Trigger:    log.Trigger,
+		Operator:   log.Operator,
+		Status:     log.Status,
+		Message:    log.Message,
+		StartedAt:  log.StartedAt,
+		FinishedAt: log.FinishedAt,
+		Duration:   log.Duration,
+	}
+
+	// 设置状态名称
+	switch log.Status {
+	case model.JobLogStatusSuccess:
+		resp.StatusName = "成功"
+	case model.JobLogStatusRunning:
+		resp.StatusName = "执行中"
+	default:
+		resp.StatusName = "失败"
+	}
+
+	return resp
+}
+
+// ToJobLogRespList 转换为定时任务执行记录响应列表
+func ToJobLogRespList(logs []*model.JobLog) []*JobLogResp {
+	result := make([]*JobLogResp, 0, len(logs))
+	for _, log := range logs {
+		result = append(result, ToJobLogResp(log))
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+// LoginLockListReq 登录锁定列表请求
+type LoginLockListReq struct {
+	PageNum  int    `form:"page_num" binding:"required,min=1" example:"1"`
+	PageSize int    `form:"page_size" binding:"required,min=1,max=100" example:"10"`
+	Scope    string `form:"scope" json:"scope" binding:"omitempty,oneof=account ip"` // 锁定范围 account:账号 ip:IP
+	Subject  string `form:"subject" json:"subject"`                                  // 账号或IP
+}
+
+// LoginLockResp 登录锁定响应
+type LoginLockResp struct {
+	ID          uint       `json:"id"`
+	Scope       string     `json:"scope"`        // 锁定范围 account:账号 ip:IP
+	Subject     string     `json:"subject"`      // 账号或IP
+	Attempts    int        `json:"attempts"`     // 连续失败次数
+	LockCount   int        `json:"lock_count"`   // 连续锁定次数
+	LastTry     time.Time  `json:"last_try"`     // 最后失败时间
+	LastAccount string     `json:"last_account"` // 最后尝试的账号
+	LastIP      string     `json:"last_ip"`      // 最后尝试的IP
+	BlockedAt   *time.Time `json:"blocked_at"`   // 锁定时间
+	LockedUntil *time.Time `json:"locked_until"` // 锁定截止时间
+}
+
+// ToLoginLockResp 转换为登录锁定响应
+func ToLoginLockResp(attempt *model.LoginAttempt) *LoginLockResp {
+	return &LoginLockResp{
+		ID:          attempt.ID,
+		Scope:       attempt.Scope,
+		Subject:     attempt.Subject,
+		Attempts:    attempt.Attempts,
+		LockCount:   attempt.LockCount,
+		LastTry:     attempt.LastTry,
+		LastAccount: attempt.LastAccount,
+		LastIP:      attempt.LastIP,
+		BlockedAt:   attempt.BlockedAt,
+		LockedUntil: attempt.LockedUntil,
+	}
+}
+
+// ToLoginLockRespList 转换为登录锁定响应列表
+func ToLoginLockRespList(attempts []*model.LoginAttempt) []*LoginLockResp {
+	result := make([]*LoginLockResp, 0, len(attempts))
+	for _, attempt := range attempts {
+		result = append(result, ToLoginLockResp(attempt))
+	}
+	return result
+}
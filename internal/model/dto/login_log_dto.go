@@ -16,6 +16,8 @@ type LoginLogListReq struct {
 	IP        string `form:"ip" json:"ip"`             // IP地址
 	BeginTime string `form:"begin_time" example:"2023-01-01 00:00:00"`
 	EndTime   string `form:"end_time" example:"2023-12-31 23:59:59"`
+
+	DataScope *DataScope `form:"-" json:"-" swaggerignore:"true"` // 数据权限范围
 }
 
 // LoginLogResp 登录日志响应
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// OIDCAuthorizeResp 单点登录授权地址响应
+type OIDCAuthorizeResp struct {
+	AuthorizeURL string `json:"authorize_url"` // 身份提供方授权地址，前端跳转到该地址完成认证
+	State        string `json:"state"`         // 授权请求标识，回调时原样提交
+	ExpiresIn    int64  `json:"expires_in"`    // 授权请求有效期（秒）
+}
+
+// OIDCCallbackReq 单点登录回调请求
+type OIDCCallbackReq struct {
+	Code       string `json:"code" binding:"required"`  // 身份提供方回调返回的授权码
+	State      string `json:"state" binding:"required"` // 身份提供方回调返回的state
+	RememberMe bool   `json:"remember_me"`
+}
+
+// OIDCIdentityResp 当前用户的单点登录身份关联状态
+type OIDCIdentityResp struct {
+	Enabled     bool       `json:"enabled"`                 // 是否启用单点登录
+	Linked      bool       `json:"linked"`                  // 是否已关联身份
+	Issuer      string     `json:"issuer,omitempty"`        // 身份提供方标识
+	Email       string     `json:"email,omitempty"`         // 身份提供方返回的邮箱
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"` // 最近一次单点登录时间
+	LinkedAt    *time.Time `json:"linked_at,omitempty"`     // 关联时间
+}
@@ -16,6 +16,8 @@ type OperationLogListReq struct {
 	Method   string `form:"method" json:"method"`     // 请求方法
 	Status   *int8  `form:"status" json:"status"`     // 状态
 	IP       string `form:"ip" json:"ip"`             // IP地址
+
+	DataScope *DataScope `form:"-" json:"-" swaggerignore:"true"` // 数据权限范围
 }
 
 // OperationLogResp 操作日志响应
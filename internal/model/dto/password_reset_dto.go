@@ -0,0 +1,18 @@
+package dto
+
+// PasswordForgotReq 找回密码请求
+type PasswordForgotReq struct {
+	Account string `json:"account" binding:"required,max=100" example:"zhangsan"` // 用户名或邮箱
+}
+
+// PasswordForgotResp 找回密码响应，无论账号是否存在都返回相同结果
+type PasswordForgotResp struct {
+	ExpiresIn int64 `json:"expires_in"` // 重置链接有效期（秒）
+	Cooldown  int64 `json:"cooldown"`   // 重新发送的等待时间（秒）
+}
+
+// PasswordResetReq 重置密码请求
+type PasswordResetReq struct {
+	Token       string `json:"token" binding:"required"`                            // 重置邮件中的凭证
+	NewPassword string `json:"new_password" binding:"required" example:"Def456$%^"` // 新密码，按密码策略校验
+}
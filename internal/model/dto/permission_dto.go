@@ -0,0 +1,35 @@
+package dto
+
+// PermissionExplainReq 权限判定说明请求，用户ID和角色ID二选一
+type PermissionExplainReq struct {
+	UserID uint   `form:"user_id" binding:"required_without=RoleID" example:"1"`
+	RoleID uint   `form:"role_id" example:"1"`
+	Method string `form:"method" binding:"required" example:"POST"`
+	Path   string `form:"path" binding:"required" example:"/api/v1/users"`
+}
+
+// PermissionExplainResp 权限判定说明响应
+type PermissionExplainResp struct {
+	Subject       string             `json:"subject"`                   // Casbin主体，用户为 user:<id>，角色为角色编码
+	Method        string             `json:"method"`                    // 请求方法
+	Path          string             `json:"path"`                      // 请求路径
+	Allowed       bool               `json:"allowed"`                   // 是否允许访问
+	Reason        string             `json:"reason"`                    // 判定原因
+	CasbinEnabled bool               `json:"casbin_enabled"`            // 是否启用权限校验
+	SuperAdmin    bool               `json:"super_admin"`               // 是否为超级管理员
+	MatchedPolicy []string           `json:"matched_policy"`            // 命中的策略 [角色编码, 路径, 方法]
+	RoleChain     []string           `json:"role_chain"`                // 主体拥有的角色，包括继承的上级角色
+	API           *PermissionAPIInfo `json:"api"`                       // 匹配的API记录，未登记时为空
+	DataScope     *DataScope         `json:"data_scope,omitempty"`      // 用户的数据权限范围
+	RoleDataScope *RoleDataScopeResp `json:"role_data_scope,omitempty"` // 角色的数据权限配置
+}
+
+// PermissionAPIInfo 权限判定匹配的API信息
+type PermissionAPIInfo struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Disabled bool   `json:"disabled"` // 是否已禁用，禁用的API不会同步到Casbin策略
+	Stale    bool   `json:"stale"`    // 对应路由是否已不存在
+}
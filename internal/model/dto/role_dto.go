@@ -2,21 +2,25 @@ package dto
 
 // RoleCreateReq 创建角色请求
 type RoleCreateReq struct {
-	Name   string `json:"name" binding:"required" example:"测试角色"`
-	Code   string `json:"code" binding:"required" example:"test"`
-	Sort   int    `json:"sort" example:"0"`
-	Status int8   `json:"status" example:"1"`
-	Remark string `json:"remark" example:"测试角色"`
+	ParentID         uint   `json:"parent_id" example:"0"`
+	Name             string `json:"name" binding:"required" example:"测试角色"`
+	Code             string `json:"code" binding:"required" example:"test"`
+	Sort             int    `json:"sort" example:"0"`
+	Status           int8   `json:"status" example:"1"`
+	Remark           string `json:"remark" example:"测试角色"`
+	RequireTwoFactor bool   `json:"require_two_factor" example:"false"` // 是否要求拥有该角色的用户启用双因素认证
 }
 
 // RoleUpdateReq 更新角色请求
 type RoleUpdateReq struct {
-	ID     uint   `json:"id" binding:"required" example:"1"`
-	Name   string `json:"name" binding:"required" example:"测试角色"`
-	Code   string `json:"code" binding:"required" example:"test"`
-	Sort   int    `json:"sort" example:"0"`
-	Status int8   `json:"status" example:"1"`
-	Remark string `json:"remark" example:"测试角色"`
+	ID               uint   `json:"id" binding:"required" example:"1"`
+	ParentID         uint   `json:"parent_id" example:"0"`
+	Name             string `json:"name" binding:"required" example:"测试角色"`
+	Code             string `json:"code" binding:"required" example:"test"`
+	Sort             int    `json:"sort" example:"0"`
+	Status           int8   `json:"status" example:"1"`
+	Remark           string `json:"remark" example:"测试角色"`
+	RequireTwoFactor bool   `json:"require_two_factor" example:"false"` // 是否要求拥有该角色的用户启用双因素认证
 }
 
 // RolePageReq 角色分页请求
@@ -41,3 +45,27 @@ type RoleAPIReq struct {
 	RoleID uint   `json:"role_id" binding:"required" example:"1"`
 	APIIDs []uint `json:"api_ids" binding:"required" example:"[1,2,3]"`
 }
+
+// RoleTreeResp 角色树响应
+type RoleTreeResp struct {
+	ID       uint            `json:"id"`
+	ParentID uint            `json:"parent_id"`
+	Name     string          `json:"name"`
+	Code     string          `json:"code"`
+	Sort     int             `json:"sort"`
+	Status   int8            `json:"status"`
+	Children []*RoleTreeResp `json:"children"`
+}
+
+// RoleDataScopeReq 角色数据权限请求
+type RoleDataScopeReq struct {
+	RoleID    uint   `json:"role_id" binding:"required" example:"1"`
+	DataScope int8   `json:"data_scope" binding:"required,oneof=1 2 3 4 5" example:"2"` // 1:全部 2:自定义部门 3:本部门 4:本部门及以下 5:仅本人
+	DeptIDs   []uint `json:"dept_ids" example:"[1,2,3]"`                                // 自定义部门时有效
+}
+
+// RoleDataScopeResp 角色数据权限响应
+type RoleDataScopeResp struct {
+	DataScope int8   `json:"data_scope"`
+	DeptIDs   []uint `json:"dept_ids"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+// TwoFactorCodeReq 双因素认证动态码请求
+type TwoFactorCodeReq struct {
+	Code string `json:"code" binding:"required" example:"123456"` // 验证器应用中的6位动态码，部分操作也可使用恢复码
+}
+
+// TwoFactorLoginReq 双因素认证登录请求
+type TwoFactorLoginReq struct {
+	TwoFactorToken string `json:"two_factor_token" binding:"required"`      // 登录第一步返回的凭证
+	Code           string `json:"code" binding:"required" example:"123456"` // 6位动态码或恢复码
+}
+
+// TwoFactorTokenReq 双因素认证凭证请求
+type TwoFactorTokenReq struct {
+	TwoFactorToken string `json:"two_factor_token" binding:"required"` // 登录第一步返回的凭证
+}
+
+// TwoFactorStatusResp 双因素认证状态响应
+type TwoFactorStatusResp struct {
+	Enabled       bool  `json:"enabled"`        // 是否已启用
+	Required      bool  `json:"required"`       // 所属角色是否要求启用
+	RecoveryCodes int64 `json:"recovery_codes"` // 剩余可用的恢复码数量
+}
+
+// TwoFactorSetupResp 双因素认证绑定响应
+type TwoFactorSetupResp struct {
+	Secret     string `json:"secret"`      // Base32编码的密钥，可手动输入验证器应用
+	OtpauthURI string `json:"otpauth_uri"` // otpauth:// 格式的URI，可生成二维码供验证器应用扫描
+}
+
+// TwoFactorRecoveryCodesResp 双因素认证恢复码响应
+type TwoFactorRecoveryCodesResp struct {
+	RecoveryCodes []string `json:"recovery_codes"` // 恢复码，仅显示一次，每个只能使用一次
+}
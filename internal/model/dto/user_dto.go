@@ -8,13 +8,23 @@ import (
 	"github.com/cuiyuanxin/kunpeng/pkg/i18n"
 )
 
+// 登录类型
+const (
+	LoginTypeUsername = "username" // 用户名及本地密码登录
+	LoginTypeMobile   = "mobile"   // 手机号及短信验证码登录
+	LoginTypeLDAP     = "ldap"     // LDAP目录账号登录
+)
+
+// ldapAccountMaxLength LDAP登录账号的最大长度
+const ldapAccountMaxLength = 64
+
 // UserLoginReq 用户登录请求
 type UserLoginReq struct {
-	LoginType  string `json:"login_type" binding:"required,oneof=username mobile" example:"username"` // username: 用户名登录, mobile: 手机号登录
-	Account    string `json:"account" binding:"required" example:"admin"`                             // 账号（用户名或手机号）
-	Password   string `json:"password" example:"Abc123!@#"`                                           // 密码（账号登录时必填）
-	Captcha    string `json:"captcha" example:"123456"`                                               // 验证码（手机号登录时必填）
-	CaptchaID  string `json:"captcha_id" example:"abcd1234"`
+	LoginType  string `json:"login_type" binding:"required,oneof=username mobile ldap" example:"username"` // username: 用户名登录, mobile: 手机号登录, ldap: LDAP目录账号登录
+	Account    string `json:"account" binding:"required" example:"admin"`                                  // 账号（用户名、手机号或目录账号）
+	Password   string `json:"password" example:"Abc123!@#"`                                                // 密码（账号及LDAP登录时必填）
+	Captcha    string `json:"captcha" example:"123456"`                                                    // 验证码（手机号登录时为短信验证码，账号登录失败次数过多后需填写图片验证码）
+	CaptchaID  string `json:"captcha_id" example:"abcd1234"`                                               // 图片验证码ID
 	RememberMe bool   `json:"remember_me"`
 }
 
@@ -26,21 +36,18 @@ func (req *UserLoginReq) Validate() error {
 // validateInternal 内部验证方法
 func (req *UserLoginReq) validateInternal() error {
 	// 根据登录类型验证账号格式和必填字段
-	if req.LoginType == "username" {
+	switch req.LoginType {
+	case LoginTypeUsername:
 		// 验证用户名格式
 		matched, _ := regexp.MatchString(constants.UsernameRegex, req.Account)
 		if !matched {
 			return errors.New(i18n.TWithField("validator.username", "username"))
 		}
-		// 账号登录必须有密码
+		// 账号登录必须有密码，不校验密码格式，密码策略变更前设置的密码仍可登录
 		if req.Password == "" {
 			return errors.New(i18n.TWithField("validator.required", "password"))
 		}
-		// 验证密码格式
-		if !constants.ValidatePassword(req.Password) {
-			return errors.New(i18n.TWithField("validator.password", "password"))
-		}
-	} else if req.LoginType == "mobile" {
+	case LoginTypeMobile:
 		// 验证手机号格式
 		matched, _ := regexp.MatchString(constants.MobileRegex, req.Account)
 		if !matched {
@@ -55,16 +62,32 @@ func (req *UserLoginReq) validateInternal() error {
 		if !matched {
 			return errors.New(i18n.TWithField("validator.captcha", "captcha"))
 		}
+	case LoginTypeLDAP:
+		// 目录账号的格式由目录决定，只限制长度
+		if len(req.Account) > ldapAccountMaxLength {
+			return errors.New(i18n.T("validator.max", map[string]interface{}{"Field": "account", "Max": ldapAccountMaxLength}))
+		}
+		if req.Password == "" {
+			return errors.New(i18n.TWithField("validator.required", "password"))
+		}
 	}
 	return nil
 }
 
 // UserLoginResp 用户登录响应
+// 需要双因素认证时只返回 two_factor_token，使用该凭证完成第二步认证后才返回token对
+// 需要修改密码时只返回 password_change_token，修改密码后需使用新密码重新登录
 type UserLoginResp struct {
-	AccessToken      string `json:"access_token"`
-	RefreshToken     string `json:"refresh_token"`
-	ExpiresIn        int64  `json:"expires_in"`         // access token过期时间（秒）
-	RefreshExpiresIn int64  `json:"refresh_expires_in"` // refresh token过期时间（秒）
+	AccessToken            string   `json:"access_token"`
+	RefreshToken           string   `json:"refresh_token"`
+	ExpiresIn              int64    `json:"expires_in"`                         // access token过期时间（秒）
+	RefreshExpiresIn       int64    `json:"refresh_expires_in"`                 // refresh token过期时间（秒）
+	TwoFactorRequired      bool     `json:"two_factor_required,omitempty"`      // 是否需要双因素认证
+	TwoFactorSetup         bool     `json:"two_factor_setup,omitempty"`         // 是否需要先绑定验证器，所属角色要求双因素认证但尚未启用时为true
+	TwoFactorToken         string   `json:"two_factor_token,omitempty"`         // 登录第二步凭证
+	RecoveryCodes          []string `json:"recovery_codes,omitempty"`           // 登录时完成绑定返回的恢复码，仅显示一次
+	PasswordChangeRequired bool     `json:"password_change_required,omitempty"` // 是否需要先修改密码，管理员创建或重置密码后首次登录及密码过期时为true
+	PasswordChangeToken    string   `json:"password_change_token,omitempty"`    // 修改密码凭证，仅可用于调用修改密码接口
 }
 
 // RefreshTokenReq 刷新token请求
@@ -74,21 +97,22 @@ type RefreshTokenReq struct {
 
 // UserInfoResp 用户信息响应
 type UserInfoResp struct {
-	ID       uint   `json:"userId"`
-	Username string `json:"username"`
-	Nickname string `json:"nickname"`
-	RealName string `json:"realname"`
-	Avatar   string `json:"avatar"`
-	Gender   int8   `json:"gender"`
-	RoleName string `json:"role_name"`
-	DeptName string `json:"dept_name"`
-	PostName string `json:"post_name"`
+	ID          uint     `json:"userId"`
+	Username    string   `json:"username"`
+	Nickname    string   `json:"nickname"`
+	RealName    string   `json:"realname"`
+	Avatar      string   `json:"avatar"`
+	Gender      int8     `json:"gender"`
+	RoleNames   []string `json:"role_names"`
+	Permissions []string `json:"permissions"` // 权限标识，超级管理员为 *:*:*
+	DeptName    string   `json:"dept_name"`
+	PostName    string   `json:"post_name"`
 }
 
 // UserCreateReq 创建用户请求
 type UserCreateReq struct {
 	Username string `json:"username" binding:"required,username" example:"zhangsan"`
-	Password string `json:"password" binding:"required,password" example:"Abc123!@#"`
+	Password string `json:"password" binding:"required" example:"Abc123!@#"` // 按密码策略校验
 	Nickname string `json:"nickname" example:"张三"`
 	RealName string `json:"real_name" example:"张三"`
 	Avatar   string `json:"avatar" example:"https://example.com/avatar.png"`
@@ -97,7 +121,7 @@ type UserCreateReq struct {
 	Mobile   string `json:"mobile" example:"13800138000"`
 	DeptID   uint   `json:"dept_id" example:"1"`
 	PostID   uint   `json:"post_id" example:"1"`
-	RoleID   uint   `json:"role_id" example:"1"`
+	RoleIDs  []uint `json:"role_ids" example:"[1,2]"`
 	Status   int8   `json:"status" example:"1"`
 	Remark   string `json:"remark" example:"测试用户"`
 }
@@ -113,15 +137,20 @@ type UserUpdateReq struct {
 	Mobile   string `json:"mobile" example:"13800138000"`
 	DeptID   uint   `json:"dept_id" example:"1"`
 	PostID   uint   `json:"post_id" example:"1"`
-	RoleID   uint   `json:"role_id" example:"1"`
+	RoleIDs  []uint `json:"role_ids" example:"[1,2]"`
 	Status   int8   `json:"status" example:"1"`
 	Remark   string `json:"remark" example:"测试用户"`
 }
 
 // UserChangePasswordReq 修改密码请求
 type UserChangePasswordReq struct {
-	OldPassword string `json:"old_password" binding:"required,password" example:"Abc123!@#"`
-	NewPassword string `json:"new_password" binding:"required,password" example:"Def456$%^"`
+	OldPassword string `json:"old_password" binding:"required" example:"Abc123!@#"`
+	NewPassword string `json:"new_password" binding:"required" example:"Def456$%^"` // 按密码策略校验
+}
+
+// UserResetPasswordResp 重置密码响应
+type UserResetPasswordResp struct {
+	Password string `json:"password"` // 随机生成的一次性密码，仅返回一次，用户登录后需立即修改
 }
 
 // UserPageReq 用户分页请求
@@ -135,4 +164,6 @@ type UserPageReq struct {
 	DeptID    uint   `form:"dept_id" example:"1"`
 	BeginTime string `form:"begin_time" example:"2023-01-01 00:00:00"`
 	EndTime   string `form:"end_time" example:"2023-12-31 23:59:59"`
+
+	DataScope *DataScope `form:"-" json:"-" swaggerignore:"true"` // 数据权限范围
 }
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// UserSessionResp 用户登录会话响应
+type UserSessionResp struct {
+	ID         uint      `json:"id"`           // 会话ID
+	IP         string    `json:"ip"`           // 最近访问IP
+	Browser    string    `json:"browser"`      // 浏览器
+	OS         string    `json:"os"`           // 操作系统
+	Device     string    `json:"device"`       // 设备类型
+	LoginAt    time.Time `json:"login_at"`     // 登录时间
+	LastSeenAt time.Time `json:"last_seen_at"` // 最近活跃时间
+	ExpiresAt  time.Time `json:"expires_at"`   // 过期时间
+	Current    bool      `json:"current"`      // 是否为当前请求所属的会话
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+// 定时任务状态
+const (
+	JobStatusPaused int8 = 0 // 已暂停
+	JobStatusNormal int8 = 1 // 正常
+)
+
+// Job 定时任务模型，任务由代码注册，表中仅保存暂停状态，多实例共享
+type Job struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Name      string    `gorm:"size:50;not null;uniqueIndex" json:"name"` // 任务名称
+	Status    int8      `gorm:"not null;default:1" json:"status"`         // 0:已暂停 1:正常
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 表名
+func (Job) TableName() string {
+	return "kp_job"
+}
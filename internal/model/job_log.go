@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+)
+
+// 定时任务执行状态
+const (
+	JobLogStatusFailed  int8 = 0 // 失败
+	JobLogStatusSuccess int8 = 1 // 成功
+	JobLogStatusRunning int8 = 2 // 执行中
+)
+
+// 定时任务触发方式
+const (
+	JobTriggerSchedule = "schedule" // 按计划执行
+	JobTriggerManual   = "manual"   // 手动触发
+)
+
+// JobLog 定时任务执行记录模型
+type JobLog struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	JobName    string     `gorm:"size:50;not null;index" json:"job_name"` // 任务名称
+	Trigger    string     `gorm:"size:20;not null" json:"trigger"`        // 触发方式 schedule, manual
+	Operator   string     `gorm:"size:50" json:"operator"`                // 手动触发的操作人
+	Status     int8       `gorm:"not null" json:"status"`                 // 0:失败 1:成功 2:执行中
+	Message    string     `gorm:"size:500" json:"message"`                // 执行结果或错误信息
+	StartedAt  time.Time  `gorm:"not null;index" json:"started_at"`       // 开始时间
+	FinishedAt *time.Time `json:"finished_at"`                            // 结束时间
+	Duration   int64      `json:"duration"`                               // 耗时（毫秒）
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TableName 表名
+func (JobLog) TableName() string {
+	return "kp_job_log"
+}
@@ -2,21 +2,28 @@ package model
 
 import (
 	"time"
+)
 
-	"gorm.io/gorm"
+// 登录失败计数范围
+const (
+	LoginAttemptScopeAccount = "account" // 按账号计数，不区分IP
+	LoginAttemptScopeIP      = "ip"      // 按IP计数，不区分账号，用于防范撞库
 )
 
-// LoginAttempt 登录尝试记录模型
+// LoginAttempt 登录尝试记录模型，按账号和按IP分别记录连续登录失败次数及锁定状态
 type LoginAttempt struct {
-	ID        uint           `gorm:"primarykey" json:"id"`
-	Account   string         `gorm:"size:100;not null;index" json:"account"` // 登录账号（用户名或手机号）
-	IP        string         `gorm:"size:50;not null;index" json:"ip"`       // 登录IP
-	Attempts  int            `gorm:"default:1" json:"attempts"`              // 失败次数
-	LastTry   time.Time      `gorm:"not null" json:"last_try"`               // 最后尝试时间
-	BlockedAt *time.Time     `json:"blocked_at"`                             // 拉黑时间
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID          uint       `gorm:"primarykey" json:"id"`
+	Scope       string     `gorm:"size:20;not null;uniqueIndex:idx_scope_subject" json:"scope"`    // 计数范围 account, ip
+	Subject     string     `gorm:"size:100;not null;uniqueIndex:idx_scope_subject" json:"subject"` // 登录账号（用户名或手机号）或IP
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`                             // 连续失败次数
+	LockCount   int        `gorm:"not null;default:0" json:"lock_count"`                           // 连续锁定次数，用于计算递增的锁定时长
+	LastTry     time.Time  `gorm:"not null;index" json:"last_try"`                                 // 最后失败时间
+	LastAccount string     `gorm:"size:100" json:"last_account"`                                   // 最后尝试的账号
+	LastIP      string     `gorm:"column:last_ip;size:50" json:"last_ip"`                          // 最后尝试的IP
+	BlockedAt   *time.Time `json:"blocked_at"`                                                     // 锁定时间
+	LockedUntil *time.Time `gorm:"index" json:"locked_until"`                                      // 锁定截止时间
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
 // TableName 表名
@@ -24,28 +31,24 @@ func (LoginAttempt) TableName() string {
 	return "kp_login_attempt"
 }
 
-// IsBlocked 检查是否被拉黑
+// IsBlocked 检查是否处于锁定中
 func (la *LoginAttempt) IsBlocked() bool {
-	if la.BlockedAt == nil {
-		return false
-	}
-	// 检查拉黑是否已过期（2小时）
-	return time.Since(*la.BlockedAt) < 2*time.Hour
-}
-
-// ShouldBlock 检查是否应该拉黑
-func (la *LoginAttempt) ShouldBlock() bool {
-	return la.Attempts >= 5
+	return la.LockedUntil != nil && time.Now().Before(*la.LockedUntil)
 }
 
-// Block 拉黑账号
-func (la *LoginAttempt) Block() {
+// Block 锁定指定时长
+func (la *LoginAttempt) Block(duration time.Duration) {
 	now := time.Now()
+	until := now.Add(duration)
+	la.LockCount++
 	la.BlockedAt = &now
+	la.LockedUntil = &until
 }
 
-// Reset 重置登录尝试记录
+// Reset 重置失败次数及锁定状态
 func (la *LoginAttempt) Reset() {
 	la.Attempts = 0
+	la.LockCount = 0
 	la.BlockedAt = nil
+	la.LockedUntil = nil
 }
@@ -6,6 +6,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// AllPermission 超级管理员拥有的全部权限标识
+const AllPermission = "*:*:*"
+
 // Menu 菜单模型
 type Menu struct {
 	ID         uint           `gorm:"primarykey" json:"id"`
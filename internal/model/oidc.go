@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+)
+
+// OIDCState 单点登录授权请求记录，用于校验回调的state并保存PKCE的code_verifier和nonce
+type OIDCState struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	State        string     `gorm:"size:64;not null;uniqueIndex:idx_state" json:"-"`
+	Nonce        string     `gorm:"size:64;not null" json:"-"`
+	CodeVerifier string     `gorm:"size:128;not null" json:"-"`
+	UserID       uint       `gorm:"not null;default:0" json:"user_id"` // 发起绑定的用户ID，登录时为0
+	IP           string     `gorm:"column:ip;size:50;not null" json:"ip"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"` // 过期时间
+	UsedAt       *time.Time `json:"used_at"`                    // 使用时间
+	CreatedAt    time.Time  `gorm:"index" json:"created_at"`
+}
+
+// TableName 表名
+func (OIDCState) TableName() string {
+	return "kp_oidc_state"
+}
+
+// IsExpired 检查授权请求是否已过期
+func (s *OIDCState) IsExpired() bool {
+	return time.Now().After(s.ExpiresAt)
+}
+
+// UserIdentity 用户关联的外部身份，每个用户在同一身份提供方只能关联一个身份
+type UserIdentity struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	UserID      uint       `gorm:"not null;uniqueIndex:idx_user_issuer" json:"user_id"`
+	Issuer      string     `gorm:"size:255;not null;uniqueIndex:idx_issuer_subject;uniqueIndex:idx_user_issuer" json:"issuer"` // 身份提供方标识
+	Subject     string     `gorm:"size:255;not null;uniqueIndex:idx_issuer_subject" json:"subject"`                            // 身份提供方的用户唯一标识
+	Email       string     `gorm:"size:100" json:"email"`                                                                      // 最近一次登录时身份提供方返回的邮箱
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName 表名
+func (UserIdentity) TableName() string {
+	return "kp_user_identity"
+}
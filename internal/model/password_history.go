@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+)
+
+// PasswordHistory 密码历史模型，记录用户最近使用过的密码哈希，用于禁止重复使用
+type PasswordHistory struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"` // 用户ID
+	Password  string    `gorm:"size:100;not null" json:"-"`    // 密码哈希
+	CreatedAt time.Time `gorm:"index" json:"created_at"`       // 设置时间
+}
+
+// TableName 表名
+func (PasswordHistory) TableName() string {
+	return "kp_password_history"
+}
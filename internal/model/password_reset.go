@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+)
+
+// PasswordReset 找回密码请求记录模型，用于限制发送频率并保证重置链接只能使用一次
+type PasswordReset struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	Account   string     `gorm:"size:100;not null;index" json:"account"`  // 请求时填写的用户名或邮箱
+	UserID    uint       `gorm:"not null;default:0;index" json:"user_id"` // 用户ID，账号不存在、未绑定邮箱或超出发送限制时为0
+	JTI       string     `gorm:"column:jti;size:64;index" json:"-"`       // 重置凭证的jti，未发送邮件时为空
+	IP        string     `gorm:"column:ip;size:50;not null;index" json:"ip"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"` // 过期时间
+	UsedAt    *time.Time `json:"used_at"`                    // 使用时间
+	CreatedAt time.Time  `gorm:"index" json:"created_at"`
+}
+
+// TableName 表名
+func (PasswordReset) TableName() string {
+	return "kp_password_reset"
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+)
+
+// RefreshToken refresh token签发记录模型，用于保证refresh token只能使用一次并检测重复使用
+type RefreshToken struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	JTI       string     `gorm:"column:jti;size:64;not null;uniqueIndex" json:"jti"` // refresh token的jti
+	FamilyID  string     `gorm:"size:64;not null;index" json:"family_id"`            // token族ID
+	UserID    uint       `gorm:"not null;index" json:"user_id"`                      // 用户ID
+	UsedAt    *time.Time `json:"used_at"`                                            // 轮换时间，已轮换的token再次使用视为泄露
+	RevokedAt *time.Time `json:"revoked_at"`                                         // 撤销时间
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"`                   // 过期时间
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName 表名
+func (RefreshToken) TableName() string {
+	return "kp_refresh_token"
+}
@@ -6,17 +6,30 @@ import (
 	"gorm.io/gorm"
 )
 
+// 角色数据权限范围
+const (
+	DataScopeAll          int8 = 1 // 全部数据
+	DataScopeCustom       int8 = 2 // 自定义部门数据
+	DataScopeDept         int8 = 3 // 本部门数据
+	DataScopeDeptAndChild int8 = 4 // 本部门及以下数据
+	DataScopeSelf         int8 = 5 // 仅本人数据
+)
+
 // Role 角色模型
 type Role struct {
-	ID        uint           `gorm:"primarykey" json:"id"`
-	Name      string         `gorm:"size:50;not null" json:"name"`
-	Code      string         `gorm:"size:50;not null;uniqueIndex" json:"code"`
-	Sort      int            `gorm:"default:0" json:"sort"`
-	Status    int8           `gorm:"default:1" json:"status"` // 0:禁用 1:启用
-	Remark    string         `gorm:"size:255" json:"remark"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID               uint           `gorm:"primarykey" json:"id"`
+	ParentID         uint           `gorm:"default:0;index" json:"parent_id"` // 上级角色ID，子角色继承上级角色的菜单和API权限
+	Name             string         `gorm:"size:50;not null" json:"name"`
+	Code             string         `gorm:"size:50;not null;uniqueIndex" json:"code"`
+	Sort             int            `gorm:"default:0" json:"sort"`
+	DataScope        int8           `gorm:"default:1" json:"data_scope"`             // 数据权限范围 1:全部 2:自定义部门 3:本部门 4:本部门及以下 5:仅本人
+	Status           int8           `gorm:"default:1" json:"status"`                 // 0:禁用 1:启用
+	IsBuiltin        bool           `gorm:"default:false" json:"is_builtin"`         // 内置角色不允许删除、禁用或修改编码
+	RequireTwoFactor bool           `gorm:"default:false" json:"require_two_factor"` // 拥有该角色的用户登录时必须通过双因素认证
+	Remark           string         `gorm:"size:255" json:"remark"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // TableName 表名
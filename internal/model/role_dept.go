@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+)
+
+// RoleDept 角色部门关联模型，用于自定义数据权限
+type RoleDept struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	RoleID    uint      `gorm:"not null;index:idx_role_dept,unique" json:"role_id"`
+	DeptID    uint      `gorm:"not null;index:idx_role_dept,unique" json:"dept_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 表名
+func (RoleDept) TableName() string {
+	return "kp_role_dept"
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+)
+
+// 短信验证码使用场景
+const (
+	SMSSceneLogin = "login" // 手机号登录
+)
+
+// SMSCode 短信验证码发送记录模型
+type SMSCode struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	Mobile    string     `gorm:"size:20;not null;index" json:"mobile"` // 手机号
+	Scene     string     `gorm:"size:20;not null" json:"scene"`        // 使用场景
+	CodeHash  string     `gorm:"size:64;not null" json:"-"`            // 验证码哈希
+	IP        string     `gorm:"size:50;not null;index" json:"ip"`     // 请求IP
+	Attempts  int        `gorm:"not null;default:0" json:"attempts"`   // 校验失败次数
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`           // 过期时间
+	UsedAt    *time.Time `json:"used_at"`                              // 使用时间，校验通过或失败次数过多后失效
+	CreatedAt time.Time  `gorm:"index" json:"created_at"`
+}
+
+// TableName 表名
+func (SMSCode) TableName() string {
+	return "kp_sms_code"
+}
+
+// IsExpired 检查验证码是否已过期
+func (c *SMSCode) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
@@ -9,13 +9,14 @@ import (
 // TokenBlacklist token黑名单模型
 type TokenBlacklist struct {
 	ID        uint           `json:"id" gorm:"primarykey"`
-	Token     string         `json:"token" gorm:"type:text;not null;comment:被拉黑的token"`
+	TokenKey  string         `json:"token_key" gorm:"type:varchar(80);not null;uniqueIndex;comment:撤销key，token的SHA-256哈希或token族ID"`
+	FamilyID  string         `json:"family_id" gorm:"type:varchar(64);index;comment:被撤销的token族ID"`
 	UserID    uint           `json:"user_id" gorm:"not null;comment:用户ID"`
 	Username  string         `json:"username" gorm:"type:varchar(50);not null;comment:用户名"`
 	Reason    string         `json:"reason" gorm:"type:varchar(100);comment:拉黑原因"`
 	ExpiresAt time.Time      `json:"expires_at" gorm:"not null;comment:token原始过期时间"`
 	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"index"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
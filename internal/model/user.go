@@ -8,27 +8,34 @@ import (
 
 // User 用户模型
 type User struct {
-	ID        uint           `gorm:"primarykey" json:"id"`
-	Username  string         `gorm:"size:50;not null;uniqueIndex" json:"username"`
-	Password  string         `gorm:"size:100;not null" json:"-"`
-	Nickname  string         `gorm:"size:50" json:"nickname"`
-	RealName  string         `gorm:"size:50" json:"real_name"`
-	Avatar    string         `gorm:"size:255" json:"avatar"`
-	Gender    int8           `gorm:"default:0" json:"gender"` // 0:未知 1:男 2:女
-	Email     string         `gorm:"size:100;uniqueIndex" json:"email"`
-	Mobile    string         `gorm:"size:20;uniqueIndex" json:"mobile"`
-	DeptID    uint           `gorm:"index" json:"dept_id"`
-	PostID    uint           `gorm:"index" json:"post_id"`
-	RoleID    uint           `gorm:"index" json:"role_id"`
-	Status    int8           `gorm:"default:1" json:"status"` // 0:禁用 1:启用
-	LoginIP   string         `gorm:"size:50" json:"login_ip"`
-	LoginTime *time.Time     `json:"login_time"`
-	AppKey    string         `gorm:"size:100" json:"app_key"`
-	AppSecret string         `gorm:"size:100" json:"-"`
-	Remark    string         `gorm:"size:255" json:"remark"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                 uint           `gorm:"primarykey" json:"id"`
+	Username           string         `gorm:"size:50;not null;uniqueIndex" json:"username"`
+	Password           string         `gorm:"size:100;not null" json:"-"`
+	Nickname           string         `gorm:"size:50" json:"nickname"`
+	RealName           string         `gorm:"size:50" json:"real_name"`
+	Avatar             string         `gorm:"size:255" json:"avatar"`
+	Gender             int8           `gorm:"default:0" json:"gender"` // 0:未知 1:男 2:女
+	Email              string         `gorm:"size:100;uniqueIndex" json:"email"`
+	Mobile             string         `gorm:"size:20;uniqueIndex" json:"mobile"`
+	DeptID             uint           `gorm:"index" json:"dept_id"`
+	PostID             uint           `gorm:"index" json:"post_id"`
+	Status             int8           `gorm:"default:1" json:"status"`         // 0:禁用 1:启用
+	IsBuiltin          bool           `gorm:"default:false" json:"is_builtin"` // 内置用户不允许删除或禁用
+	LoginIP            string         `gorm:"size:50" json:"login_ip"`
+	LoginTime          *time.Time     `json:"login_time"`
+	AppKey             string         `gorm:"size:100;index" json:"app_key"`
+	AppSecret          string         `gorm:"size:100" json:"-"`
+	PrevAppSecret      string         `gorm:"size:100" json:"-"`                                  // 轮换前的AppSecret，过渡期内仍可用于签名
+	PrevAppSecretUntil *time.Time     `json:"-"`                                                  // 轮换前的AppSecret的有效截止时间
+	AppSecretRotatedAt *time.Time     `json:"app_secret_rotated_at"`                              // AppSecret最近轮换时间
+	TokenVersion       uint           `gorm:"not null;default:0" json:"-"`                        // token版本号，递增后此前签发的token全部失效
+	MustChangePassword bool           `gorm:"not null;default:false" json:"must_change_password"` // 登录后必须修改密码，管理员创建用户或重置密码后为true
+	PasswordChangedAt  *time.Time     `json:"password_changed_at"`                                // 密码最近修改时间，用于判断密码是否过期
+	Remark             string         `gorm:"size:255" json:"remark"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
+	Roles              []*Role        `gorm:"many2many:kp_user_role;joinForeignKey:UserID;joinReferences:RoleID" json:"roles,omitempty"`
 }
 
 // TableName 表名
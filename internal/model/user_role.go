@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+)
+
+// UserRole 用户角色关联模型
+type UserRole struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"not null;index:idx_user_role,unique" json:"user_id"`
+	RoleID    uint      `gorm:"not null;index:idx_user_role,unique;index" json:"role_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 表名
+func (UserRole) TableName() string {
+	return "kp_user_role"
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+)
+
+// UserSession 用户登录会话模型，每次登录创建一个会话，会话ID与token族ID一致
+type UserSession struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	SessionID    string     `gorm:"size:64;not null;uniqueIndex" json:"-"` // 会话ID，即token族ID
+	UserID       uint       `gorm:"not null;index" json:"user_id"`         // 用户ID
+	Username     string     `gorm:"size:50;not null" json:"username"`      // 用户名
+	JTI          string     `gorm:"column:jti;size:64;not null" json:"-"`  // 最近签发的refresh token的jti
+	IP           string     `gorm:"column:ip;size:50" json:"ip"`           // 最近访问IP
+	Browser      string     `gorm:"size:50" json:"browser"`                // 浏览器
+	OS           string     `gorm:"column:os;size:50" json:"os"`           // 操作系统
+	Device       string     `gorm:"size:50" json:"device"`                 // 设备类型
+	UserAgent    string     `gorm:"size:500" json:"user_agent"`            // 原始User-Agent
+	LoginAt      time.Time  `gorm:"not null" json:"login_at"`              // 登录时间
+	LastSeenAt   time.Time  `gorm:"not null" json:"last_seen_at"`          // 最近活跃时间
+	ExpiresAt    time.Time  `gorm:"not null;index" json:"expires_at"`      // 过期时间，随refresh token刷新延长
+	RevokedAt    *time.Time `json:"revoked_at"`                            // 撤销时间
+	RevokeReason string     `gorm:"size:255" json:"revoke_reason"`         // 撤销原因
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName 表名
+func (UserSession) TableName() string {
+	return "kp_user_session"
+}
+
+// IsActive 会话是否有效
+func (s *UserSession) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
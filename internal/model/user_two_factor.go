@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+)
+
+// UserTwoFactor 用户双因素认证模型
+type UserTwoFactor struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	UserID    uint       `gorm:"not null;uniqueIndex" json:"user_id"`
+	Secret    string     `gorm:"size:64;not null" json:"-"`    // TOTP密钥（Base32编码）
+	Enabled   bool       `gorm:"default:false" json:"enabled"` // 是否已启用，绑定验证器并校验动态码后启用
+	LastStep  int64      `gorm:"not null;default:0" json:"-"`  // 最近一次校验通过的时间步，防止动态码重复使用
+	EnabledAt *time.Time `json:"enabled_at"`                   // 启用时间
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName 表名
+func (UserTwoFactor) TableName() string {
+	return "kp_user_two_factor"
+}
+
+// UserRecoveryCode 双因素认证恢复码模型，恢复码只能使用一次
+type UserRecoveryCode struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"size:64;not null" json:"-"` // 恢复码哈希
+	UsedAt    *time.Time `json:"used_at"`                   // 使用时间
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName 表名
+func (UserRecoveryCode) TableName() string {
+	return "kp_user_recovery_code"
+}
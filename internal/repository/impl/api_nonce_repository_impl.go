@@ -0,0 +1,44 @@
+package impl
+
+import (
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"gorm.io/gorm/clause"
+)
+
+// APINonceRepositoryImpl 签名请求随机串仓储实现
+type APINonceRepositoryImpl struct {
+	BaseRepository
+}
+
+// NewAPINonceRepository 创建签名请求随机串仓储实例
+func NewAPINonceRepository() repository.APINonceRepository {
+	return &APINonceRepositoryImpl{
+		BaseRepository: NewBaseRepository(),
+	}
+}
+
+// Use 记录AppKey使用的随机串，通过唯一索引保证并发时同一随机串只有一次成功
+func (r *APINonceRepositoryImpl) Use(appKey, nonce string, expiresAt time.Time) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.APINonce{
+		AppKey:    appKey,
+		Nonce:     nonce,
+		ExpiresAt: expiresAt,
+	})
+	if result.Error != nil {
+		return false, kperrors.New(kperrors.ErrDatabase, result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CleanExpired 清理过期的随机串记录
+func (r *APINonceRepositoryImpl) CleanExpired() error {
+	err := r.db.Where("expires_at < ?", time.Now()).Delete(&model.APINonce{}).Error
+	if err != nil {
+		return kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return nil
+}
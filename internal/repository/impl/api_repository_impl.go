@@ -5,6 +5,7 @@ import (
 	"github.com/cuiyuanxin/kunpeng/internal/model"
 	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
 	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"gorm.io/gorm"
 )
 
 // APIRepositoryImpl API仓储实现
@@ -86,31 +87,55 @@ func (r *APIRepositoryImpl) Create(api *model.API) error {
 	return nil
 }
 
-// Update 更新API
+// Update 更新API，并重写关联角色的Casbin策略
 func (r *APIRepositoryImpl) Update(api *model.API) error {
-	err := r.db.Save(api).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(api).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		roleIDs, err := findRoleIDsByAPIIDs(tx, []uint{api.ID})
+		if err != nil {
+			return err
+		}
+		return syncRolePolicies(tx, roleIDs)
+	})
 	if err != nil {
-		return kperrors.New(kperrors.ErrDatabase, err)
+		return wrapTxError(err)
 	}
-	return nil
+
+	return reloadPolicies()
 }
 
 // Delete 删除API
 func (r *APIRepositoryImpl) Delete(id uint) error {
-	err := r.db.Delete(&model.API{}, id).Error
-	if err != nil {
-		return kperrors.New(kperrors.ErrDatabase, err)
-	}
-	return nil
+	return r.BatchDelete([]uint{id})
 }
 
-// BatchDelete 批量删除API
+// BatchDelete 批量删除API，同时删除角色API关联并重写关联角色的Casbin策略
 func (r *APIRepositoryImpl) BatchDelete(ids []uint) error {
-	err := r.db.Delete(&model.API{}, ids).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		roleIDs, err := findRoleIDsByAPIIDs(tx, ids)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&model.API{}, ids).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		// 删除角色API关联
+		if err := tx.Where("api_id IN ?", ids).Delete(&model.RoleAPI{}).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		return syncRolePolicies(tx, roleIDs)
+	})
 	if err != nil {
-		return kperrors.New(kperrors.ErrDatabase, err)
+		return wrapTxError(err)
 	}
-	return nil
+
+	return reloadPolicies()
 }
 
 // FindAPIIDsByRoleID 根据角色ID获取API ID列表
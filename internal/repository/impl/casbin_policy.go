@@ -0,0 +1,119 @@
+package impl
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/pkg/casbin"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// syncRolePolicies 在事务中根据角色API关联重写角色的Casbin策略，并同步角色到上级角色的分组
+// 仅同步启用状态的角色和API，事务提交后需调用 reloadPolicies 刷新内存策略
+func syncRolePolicies(tx *gorm.DB, roleIDs []uint) error {
+	if len(roleIDs) == 0 {
+		return nil
+	}
+
+	var roles []*model.Role
+	if err := tx.Where("id IN ?", roleIDs).Find(&roles).Error; err != nil {
+		return kperrors.New(kperrors.ErrDatabase, err)
+	}
+
+	for _, role := range roles {
+		// 禁用的角色不授予任何权限，也不再继承上级角色的权限
+		if role.Status != 1 {
+			if err := casbin.RemovePoliciesTx(tx, role.Code); err != nil {
+				return err
+			}
+			if err := casbin.RemoveGroupingsTx(tx, role.Code); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := syncRoleParent(tx, role); err != nil {
+			return err
+		}
+
+		var apis []*model.API
+		err := tx.Model(&model.API{}).
+			Joins("JOIN kp_role_api ON kp_role_api.api_id = kp_api.id").
+			Where("kp_role_api.role_id = ? AND kp_api.status = ?", role.ID, 1).
+			Find(&apis).Error
+		if err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		rules := make([][]string, 0, len(apis))
+		for _, api := range apis {
+			rules = append(rules, []string{api.Path, api.Method})
+		}
+
+		if err := casbin.ReplacePoliciesTx(tx, role.Code, rules); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncRoleParent 在事务中重写角色到上级角色的分组，子角色通过该分组继承上级角色的权限
+func syncRoleParent(tx *gorm.DB, role *model.Role) error {
+	if role.ParentID == 0 {
+		return casbin.RemoveGroupingsTx(tx, role.Code)
+	}
+
+	var parentCodes []string
+	if err := tx.Model(&model.Role{}).Where("id = ?", role.ParentID).Pluck("code", &parentCodes).Error; err != nil {
+		return kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return casbin.ReplaceGroupingsTx(tx, role.Code, parentCodes)
+}
+
+// syncUserGroupings 在事务中根据用户角色关联重写用户的Casbin角色分组
+func syncUserGroupings(tx *gorm.DB, userIDs []uint) error {
+	for _, userID := range userIDs {
+		var codes []string
+		err := tx.Model(&model.Role{}).
+			Joins("JOIN kp_user_role ON kp_user_role.role_id = kp_role.id").
+			Where("kp_user_role.user_id = ?", userID).
+			Pluck("kp_role.code", &codes).Error
+		if err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		if err := casbin.ReplaceGroupingsTx(tx, casbin.UserSubject(userID), codes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findRoleIDsByAPIIDs 在事务中查询关联了指定API的角色ID
+func findRoleIDsByAPIIDs(tx *gorm.DB, apiIDs []uint) ([]uint, error) {
+	var roleIDs []uint
+	err := tx.Model(&model.RoleAPI{}).
+		Where("api_id IN ?", apiIDs).
+		Distinct().
+		Pluck("role_id", &roleIDs).Error
+	if err != nil {
+		return nil, kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return roleIDs, nil
+}
+
+// reloadPolicies 事务提交后重新加载Casbin策略
+func reloadPolicies() error {
+	return casbin.LoadPolicy()
+}
+
+// wrapTxError 包装事务错误，已是业务错误的直接返回
+func wrapTxError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := err.(*kperrors.Error); ok {
+		return e
+	}
+	return kperrors.New(kperrors.ErrDatabase, err)
+}
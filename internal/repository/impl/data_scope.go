@@ -0,0 +1,43 @@
+package impl
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"gorm.io/gorm"
+)
+
+// dataScopeFilter 数据权限过滤条件，作为GORM Scope应用于列表查询
+// userColumn 为数据所属用户ID字段，deptColumn 为数据所属部门ID字段
+// deptColumn 为空时通过 userColumn 关联用户表的部门进行过滤
+func dataScopeFilter(scope *dto.DataScope, userColumn, deptColumn string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if scope == nil || scope.All {
+			return db
+		}
+
+		var cond *gorm.DB
+		or := func(query string, args ...interface{}) {
+			if cond == nil {
+				cond = db.Session(&gorm.Session{NewDB: true}).Where(query, args...)
+			} else {
+				cond = cond.Or(query, args...)
+			}
+		}
+
+		if len(scope.DeptIDs) > 0 {
+			if deptColumn != "" {
+				or(deptColumn+" IN ?", scope.DeptIDs)
+			} else {
+				or(userColumn+" IN (SELECT id FROM kp_user WHERE dept_id IN ? AND deleted_at IS NULL)", scope.DeptIDs)
+			}
+		}
+		if scope.Self {
+			or(userColumn+" = ?", scope.UserID)
+		}
+
+		// 没有任何可访问的数据
+		if cond == nil {
+			return db.Where("1 = 0")
+		}
+		return db.Where(cond)
+	}
+}
@@ -113,6 +113,35 @@ func (r *DeptRepositoryImpl) HasUsers(id uint) (bool, error) {
 	return count > 0, nil
 }
 
+// FindChildIDs 获取部门及其所有下级部门ID
+func (r *DeptRepositoryImpl) FindChildIDs(id uint) ([]uint, error) {
+	var depts []*model.Dept
+	err := r.db.Select("id", "parent_id").Find(&depts).Error
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabase, err)
+	}
+
+	children := make(map[uint][]uint, len(depts))
+	for _, dept := range depts {
+		children[dept.ParentID] = append(children[dept.ParentID], dept.ID)
+	}
+
+	// 按层级广度遍历，visited 防止数据异常时出现环
+	ids := []uint{id}
+	visited := map[uint]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, childID := range children[ids[i]] {
+			if visited[childID] {
+				continue
+			}
+			visited[childID] = true
+			ids = append(ids, childID)
+		}
+	}
+
+	return ids, nil
+}
+
 // buildDeptTree 构建部门树
 func buildDeptTree(depts []*model.Dept, parentID uint) []*dto.DeptTreeResp {
 	var tree []*dto.DeptTreeResp
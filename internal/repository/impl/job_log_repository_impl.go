@@ -0,0 +1,101 @@
+package impl
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+)
+
+// JobLogRepositoryImpl 定时任务执行记录仓储实现
+type JobLogRepositoryImpl struct {
+	BaseRepository
+}
+
+// NewJobLogRepository 创建定时任务执行记录仓储实例
+func NewJobLogRepository() repository.JobLogRepository {
+	return &JobLogRepositoryImpl{
+		BaseRepository: NewBaseRepository(),
+	}
+}
+
+// Create 创建执行记录
+func (r *JobLogRepositoryImpl) Create(log *model.JobLog) error {
+	if err := r.db.Create(log).Error; err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
+
+// Update 更新执行结果
+func (r *JobLogRepositoryImpl) Update(log *model.JobLog) error {
+	err := r.db.Model(log).Select("status", "message", "finished_at", "duration").Updates(log).Error
+	if err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
+
+// FindList 查询执行记录列表
+func (r *JobLogRepositoryImpl) FindList(req *dto.JobLogListReq) ([]*model.JobLog, int64, error) {
+	var logs []*model.JobLog
+	var total int64
+
+	db := r.db.Model(&model.JobLog{})
+
+	// 构建查询条件
+	if req.JobName != "" {
+		db = db.Where("job_name = ?", req.JobName)
+	}
+	if req.Status != nil {
+		db = db.Where("status = ?", *req.Status)
+	}
+	if req.Trigger != "" {
+		db = db.Where("`trigger` = ?", req.Trigger)
+	}
+	if req.BeginTime != "" && req.EndTime != "" {
+		db = db.Where("started_at BETWEEN ? AND ?", req.BeginTime, req.EndTime)
+	}
+
+	// 查询总数
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, r.HandleDBError(err)
+	}
+
+	// 分页查询
+	if err := db.Order("started_at DESC, id DESC").
+		Offset((req.PageNum - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&logs).Error; err != nil {
+		return nil, 0, r.HandleDBError(err)
+	}
+
+	return logs, total, nil
+}
+
+// FindLatest 获取任务最近一次执行记录
+func (r *JobLogRepositoryImpl) FindLatest(jobName string) (*model.JobLog, error) {
+	var log model.JobLog
+	err := r.db.Where("job_name = ?", jobName).Order("started_at DESC, id DESC").First(&log).Error
+	if err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return &log, nil
+}
+
+// CleanOldLogs 清理旧执行记录（保留指定天数）
+func (r *JobLogRepositoryImpl) CleanOldLogs(days int) error {
+	if days <= 0 {
+		return kperrors.New(kperrors.ErrParam, errors.New("保留天数必须大于0"))
+	}
+
+	cutoffTime := time.Now().AddDate(0, 0, -days)
+	err := r.db.Where("started_at < ?", cutoffTime).Delete(&model.JobLog{}).Error
+	if err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
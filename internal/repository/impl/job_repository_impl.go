@@ -0,0 +1,50 @@
+package impl
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"gorm.io/gorm/clause"
+)
+
+// JobRepositoryImpl 定时任务仓储实现
+type JobRepositoryImpl struct {
+	BaseRepository
+}
+
+// NewJobRepository 创建定时任务仓储实例
+func NewJobRepository() repository.JobRepository {
+	return &JobRepositoryImpl{
+		BaseRepository: NewBaseRepository(),
+	}
+}
+
+// FindAll 获取全部任务的状态
+func (r *JobRepositoryImpl) FindAll() ([]*model.Job, error) {
+	var jobs []*model.Job
+	if err := r.db.Find(&jobs).Error; err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return jobs, nil
+}
+
+// FindByName 根据名称获取任务
+func (r *JobRepositoryImpl) FindByName(name string) (*model.Job, error) {
+	var job model.Job
+	if err := r.db.Where("name = ?", name).First(&job).Error; err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return &job, nil
+}
+
+// SaveStatus 保存任务状态，任务不存在时创建
+func (r *JobRepositoryImpl) SaveStatus(name string, status int8) error {
+	job := &model.Job{Name: name, Status: status}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "updated_at"}),
+	}).Create(job).Error
+	if err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
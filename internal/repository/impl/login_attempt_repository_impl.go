@@ -5,6 +5,9 @@ import (
 
 	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
 	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // LoginAttemptRepositoryImpl 登录尝试仓储实现
@@ -19,38 +22,115 @@ func NewLoginAttemptRepository() repository.LoginAttemptRepository {
 	}
 }
 
-// FindByAccountAndIP 根据账号和IP获取登录尝试记录
-func (r *LoginAttemptRepositoryImpl) FindByAccountAndIP(account, ip string) (*model.LoginAttempt, error) {
+// FindBySubject 根据计数范围和账号或IP获取登录尝试记录
+func (r *LoginAttemptRepositoryImpl) FindBySubject(scope, subject string) (*model.LoginAttempt, error) {
 	var attempt model.LoginAttempt
-	err := r.db.Where("account = ? AND ip = ?", account, ip).First(&attempt).Error
+	err := r.db.Where("scope = ? AND subject = ?", scope, subject).First(&attempt).Error
 	if err != nil {
 		return nil, r.HandleDBError(err)
 	}
 	return &attempt, nil
 }
 
-// FindByAccount 根据账号获取登录尝试记录
-func (r *LoginAttemptRepositoryImpl) FindByAccount(account string) (*model.LoginAttempt, error) {
+// FindByID 根据ID获取登录尝试记录
+func (r *LoginAttemptRepositoryImpl) FindByID(id uint) (*model.LoginAttempt, error) {
 	var attempt model.LoginAttempt
-	err := r.db.Where("account = ?", account).First(&attempt).Error
+	err := r.db.First(&attempt, id).Error
 	if err != nil {
 		return nil, r.HandleDBError(err)
 	}
 	return &attempt, nil
 }
 
-// Create 创建登录尝试记录
-func (r *LoginAttemptRepositoryImpl) Create(attempt *model.LoginAttempt) error {
-	err := r.db.Create(attempt).Error
+// FindLockedList 查询锁定中的登录尝试记录
+func (r *LoginAttemptRepositoryImpl) FindLockedList(req *dto.LoginLockListReq) ([]*model.LoginAttempt, int64, error) {
+	var attempts []*model.LoginAttempt
+	var total int64
+
+	db := r.db.Model(&model.LoginAttempt{}).Where("locked_until > ?", time.Now())
+
+	// 构建查询条件
+	if req.Scope != "" {
+		db = db.Where("scope = ?", req.Scope)
+	}
+	if req.Subject != "" {
+		db = db.Where("subject LIKE ?", "%"+req.Subject+"%")
+	}
+
+	// 查询总数
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, r.HandleDBError(err)
+	}
+
+	// 分页查询
+	if err := db.Order("blocked_at DESC").
+		Offset((req.PageNum - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&attempts).Error; err != nil {
+		return nil, 0, r.HandleDBError(err)
+	}
+
+	return attempts, total, nil
+}
+
+// IncrFailure 累计一次登录失败，记录不存在时创建，返回累计后的记录
+// 通过一条插入或更新语句完成累计，并发失败时不会丢失次数；锁定期间不再累计，超过resetBefore没有失败时重新计数
+// MySQL按顺序赋值，后面的赋值读取的是前面已更新的值，因此最后更新作为判断条件的最后失败时间
+func (r *LoginAttemptRepositoryImpl) IncrFailure(scope, subject, account, ip string, resetBefore time.Time) (*model.LoginAttempt, error) {
+	now := time.Now()
+	keep := func(column, expired string) clause.Assignment {
+		return clause.Assignment{
+			Column: clause.Column{Name: column},
+			Value:  gorm.Expr("CASE WHEN locked_until > ? THEN "+column+" WHEN last_try < ? THEN "+expired+" ELSE "+column+" END", now, resetBefore),
+		}
+	}
+	set := func(column string, value interface{}) clause.Assignment {
+		return clause.Assignment{
+			Column: clause.Column{Name: column},
+			Value:  gorm.Expr("CASE WHEN locked_until > ? THEN "+column+" ELSE ? END", now, value),
+		}
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "scope"}, {Name: "subject"}},
+		DoUpdates: clause.Set{
+			{
+				Column: clause.Column{Name: "attempts"},
+				Value:  gorm.Expr("CASE WHEN locked_until > ? THEN attempts WHEN last_try < ? THEN 1 ELSE attempts + 1 END", now, resetBefore),
+			},
+			keep("lock_count", "0"),
+			keep("blocked_at", "NULL"),
+			keep("locked_until", "NULL"),
+			set("last_account", account),
+			set("last_ip", ip),
+			set("updated_at", now),
+			set("last_try", now),
+		},
+	}).Create(&model.LoginAttempt{
+		Scope:       scope,
+		Subject:     subject,
+		Attempts:    1,
+		LastTry:     now,
+		LastAccount: account,
+		LastIP:      ip,
+	}).Error
 	if err != nil {
-		return r.HandleDBError(err)
+		return nil, r.HandleDBError(err)
 	}
-	return nil
+
+	return r.FindBySubject(scope, subject)
 }
 
-// Update 更新登录尝试记录
-func (r *LoginAttemptRepositoryImpl) Update(attempt *model.LoginAttempt) error {
-	err := r.db.Save(attempt).Error
+// Block 锁定登录尝试记录，记录已处于锁定中时不重复锁定
+func (r *LoginAttemptRepositoryImpl) Block(id uint, duration time.Duration) error {
+	now := time.Now()
+	err := r.db.Model(&model.LoginAttempt{}).
+		Where("id = ? AND (locked_until IS NULL OR locked_until <= ?)", id, now).
+		Updates(map[string]interface{}{
+			"lock_count":   gorm.Expr("lock_count + 1"),
+			"blocked_at":   now,
+			"locked_until": now.Add(duration),
+		}).Error
 	if err != nil {
 		return r.HandleDBError(err)
 	}
@@ -66,16 +146,22 @@ func (r *LoginAttemptRepositoryImpl) Delete(id uint) error {
 	return nil
 }
 
-// CleanExpired 清理过期的登录尝试记录
+// CleanExpired 清理30天内没有登录失败且未处于锁定中的记录
 func (r *LoginAttemptRepositoryImpl) CleanExpired() error {
-	// 清理30天前的记录
-	expiredTime := time.Now().AddDate(0, 0, -30)
-	return r.db.Where("created_at < ?", expiredTime).Delete(&model.LoginAttempt{}).Error
+	now := time.Now()
+	expiredTime := now.AddDate(0, 0, -30)
+	err := r.db.Where("last_try < ? AND (locked_until IS NULL OR locked_until < ?)", expiredTime, now).
+		Delete(&model.LoginAttempt{}).Error
+	if err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
 }
 
 // ResetByAccount 重置账号的登录尝试记录
 func (r *LoginAttemptRepositoryImpl) ResetByAccount(account string) error {
-	err := r.db.Where("account = ?", account).Delete(&model.LoginAttempt{}).Error
+	err := r.db.Where("scope = ? AND subject = ?", model.LoginAttemptScopeAccount, account).
+		Delete(&model.LoginAttempt{}).Error
 	if err != nil {
 		return r.HandleDBError(err)
 	}
@@ -45,7 +45,7 @@ func (r *LoginLogRepositoryImpl) FindList(req *dto.LoginLogListReq) ([]*model.Lo
 	var logs []*model.LoginLog
 	var total int64
 
-	db := r.db.Model(&model.LoginLog{})
+	db := r.db.Model(&model.LoginLog{}).Scopes(dataScopeFilter(req.DataScope, "user_id", ""))
 
 	// 构建查询条件
 	if req.UserID != nil {
@@ -4,6 +4,7 @@ import (
 	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
 	"github.com/cuiyuanxin/kunpeng/internal/model"
 	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
 	"github.com/cuiyuanxin/kunpeng/pkg/errors"
 )
 
@@ -70,25 +71,34 @@ func (r *MenuRepositoryImpl) FindTree() ([]*dto.MenuTreeResp, error) {
 func (r *MenuRepositoryImpl) FindUserMenuTree(userID uint) ([]*dto.MenuTreeResp, error) {
 	var menus []*model.Menu
 
-	// 查询用户角色
-	var user model.User
-	err := r.db.First(&user, userID).Error
+	// 查询用户的有效角色，包括继承的上级角色
+	roles, err := findEffectiveRoles(r.db, userID)
 	if err != nil {
-		return nil, r.HandleDBError(err)
+		return nil, err
+	}
+
+	roleIDs := make([]uint, 0, len(roles))
+	superAdmin := config.GetCasbinConfig().SuperAdmin
+	isSuperAdmin := false
+	for _, role := range roles {
+		roleIDs = append(roleIDs, role.ID)
+		if superAdmin != "" && role.Code == superAdmin {
+			isSuperAdmin = true
+		}
 	}
 
 	// 如果是超级管理员，返回所有菜单
-	if user.RoleID == 1 {
+	if isSuperAdmin {
 		err = r.db.Where("status = ? AND type IN (0, 1)", 1).Order("sort ASC").Find(&menus).Error
 		if err != nil {
 			return nil, errors.New(errors.ErrDatabase, err)
 		}
 	} else {
-		// 查询角色菜单
+		// 查询所有角色的菜单
 		var menuIDs []uint
 		err = r.db.Model(&model.RoleMenu{}).
-			Select("menu_id").
-			Where("role_id = ?", user.RoleID).
+			Distinct().
+			Where("role_id IN ?", roleIDs).
 			Pluck("menu_id", &menuIDs).Error
 		if err != nil {
 			return nil, errors.New(errors.ErrDatabase, err)
@@ -107,6 +117,40 @@ func (r *MenuRepositoryImpl) FindUserMenuTree(userID uint) ([]*dto.MenuTreeResp,
 	return buildMenuTree(menus, 0), nil
 }
 
+// FindUserPermissions 获取用户有效角色的菜单和按钮权限标识，超级管理员返回全部权限标识
+func (r *MenuRepositoryImpl) FindUserPermissions(userID uint) ([]string, error) {
+	roles, err := findEffectiveRoles(r.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roleIDs := make([]uint, 0, len(roles))
+	superAdmin := config.GetCasbinConfig().SuperAdmin
+	for _, role := range roles {
+		if superAdmin != "" && role.Code == superAdmin {
+			return []string{model.AllPermission}, nil
+		}
+		roleIDs = append(roleIDs, role.ID)
+	}
+
+	permissions := []string{}
+	if len(roleIDs) == 0 {
+		return permissions, nil
+	}
+
+	err = r.db.Model(&model.Menu{}).
+		Joins("JOIN kp_role_menu ON kp_role_menu.menu_id = kp_menu.id").
+		Where("kp_role_menu.role_id IN ? AND kp_menu.status = ? AND kp_menu.permission <> ''", roleIDs, 1).
+		Distinct().
+		Order("kp_menu.permission ASC").
+		Pluck("kp_menu.permission", &permissions).Error
+	if err != nil {
+		return nil, errors.New(errors.ErrDatabase, err)
+	}
+
+	return permissions, nil
+}
+
 // Create 创建菜单
 func (r *MenuRepositoryImpl) Create(menu *model.Menu) error {
 	err := r.db.Create(menu).Error
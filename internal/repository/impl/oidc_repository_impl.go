@@ -0,0 +1,115 @@
+package impl
+
+import (
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+// OIDCStateRepositoryImpl 单点登录授权请求记录仓储实现
+type OIDCStateRepositoryImpl struct {
+	BaseRepository
+}
+
+// NewOIDCStateRepository 创建单点登录授权请求记录仓储实例
+func NewOIDCStateRepository() repository.OIDCStateRepository {
+	return &OIDCStateRepositoryImpl{
+		BaseRepository: NewBaseRepository(),
+	}
+}
+
+// Create 创建授权请求记录
+func (r *OIDCStateRepositoryImpl) Create(state *model.OIDCState) error {
+	if err := r.db.Create(state).Error; err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
+
+// FindByState 根据state获取授权请求记录
+func (r *OIDCStateRepositoryImpl) FindByState(state string) (*model.OIDCState, error) {
+	var record model.OIDCState
+	err := r.db.Where("state = ?", state).First(&record).Error
+	if err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return &record, nil
+}
+
+// MarkUsed 将授权请求记录标记为已使用，通过条件更新保证并发时只有一次成功
+func (r *OIDCStateRepositoryImpl) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&model.OIDCState{}).Where("id = ? AND used_at IS NULL", id).
+		UpdateColumn("used_at", time.Now())
+	if result.Error != nil {
+		return false, r.HandleDBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CleanExpired 清理在指定时间之前过期的授权请求记录
+func (r *OIDCStateRepositoryImpl) CleanExpired(before time.Time) error {
+	if err := r.db.Where("expires_at < ?", before).Delete(&model.OIDCState{}).Error; err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
+
+// UserIdentityRepositoryImpl 用户外部身份仓储实现
+type UserIdentityRepositoryImpl struct {
+	BaseRepository
+}
+
+// NewUserIdentityRepository 创建用户外部身份仓储实例
+func NewUserIdentityRepository() repository.UserIdentityRepository {
+	return &UserIdentityRepositoryImpl{
+		BaseRepository: NewBaseRepository(),
+	}
+}
+
+// FindBySubject 根据身份提供方及用户唯一标识获取外部身份
+func (r *UserIdentityRepositoryImpl) FindBySubject(issuer, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.Where("issuer = ? AND subject = ?", issuer, subject).First(&identity).Error
+	if err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return &identity, nil
+}
+
+// FindByUserID 获取用户在身份提供方关联的外部身份
+func (r *UserIdentityRepositoryImpl) FindByUserID(userID uint, issuer string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.Where("user_id = ? AND issuer = ?", userID, issuer).First(&identity).Error
+	if err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return &identity, nil
+}
+
+// Create 创建外部身份
+func (r *UserIdentityRepositoryImpl) Create(identity *model.UserIdentity) error {
+	if err := r.db.Create(identity).Error; err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
+
+// Delete 删除用户在身份提供方关联的外部身份
+func (r *UserIdentityRepositoryImpl) Delete(userID uint, issuer string) error {
+	err := r.db.Where("user_id = ? AND issuer = ?", userID, issuer).Delete(&model.UserIdentity{}).Error
+	if err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
+
+// UpdateLogin 更新最近登录时间及邮箱
+func (r *UserIdentityRepositoryImpl) UpdateLogin(id uint, email string) error {
+	err := r.db.Model(&model.UserIdentity{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"last_login_at": time.Now(), "email": email}).Error
+	if err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
@@ -49,7 +49,7 @@ func (r *OperationLogRepositoryImpl) FindList(req *dto.OperationLogListReq) ([]*
 	var logs []*model.OperationLog
 	var total int64
 
-	db := r.db.Model(&model.OperationLog{})
+	db := r.db.Model(&model.OperationLog{}).Scopes(dataScopeFilter(req.DataScope, "user_id", ""))
 
 	// 构建查询条件
 	if req.UserID != nil {
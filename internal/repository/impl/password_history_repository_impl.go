@@ -0,0 +1,60 @@
+package impl
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+// PasswordHistoryRepositoryImpl 密码历史仓储实现
+type PasswordHistoryRepositoryImpl struct {
+	BaseRepository
+}
+
+// NewPasswordHistoryRepository 创建密码历史仓储实例
+func NewPasswordHistoryRepository() repository.PasswordHistoryRepository {
+	return &PasswordHistoryRepositoryImpl{
+		BaseRepository: NewBaseRepository(),
+	}
+}
+
+// Create 创建密码历史记录
+func (r *PasswordHistoryRepositoryImpl) Create(history *model.PasswordHistory) error {
+	if err := r.db.Create(history).Error; err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
+
+// FindRecent 获取用户最近的密码历史记录
+func (r *PasswordHistoryRepositoryImpl) FindRecent(userID uint, limit int) ([]*model.PasswordHistory, error) {
+	var histories []*model.PasswordHistory
+	err := r.db.Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&histories).Error
+	if err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return histories, nil
+}
+
+// DeleteExceptRecent 删除用户最近keep条之外的密码历史记录
+func (r *PasswordHistoryRepositoryImpl) DeleteExceptRecent(userID uint, keep int) error {
+	var ids []uint
+	err := r.db.Model(&model.PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Offset(keep).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return r.HandleDBError(err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := r.db.Where("id IN ?", ids).Delete(&model.PasswordHistory{}).Error; err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package impl
+
+import (
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+// PasswordResetRepositoryImpl 找回密码请求记录仓储实现
+type PasswordResetRepositoryImpl struct {
+	BaseRepository
+}
+
+// NewPasswordResetRepository 创建找回密码请求记录仓储实例
+func NewPasswordResetRepository() repository.PasswordResetRepository {
+	return &PasswordResetRepositoryImpl{
+		BaseRepository: NewBaseRepository(),
+	}
+}
+
+// Create 创建找回密码请求记录
+func (r *PasswordResetRepositoryImpl) Create(reset *model.PasswordReset) error {
+	if err := r.db.Create(reset).Error; err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
+
+// FindLatest 获取账号最近一次找回密码请求记录
+func (r *PasswordResetRepositoryImpl) FindLatest(account string) (*model.PasswordReset, error) {
+	var reset model.PasswordReset
+	err := r.db.Where("account = ?", account).Order("id DESC").First(&reset).Error
+	if err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return &reset, nil
+}
+
+// FindByJTI 根据重置凭证的jti获取找回密码请求记录
+func (r *PasswordResetRepositoryImpl) FindByJTI(jti string) (*model.PasswordReset, error) {
+	var reset model.PasswordReset
+	err := r.db.Where("jti = ?", jti).First(&reset).Error
+	if err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return &reset, nil
+}
+
+// FindLatestByUserID 获取用户最近一次已发送邮件的找回密码请求记录
+func (r *PasswordResetRepositoryImpl) FindLatestByUserID(userID uint) (*model.PasswordReset, error) {
+	var reset model.PasswordReset
+	err := r.db.Where("user_id = ?", userID).Order("id DESC").First(&reset).Error
+	if err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return &reset, nil
+}
+
+// CountByUserID 统计用户自指定时间以来已发送邮件的请求次数
+func (r *PasswordResetRepositoryImpl) CountByUserID(userID uint, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.PasswordReset{}).Where("user_id = ? AND created_at >= ?", userID, since).Count(&count).Error
+	if err != nil {
+		return 0, r.HandleDBError(err)
+	}
+	return count, nil
+}
+
+// CountByIP 统计IP自指定时间以来的请求次数
+func (r *PasswordResetRepositoryImpl) CountByIP(ip string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.PasswordReset{}).Where("ip = ? AND created_at >= ?", ip, since).Count(&count).Error
+	if err != nil {
+		return 0, r.HandleDBError(err)
+	}
+	return count, nil
+}
+
+// MarkUsed 将请求记录标记为已使用，通过条件更新保证并发时只有一次成功
+func (r *PasswordResetRepositoryImpl) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&model.PasswordReset{}).Where("id = ? AND used_at IS NULL", id).
+		UpdateColumn("used_at", time.Now())
+	if result.Error != nil {
+		return false, r.HandleDBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CleanExpired 清理在指定时间之前过期的重置密码记录
+func (r *PasswordResetRepositoryImpl) CleanExpired(before time.Time) error {
+	if err := r.db.Where("expires_at < ?", before).Delete(&model.PasswordReset{}).Error; err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package impl
+
+import (
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+// RefreshTokenRepositoryImpl refresh token签发记录仓储实现
+type RefreshTokenRepositoryImpl struct {
+	BaseRepository
+}
+
+// NewRefreshTokenRepository 创建refresh token签发记录仓储实例
+func NewRefreshTokenRepository() repository.RefreshTokenRepository {
+	return &RefreshTokenRepositoryImpl{
+		BaseRepository: NewBaseRepository(),
+	}
+}
+
+// Create 创建refresh token签发记录
+func (r *RefreshTokenRepositoryImpl) Create(token *model.RefreshToken) error {
+	if err := r.db.Create(token).Error; err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
+
+// FindByJTI 根据jti获取refresh token签发记录
+func (r *RefreshTokenRepositoryImpl) FindByJTI(jti string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.Where("jti = ?", jti).First(&token).Error
+	if err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return &token, nil
+}
+
+// MarkUsed 将refresh token标记为已轮换，通过条件更新保证并发时只有一次成功
+func (r *RefreshTokenRepositoryImpl) MarkUsed(jti string) (bool, error) {
+	result := r.db.Model(&model.RefreshToken{}).
+		Where("jti = ? AND used_at IS NULL AND revoked_at IS NULL", jti).
+		UpdateColumn("used_at", time.Now())
+	if result.Error != nil {
+		return false, r.HandleDBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RevokeFamily 撤销token族中的全部refresh token
+func (r *RefreshTokenRepositoryImpl) RevokeFamily(familyID string) error {
+	err := r.db.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		UpdateColumn("revoked_at", time.Now()).Error
+	if err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
+
+// CleanExpired 清理在指定时间之前过期的refresh token签发记录
+func (r *RefreshTokenRepositoryImpl) CleanExpired(before time.Time) error {
+	if err := r.db.Where("expires_at < ?", before).Delete(&model.RefreshToken{}).Error; err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
@@ -4,7 +4,9 @@ import (
 	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
 	"github.com/cuiyuanxin/kunpeng/internal/model"
 	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/pkg/casbin"
 	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"gorm.io/gorm"
 )
 
 // RoleRepositoryImpl 角色仓储实现
@@ -80,55 +82,122 @@ func (r *RoleRepositoryImpl) FindList(req *dto.RolePageReq) ([]*model.Role, int6
 	return roles, total, nil
 }
 
-// Create 创建角色
+// Create 创建角色，并同步角色到上级角色的分组
 func (r *RoleRepositoryImpl) Create(role *model.Role) error {
-	err := r.db.Create(role).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(role).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+		return syncRolePolicies(tx, []uint{role.ID})
+	})
 	if err != nil {
-		return kperrors.New(kperrors.ErrDatabase, err)
+		return wrapTxError(err)
 	}
-	return nil
+
+	return reloadPolicies()
 }
 
-// Update 更新角色
+// Update 更新角色，并同步角色的Casbin策略和角色分组
 func (r *RoleRepositoryImpl) Update(role *model.Role) error {
-	err := r.db.Save(role).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var oldCode string
+		if err := tx.Model(&model.Role{}).Where("id = ?", role.ID).Pluck("code", &oldCode).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		if err := tx.Save(role).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		// 角色编码变更时，删除旧编码下的策略并迁移角色分组
+		if oldCode != role.Code {
+			if err := casbin.RemovePoliciesTx(tx, oldCode); err != nil {
+				return err
+			}
+			if err := casbin.RemoveGroupingsTx(tx, oldCode); err != nil {
+				return err
+			}
+			if err := casbin.RenameRoleGroupingsTx(tx, oldCode, role.Code); err != nil {
+				return err
+			}
+		}
+
+		// 角色状态或上级角色可能变更，重新生成策略
+		return syncRolePolicies(tx, []uint{role.ID})
+	})
 	if err != nil {
-		return kperrors.New(kperrors.ErrDatabase, err)
+		return wrapTxError(err)
 	}
-	return nil
+
+	return reloadPolicies()
 }
 
-// Delete 删除角色
+// Delete 删除角色，同时删除角色的Casbin策略和角色分组
 func (r *RoleRepositoryImpl) Delete(id uint) error {
-	err := r.db.Delete(&model.Role{}, id).Error
-	if err != nil {
-		return kperrors.New(kperrors.ErrDatabase, err)
-	}
-	return nil
+	return r.BatchDelete([]uint{id})
 }
 
-// BatchDelete 批量删除角色
+// BatchDelete 批量删除角色，同时删除角色的Casbin策略和角色分组
 func (r *RoleRepositoryImpl) BatchDelete(ids []uint) error {
-	err := r.db.Delete(&model.Role{}, ids).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var codes []string
+		if err := tx.Model(&model.Role{}).Where("id IN ?", ids).Pluck("code", &codes).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		if err := tx.Delete(&model.Role{}, ids).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		// 删除用户角色关联
+		if err := tx.Where("role_id IN ?", ids).Delete(&model.UserRole{}).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		// 删除角色数据权限部门关联
+		if err := tx.Where("role_id IN ?", ids).Delete(&model.RoleDept{}).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		for _, code := range codes {
+			if err := casbin.RemovePoliciesTx(tx, code); err != nil {
+				return err
+			}
+			if err := casbin.RemoveRoleGroupingsTx(tx, code); err != nil {
+				return err
+			}
+			if err := casbin.RemoveGroupingsTx(tx, code); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return kperrors.New(kperrors.ErrDatabase, err)
+		return wrapTxError(err)
 	}
-	return nil
+
+	return reloadPolicies()
 }
 
-// UpdateStatus 更新角色状态
+// UpdateStatus 更新角色状态，禁用的角色不再授予权限
 func (r *RoleRepositoryImpl) UpdateStatus(id uint, status int) error {
-	err := r.db.Model(&model.Role{}).Where("id = ?", id).Update("status", status).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Role{}).Where("id = ?", id).Update("status", status).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+		return syncRolePolicies(tx, []uint{id})
+	})
 	if err != nil {
-		return kperrors.New(kperrors.ErrDatabase, err)
+		return wrapTxError(err)
 	}
-	return nil
+
+	return reloadPolicies()
 }
 
 // GetRoleMenus 获取角色菜单权限
 func (r *RoleRepositoryImpl) GetRoleMenus(roleID uint) ([]uint, error) {
 	var menuIDs []uint
-	err := r.db.Table("role_menus").Where("role_id = ?", roleID).Pluck("menu_id", &menuIDs).Error
+	err := r.db.Model(&model.RoleMenu{}).Where("role_id = ?", roleID).Pluck("menu_id", &menuIDs).Error
 	if err != nil {
 		return nil, kperrors.New(kperrors.ErrDatabase, err)
 	}
@@ -182,7 +251,7 @@ func (r *RoleRepositoryImpl) UpdateRoleMenus(roleID uint, menuIDs []uint) error
 // GetRoleAPIs 获取角色API权限
 func (r *RoleRepositoryImpl) GetRoleAPIs(roleID uint) ([]uint, error) {
 	var apiIDs []uint
-	err := r.db.Table("role_apis").Where("role_id = ?", roleID).Pluck("api_id", &apiIDs).Error
+	err := r.db.Model(&model.RoleAPI{}).Where("role_id = ?", roleID).Pluck("api_id", &apiIDs).Error
 	if err != nil {
 		return nil, kperrors.New(kperrors.ErrDatabase, err)
 	}
@@ -195,40 +264,225 @@ func (r *RoleRepositoryImpl) GetRoleAPIs(roleID uint) ([]uint, error) {
 	return apiIDs, nil
 }
 
-// UpdateRoleAPIs 更新角色API权限
+// UpdateRoleAPIs 更新角色API权限，并在同一事务中重写角色的Casbin策略
 func (r *RoleRepositoryImpl) UpdateRoleAPIs(roleID uint, apiIDs []uint) error {
-	// 开启事务
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return kperrors.New(kperrors.ErrDatabase, tx.Error)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 删除原有的角色API关联
+		if err := tx.Where("role_id = ?", roleID).Delete(&model.RoleAPI{}).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		// 插入新的角色API关联
+		if len(apiIDs) > 0 {
+			var roleAPIs []model.RoleAPI
+			for _, apiID := range apiIDs {
+				roleAPIs = append(roleAPIs, model.RoleAPI{
+					RoleID: roleID,
+					APIID:  apiID,
+				})
+			}
+
+			if err := tx.Create(&roleAPIs).Error; err != nil {
+				return kperrors.New(kperrors.ErrDatabase, err)
+			}
+		}
+
+		// 同步角色策略
+		return syncRolePolicies(tx, []uint{roleID})
+	})
+	if err != nil {
+		return wrapTxError(err)
 	}
 
-	// 删除原有的角色API关联
-	if err := tx.Where("role_id = ?", roleID).Delete(&model.RoleAPI{}).Error; err != nil {
-		tx.Rollback()
-		return kperrors.New(kperrors.ErrDatabase, err)
+	return reloadPolicies()
+}
+
+// RebuildPolicies 根据角色API关联和用户角色关联重建所有Casbin策略和角色分组
+func (r *RoleRepositoryImpl) RebuildPolicies() error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var roleIDs []uint
+		if err := tx.Model(&model.Role{}).Pluck("id", &roleIDs).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		var userIDs []uint
+		if err := tx.Model(&model.UserRole{}).Distinct().Pluck("user_id", &userIDs).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		// 清空所有策略和分组，避免残留已删除角色、API或用户的数据
+		if err := casbin.RemoveAllPoliciesTx(tx); err != nil {
+			return err
+		}
+		if err := casbin.RemoveAllGroupingsTx(tx); err != nil {
+			return err
+		}
+
+		if err := syncRolePolicies(tx, roleIDs); err != nil {
+			return err
+		}
+		return syncUserGroupings(tx, userIDs)
+	})
+	if err != nil {
+		return wrapTxError(err)
+	}
+
+	return reloadPolicies()
+}
+
+// FindByIDs 根据ID列表获取角色
+func (r *RoleRepositoryImpl) FindByIDs(ids []uint) ([]*model.Role, error) {
+	var roles []*model.Role
+	err := r.db.Where("id IN ?", ids).Find(&roles).Error
+	if err != nil {
+		return nil, kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return roles, nil
+}
+
+// FindByUserID 获取用户的所有角色
+func (r *RoleRepositoryImpl) FindByUserID(userID uint) ([]*model.Role, error) {
+	var roles []*model.Role
+	err := r.db.Joins("JOIN kp_user_role ON kp_user_role.role_id = kp_role.id").
+		Where("kp_user_role.user_id = ?", userID).
+		Order("kp_role.sort ASC").
+		Find(&roles).Error
+	if err != nil {
+		return nil, kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return roles, nil
+}
+
+// FindTree 获取角色树
+func (r *RoleRepositoryImpl) FindTree() ([]*dto.RoleTreeResp, error) {
+	var roles []*model.Role
+	err := r.db.Order("sort ASC").Find(&roles).Error
+	if err != nil {
+		return nil, kperrors.New(kperrors.ErrDatabase, err)
+	}
+
+	// 构建角色树
+	return buildRoleTree(roles, 0), nil
+}
+
+// HasChildren 检查角色是否有子角色
+func (r *RoleRepositoryImpl) HasChildren(id uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Role{}).Where("parent_id = ?", id).Count(&count).Error
+	if err != nil {
+		return false, kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return count > 0, nil
+}
+
+// FindEffectiveByUserID 获取用户的有效角色，包括启用状态的直接角色及其继承的上级角色
+func (r *RoleRepositoryImpl) FindEffectiveByUserID(userID uint) ([]*model.Role, error) {
+	return findEffectiveRoles(r.db, userID)
+}
+
+// GetRoleDepts 获取角色自定义数据权限的部门
+func (r *RoleRepositoryImpl) GetRoleDepts(roleID uint) ([]uint, error) {
+	deptIDs := []uint{}
+	err := r.db.Model(&model.RoleDept{}).Where("role_id = ?", roleID).Pluck("dept_id", &deptIDs).Error
+	if err != nil {
+		return nil, kperrors.New(kperrors.ErrDatabase, err)
 	}
+	return deptIDs, nil
+}
+
+// UpdateDataScope 更新角色数据权限，非自定义数据权限时清空部门关联
+func (r *RoleRepositoryImpl) UpdateDataScope(roleID uint, dataScope int8, deptIDs []uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Role{}).Where("id = ?", roleID).Update("data_scope", dataScope).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
 
-	// 插入新的角色API关联
-	if len(apiIDs) > 0 {
-		var roleAPIs []model.RoleAPI
-		for _, apiID := range apiIDs {
-			roleAPIs = append(roleAPIs, model.RoleAPI{
+		if err := tx.Where("role_id = ?", roleID).Delete(&model.RoleDept{}).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		if dataScope != model.DataScopeCustom || len(deptIDs) == 0 {
+			return nil
+		}
+
+		seen := make(map[uint]struct{}, len(deptIDs))
+		roleDepts := make([]model.RoleDept, 0, len(deptIDs))
+		for _, deptID := range deptIDs {
+			if _, ok := seen[deptID]; ok {
+				continue
+			}
+			seen[deptID] = struct{}{}
+			roleDepts = append(roleDepts, model.RoleDept{
 				RoleID: roleID,
-				APIID:  apiID,
+				DeptID: deptID,
 			})
 		}
 
-		if err := tx.Create(&roleAPIs).Error; err != nil {
-			tx.Rollback()
+		if err := tx.Create(&roleDepts).Error; err != nil {
 			return kperrors.New(kperrors.ErrDatabase, err)
 		}
+		return nil
+	})
+	return wrapTxError(err)
+}
+
+// findEffectiveRoles 获取用户启用状态的角色及其继承的上级角色
+// 禁用的角色不授予权限，也不再向上继承
+func findEffectiveRoles(db *gorm.DB, userID uint) ([]*model.Role, error) {
+	var roleIDs []uint
+	if err := db.Model(&model.UserRole{}).Where("user_id = ?", userID).Pluck("role_id", &roleIDs).Error; err != nil {
+		return nil, kperrors.New(kperrors.ErrDatabase, err)
+	}
+	if len(roleIDs) == 0 {
+		return []*model.Role{}, nil
 	}
 
-	// 提交事务
-	if err := tx.Commit().Error; err != nil {
-		return kperrors.New(kperrors.ErrDatabase, err)
+	var all []*model.Role
+	if err := db.Order("sort ASC").Find(&all).Error; err != nil {
+		return nil, kperrors.New(kperrors.ErrDatabase, err)
+	}
+	roleMap := make(map[uint]*model.Role, len(all))
+	for _, role := range all {
+		roleMap[role.ID] = role
 	}
 
-	return nil
+	visited := make(map[uint]struct{})
+	roles := make([]*model.Role, 0, len(roleIDs))
+	for _, roleID := range roleIDs {
+		// 沿上级角色向上查找，遇到禁用、不存在或已访问的角色时停止
+		for id := roleID; id != 0; {
+			role, ok := roleMap[id]
+			if !ok || role.Status != 1 {
+				break
+			}
+			if _, ok := visited[id]; ok {
+				break
+			}
+			visited[id] = struct{}{}
+			roles = append(roles, role)
+			id = role.ParentID
+		}
+	}
+
+	return roles, nil
+}
+
+// buildRoleTree 构建角色树
+func buildRoleTree(roles []*model.Role, parentID uint) []*dto.RoleTreeResp {
+	var tree []*dto.RoleTreeResp
+	for _, role := range roles {
+		if role.ParentID == parentID {
+			node := &dto.RoleTreeResp{
+				ID:       role.ID,
+				ParentID: role.ParentID,
+				Name:     role.Name,
+				Code:     role.Code,
+				Sort:     role.Sort,
+				Status:   role.Status,
+				Children: buildRoleTree(roles, role.ID),
+			}
+			tree = append(tree, node)
+		}
+	}
+	return tree
 }
@@ -0,0 +1,87 @@
+package impl
+
+import (
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"gorm.io/gorm"
+)
+
+// SMSCodeRepositoryImpl 短信验证码仓储实现
+type SMSCodeRepositoryImpl struct {
+	BaseRepository
+}
+
+// NewSMSCodeRepository 创建短信验证码仓储实例
+func NewSMSCodeRepository() repository.SMSCodeRepository {
+	return &SMSCodeRepositoryImpl{
+		BaseRepository: NewBaseRepository(),
+	}
+}
+
+// Create 创建短信验证码记录
+func (r *SMSCodeRepositoryImpl) Create(code *model.SMSCode) error {
+	if err := r.db.Create(code).Error; err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
+
+// FindLatest 获取手机号在指定场景下最近一次发送的验证码
+func (r *SMSCodeRepositoryImpl) FindLatest(mobile, scene string) (*model.SMSCode, error) {
+	var code model.SMSCode
+	err := r.db.Where("mobile = ? AND scene = ?", mobile, scene).Order("id DESC").First(&code).Error
+	if err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return &code, nil
+}
+
+// CountByMobile 统计手机号自指定时间以来的发送次数
+func (r *SMSCodeRepositoryImpl) CountByMobile(mobile string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.SMSCode{}).Where("mobile = ? AND created_at >= ?", mobile, since).Count(&count).Error
+	if err != nil {
+		return 0, r.HandleDBError(err)
+	}
+	return count, nil
+}
+
+// CountByIP 统计IP自指定时间以来的发送次数
+func (r *SMSCodeRepositoryImpl) CountByIP(ip string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.SMSCode{}).Where("ip = ? AND created_at >= ?", ip, since).Count(&count).Error
+	if err != nil {
+		return 0, r.HandleDBError(err)
+	}
+	return count, nil
+}
+
+// UseAttempt 占用一次校验次数，通过条件更新保证并发校验时次数不超过上限，次数已用完时返回false
+func (r *SMSCodeRepositoryImpl) UseAttempt(id uint, maxAttempts int) (bool, error) {
+	result := r.db.Model(&model.SMSCode{}).Where("id = ? AND attempts < ?", id, maxAttempts).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return false, r.HandleDBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// MarkUsed 将验证码标记为已使用，通过条件更新保证并发时只有一次成功
+func (r *SMSCodeRepositoryImpl) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&model.SMSCode{}).Where("id = ? AND used_at IS NULL", id).
+		UpdateColumn("used_at", time.Now())
+	if result.Error != nil {
+		return false, r.HandleDBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CleanExpired 清理在指定时间之前过期的短信验证码记录
+func (r *SMSCodeRepositoryImpl) CleanExpired(before time.Time) error {
+	if err := r.db.Where("expires_at < ?", before).Delete(&model.SMSCode{}).Error; err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
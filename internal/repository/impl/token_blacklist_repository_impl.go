@@ -6,6 +6,7 @@ import (
 	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
 	"github.com/cuiyuanxin/kunpeng/internal/model"
 	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"gorm.io/gorm/clause"
 )
 
 // TokenBlacklistRepositoryImpl token黑名单仓储实现
@@ -20,29 +21,48 @@ func NewTokenBlacklistRepository() repository.TokenBlacklistRepository {
 	}
 }
 
-// Create 添加token到黑名单
+// Create 添加token到黑名单，撤销key已存在时更新原记录
 func (r *TokenBlacklistRepositoryImpl) Create(blacklist *model.TokenBlacklist) error {
-	err := r.db.Create(blacklist).Error
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "expires_at", "updated_at", "deleted_at"}),
+	}).Create(blacklist).Error
 	if err != nil {
 		return kperrors.New(kperrors.ErrDatabase, err)
 	}
 	return nil
 }
 
-// IsTokenBlacklisted 检查token是否在黑名单中
-func (r *TokenBlacklistRepositoryImpl) IsTokenBlacklisted(token string) (bool, error) {
+// ExistsKey 检查撤销key是否存在未过期的黑名单记录
+func (r *TokenBlacklistRepositoryImpl) ExistsKey(key string) (bool, error) {
 	var count int64
-	err := r.db.Model(&model.TokenBlacklist{}).Where("token = ?", token).Count(&count).Error
+	err := r.db.Model(&model.TokenBlacklist{}).
+		Where("token_key = ? AND expires_at > ?", key, time.Now()).
+		Count(&count).Error
 	if err != nil {
 		return false, kperrors.New(kperrors.ErrDatabase, err)
 	}
 	return count > 0, nil
 }
 
-// FindByToken 根据token查找黑名单记录
-func (r *TokenBlacklistRepositoryImpl) FindByToken(token string) (*model.TokenBlacklist, error) {
+// FindActiveKeys 查询since之后新增或更新且未过期的撤销key
+func (r *TokenBlacklistRepositoryImpl) FindActiveKeys(since time.Time) ([]string, error) {
+	var keys []string
+	query := r.db.Model(&model.TokenBlacklist{}).Where("expires_at > ?", time.Now())
+	if !since.IsZero() {
+		query = query.Where("updated_at >= ?", since)
+	}
+	err := query.Pluck("token_key", &keys).Error
+	if err != nil {
+		return nil, kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return keys, nil
+}
+
+// FindByID 根据ID查找黑名单记录
+func (r *TokenBlacklistRepositoryImpl) FindByID(id uint) (*model.TokenBlacklist, error) {
 	var blacklist model.TokenBlacklist
-	err := r.db.Where("token = ?", token).First(&blacklist).Error
+	err := r.db.First(&blacklist, id).Error
 	if err != nil {
 		return nil, r.HandleDBError(err)
 	}
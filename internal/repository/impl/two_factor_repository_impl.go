@@ -0,0 +1,119 @@
+package impl
+
+import (
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// TwoFactorRepositoryImpl 双因素认证仓储实现
+type TwoFactorRepositoryImpl struct {
+	BaseRepository
+}
+
+// NewTwoFactorRepository 创建双因素认证仓储实例
+func NewTwoFactorRepository() repository.TwoFactorRepository {
+	return &TwoFactorRepositoryImpl{
+		BaseRepository: NewBaseRepository(),
+	}
+}
+
+// FindByUserID 根据用户ID获取双因素认证信息
+func (r *TwoFactorRepositoryImpl) FindByUserID(userID uint) (*model.UserTwoFactor, error) {
+	var twoFactor model.UserTwoFactor
+	err := r.db.Where("user_id = ?", userID).First(&twoFactor).Error
+	if err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return &twoFactor, nil
+}
+
+// Save 保存双因素认证信息
+func (r *TwoFactorRepositoryImpl) Save(twoFactor *model.UserTwoFactor) error {
+	if err := r.db.Save(twoFactor).Error; err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
+
+// ConsumeStep 记录校验通过的时间步，通过条件更新保证同一动态码只能使用一次
+func (r *TwoFactorRepositoryImpl) ConsumeStep(userID uint, step int64) (bool, error) {
+	result := r.db.Model(&model.UserTwoFactor{}).
+		Where("user_id = ? AND last_step < ?", userID, step).
+		UpdateColumn("last_step", step)
+	if result.Error != nil {
+		return false, r.HandleDBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteByUserID 删除用户的双因素认证信息及恢复码
+func (r *TwoFactorRepositoryImpl) DeleteByUserID(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserTwoFactor{}).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserRecoveryCode{}).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+		return nil
+	})
+}
+
+// ReplaceRecoveryCodes 替换用户的恢复码，原有恢复码全部失效
+func (r *TwoFactorRepositoryImpl) ReplaceRecoveryCodes(userID uint, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserRecoveryCode{}).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		if len(codeHashes) == 0 {
+			return nil
+		}
+		codes := make([]model.UserRecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, model.UserRecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		if err := tx.Create(&codes).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+		return nil
+	})
+}
+
+// UseRecoveryCode 使用恢复码，通过条件更新保证并发时只有一次成功
+func (r *TwoFactorRepositoryImpl) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	result := r.db.Model(&model.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		UpdateColumn("used_at", time.Now())
+	if result.Error != nil {
+		return false, r.HandleDBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountRecoveryCodes 统计用户未使用的恢复码数量
+func (r *TwoFactorRepositoryImpl) CountRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.UserRecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	if err != nil {
+		return 0, r.HandleDBError(err)
+	}
+	return count, nil
+}
+
+// IsRequiredByRole 检查用户是否拥有要求双因素认证的启用状态的角色
+func (r *TwoFactorRepositoryImpl) IsRequiredByRole(userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Role{}).
+		Joins("JOIN kp_user_role ON kp_user_role.role_id = kp_role.id").
+		Where("kp_user_role.user_id = ? AND kp_role.require_two_factor = ? AND kp_role.status = ?", userID, true, 1).
+		Count(&count).Error
+	if err != nil {
+		return false, r.HandleDBError(err)
+	}
+	return count > 0, nil
+}
@@ -1,10 +1,14 @@
 package impl
 
 import (
+	"time"
+
 	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
 	"github.com/cuiyuanxin/kunpeng/internal/model"
 	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/pkg/casbin"
 	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"gorm.io/gorm"
 )
 
 // UserRepositoryImpl 用户仓储实现
@@ -49,12 +53,32 @@ func (r *UserRepositoryImpl) FindByMobile(mobile string) (*model.User, error) {
 	return &user, nil
 }
 
+// FindByEmail 根据邮箱获取用户
+func (r *UserRepositoryImpl) FindByEmail(email string) (*model.User, error) {
+	var user model.User
+	err := r.db.Where("email = ?", email).First(&user).Error
+	if err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return &user, nil
+}
+
+// FindByAppKey 根据AppKey获取用户
+func (r *UserRepositoryImpl) FindByAppKey(appKey string) (*model.User, error) {
+	var user model.User
+	err := r.db.Where("app_key = ?", appKey).First(&user).Error
+	if err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return &user, nil
+}
+
 // FindList 获取用户列表
 func (r *UserRepositoryImpl) FindList(req *dto.UserPageReq) ([]*model.User, int64, error) {
 	var users []*model.User
 	var total int64
 
-	db := r.db.Model(&model.User{})
+	db := r.db.Model(&model.User{}).Scopes(dataScopeFilter(req.DataScope, "id", "dept_id"))
 
 	// 构建查询条件
 	if req.Username != "" {
@@ -83,7 +107,7 @@ func (r *UserRepositoryImpl) FindList(req *dto.UserPageReq) ([]*model.User, int6
 	}
 
 	// 分页查询
-	err = db.Preload("Role").Preload("Dept").Preload("Post").
+	err = db.Preload("Roles").Preload("Dept").Preload("Post").
 		Offset((req.PageNum - 1) * req.PageSize).
 		Limit(req.PageSize).
 		Find(&users).Error
@@ -103,31 +127,96 @@ func (r *UserRepositoryImpl) Create(user *model.User) error {
 	return nil
 }
 
-// Update 更新用户
-func (r *UserRepositoryImpl) Update(user *model.User) error {
-	err := r.db.Save(user).Error
+// Update 更新用户的指定字段
+// 只写入调用方修改的字段，避免使用先前读取的旧数据覆盖密码、AppSecret、token版本号等字段
+func (r *UserRepositoryImpl) Update(user *model.User, columns ...string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	err := r.db.Model(user).Select(columns).Updates(user).Error
 	if err != nil {
 		return kperrors.New(kperrors.ErrDatabase, err)
 	}
 	return nil
 }
 
-// Delete 删除用户
-func (r *UserRepositoryImpl) Delete(id uint) error {
-	err := r.db.Delete(&model.User{}, id).Error
+// UpdateLoginInfo 更新用户的最近登录时间及IP
+func (r *UserRepositoryImpl) UpdateLoginInfo(id uint, loginIP string, loginTime time.Time) error {
+	err := r.db.Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"login_time": loginTime,
+		"login_ip":   loginIP,
+	}).Error
 	if err != nil {
 		return kperrors.New(kperrors.ErrDatabase, err)
 	}
 	return nil
 }
 
-// BatchDelete 批量删除用户
+// CreateWithRoles 创建用户并关联角色
+func (r *UserRepositoryImpl) CreateWithRoles(user *model.User, roleIDs []uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Roles").Create(user).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+		return replaceUserRoles(tx, user.ID, roleIDs)
+	})
+	if err != nil {
+		return wrapTxError(err)
+	}
+
+	return reloadPolicies()
+}
+
+// UpdateWithRoles 更新用户的指定字段并重新关联角色
+func (r *UserRepositoryImpl) UpdateWithRoles(user *model.User, roleIDs []uint, columns ...string) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if len(columns) > 0 {
+			if err := tx.Model(user).Select(columns).Updates(user).Error; err != nil {
+				return kperrors.New(kperrors.ErrDatabase, err)
+			}
+		}
+		return replaceUserRoles(tx, user.ID, roleIDs)
+	})
+	if err != nil {
+		return wrapTxError(err)
+	}
+
+	return reloadPolicies()
+}
+
+// Delete 删除用户
+func (r *UserRepositoryImpl) Delete(id uint) error {
+	return r.BatchDelete([]uint{id})
+}
+
+// BatchDelete 批量删除用户，同时删除用户角色关联、外部身份关联和角色分组
 func (r *UserRepositoryImpl) BatchDelete(ids []uint) error {
-	err := r.db.Delete(&model.User{}, ids).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.User{}, ids).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		if err := tx.Where("user_id IN ?", ids).Delete(&model.UserRole{}).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		// 删除外部身份关联，身份提供方的同一用户可以重新关联或创建账号
+		if err := tx.Where("user_id IN ?", ids).Delete(&model.UserIdentity{}).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+
+		for _, id := range ids {
+			if err := casbin.RemoveGroupingsTx(tx, casbin.UserSubject(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return kperrors.New(kperrors.ErrDatabase, err)
+		return wrapTxError(err)
 	}
-	return nil
+
+	return reloadPolicies()
 }
 
 // UpdateStatus 更新用户状态
@@ -139,28 +228,87 @@ func (r *UserRepositoryImpl) UpdateStatus(id uint, status int) error {
 	return nil
 }
 
-// ResetPassword 重置用户密码
+// ResetPassword 重置用户密码，用户下次登录时必须修改密码
 func (r *UserRepositoryImpl) ResetPassword(id uint, password string) error {
-	err := r.db.Model(&model.User{}).Where("id = ?", id).Update("password", password).Error
+	err := r.db.Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"password":             password,
+		"must_change_password": true,
+		"password_changed_at":  time.Now(),
+	}).Error
 	if err != nil {
 		return kperrors.New(kperrors.ErrDatabase, err)
 	}
 	return nil
 }
 
-// UpdatePassword 更新用户密码
+// UpdatePassword 更新用户密码，同时清除必须修改密码标记
 func (r *UserRepositoryImpl) UpdatePassword(id uint, password string) error {
-	err := r.db.Model(&model.User{}).Where("id = ?", id).Update("password", password).Error
+	err := r.db.Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"password":             password,
+		"must_change_password": false,
+		"password_changed_at":  time.Now(),
+	}).Error
+	if err != nil {
+		return kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return nil
+}
+
+// UpdateAppSecret 更新用户的AppSecret，原AppSecret在prevUntil之前仍然有效
+func (r *UserRepositoryImpl) UpdateAppSecret(id uint, appSecret, prevAppSecret string, prevUntil *time.Time) error {
+	err := r.db.Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"app_secret":            appSecret,
+		"prev_app_secret":       prevAppSecret,
+		"prev_app_secret_until": prevUntil,
+		"app_secret_rotated_at": time.Now(),
+	}).Error
+	if err != nil {
+		return kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return nil
+}
+
+// ResetAppSecret 重置用户的AppSecret并清除轮换时间，原AppSecret立即失效
+func (r *UserRepositoryImpl) ResetAppSecret(id uint, appSecret string) error {
+	err := r.db.Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"app_secret":            appSecret,
+		"prev_app_secret":       "",
+		"prev_app_secret_until": nil,
+		"app_secret_rotated_at": nil,
+	}).Error
 	if err != nil {
 		return kperrors.New(kperrors.ErrDatabase, err)
 	}
 	return nil
 }
 
+// FindTokenVersion 获取用户的token版本号
+func (r *UserRepositoryImpl) FindTokenVersion(id uint) (uint, error) {
+	var user model.User
+	err := r.db.Select("id", "token_version").First(&user, id).Error
+	if err != nil {
+		return 0, r.HandleDBError(err)
+	}
+	return user.TokenVersion, nil
+}
+
+// IncrTokenVersion 递增用户的token版本号
+func (r *UserRepositoryImpl) IncrTokenVersion(id uint) (uint, error) {
+	err := r.db.Model(&model.User{}).Where("id = ?", id).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
+	if err != nil {
+		return 0, kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return r.FindTokenVersion(id)
+}
+
 // FindByRoleID 根据角色ID查找用户
 func (r *UserRepositoryImpl) FindByRoleID(roleID uint) error {
 	var count int64
-	err := r.db.Model(&model.User{}).Where("role_id = ?", roleID).Count(&count).Error
+	err := r.db.Model(&model.User{}).
+		Joins("JOIN kp_user_role ON kp_user_role.user_id = kp_user.id").
+		Where("kp_user_role.role_id = ?", roleID).
+		Count(&count).Error
 	if err != nil {
 		return kperrors.New(kperrors.ErrDatabase, err)
 	}
@@ -171,3 +319,41 @@ func (r *UserRepositoryImpl) FindByRoleID(roleID uint) error {
 
 	return kperrors.New(kperrors.ErrUserNotFound, nil) // 没找到用户，返回错误
 }
+
+// FindRoleIDs 获取用户的角色ID列表
+func (r *UserRepositoryImpl) FindRoleIDs(userID uint) ([]uint, error) {
+	var roleIDs []uint
+	err := r.db.Model(&model.UserRole{}).Where("user_id = ?", userID).Pluck("role_id", &roleIDs).Error
+	if err != nil {
+		return nil, kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return roleIDs, nil
+}
+
+// replaceUserRoles 在事务中重写用户角色关联，并同步用户的Casbin角色分组
+func replaceUserRoles(tx *gorm.DB, userID uint, roleIDs []uint) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.UserRole{}).Error; err != nil {
+		return kperrors.New(kperrors.ErrDatabase, err)
+	}
+
+	if len(roleIDs) > 0 {
+		seen := make(map[uint]struct{}, len(roleIDs))
+		userRoles := make([]model.UserRole, 0, len(roleIDs))
+		for _, roleID := range roleIDs {
+			if _, ok := seen[roleID]; ok {
+				continue
+			}
+			seen[roleID] = struct{}{}
+			userRoles = append(userRoles, model.UserRole{
+				UserID: userID,
+				RoleID: roleID,
+			})
+		}
+
+		if err := tx.Create(&userRoles).Error; err != nil {
+			return kperrors.New(kperrors.ErrDatabase, err)
+		}
+	}
+
+	return syncUserGroupings(tx, []uint{userID})
+}
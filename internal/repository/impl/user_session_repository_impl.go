@@ -0,0 +1,124 @@
+package impl
+
+import (
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+// UserSessionRepositoryImpl 用户登录会话仓储实现
+type UserSessionRepositoryImpl struct {
+	BaseRepository
+}
+
+// NewUserSessionRepository 创建用户登录会话仓储实例
+func NewUserSessionRepository() repository.UserSessionRepository {
+	return &UserSessionRepositoryImpl{
+		BaseRepository: NewBaseRepository(),
+	}
+}
+
+// Create 创建会话
+func (r *UserSessionRepositoryImpl) Create(session *model.UserSession) error {
+	if err := r.db.Create(session).Error; err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
+
+// FindByID 根据ID获取会话
+func (r *UserSessionRepositoryImpl) FindByID(id uint) (*model.UserSession, error) {
+	var session model.UserSession
+	if err := r.db.First(&session, id).Error; err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return &session, nil
+}
+
+// FindBySessionID 根据会话ID获取会话
+func (r *UserSessionRepositoryImpl) FindBySessionID(sessionID string) (*model.UserSession, error) {
+	var session model.UserSession
+	if err := r.db.Where("session_id = ?", sessionID).First(&session).Error; err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return &session, nil
+}
+
+// FindActiveByUserID 获取用户未撤销且未过期的会话
+func (r *UserSessionRepositoryImpl) FindActiveByUserID(userID uint) ([]*model.UserSession, error) {
+	var sessions []*model.UserSession
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, r.HandleDBError(err)
+	}
+	return sessions, nil
+}
+
+// UpdateRefresh 刷新token后更新会话
+func (r *UserSessionRepositoryImpl) UpdateRefresh(sessionID string, jti string, ip string, expiresAt time.Time) error {
+	err := r.db.Model(&model.UserSession{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Updates(map[string]interface{}{
+			"jti":          jti,
+			"ip":           ip,
+			"last_seen_at": time.Now(),
+			"expires_at":   expiresAt,
+		}).Error
+	if err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
+
+// Touch 更新会话的最近活跃时间及访问IP
+func (r *UserSessionRepositoryImpl) Touch(sessionID string, ip string, lastSeenAt time.Time) error {
+	err := r.db.Model(&model.UserSession{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		UpdateColumns(map[string]interface{}{
+			"ip":           ip,
+			"last_seen_at": lastSeenAt,
+		}).Error
+	if err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
+
+// Revoke 撤销会话，通过条件更新保证并发时只有一次成功
+func (r *UserSessionRepositoryImpl) Revoke(sessionID string, reason string) (bool, error) {
+	result := r.db.Model(&model.UserSession{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Updates(map[string]interface{}{
+			"revoked_at":    time.Now(),
+			"revoke_reason": reason,
+		})
+	if result.Error != nil {
+		return false, r.HandleDBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RevokeByUserID 撤销用户的全部会话
+func (r *UserSessionRepositoryImpl) RevokeByUserID(userID uint, reason string) (int64, error) {
+	result := r.db.Model(&model.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"revoked_at":    time.Now(),
+			"revoke_reason": reason,
+		})
+	if result.Error != nil {
+		return 0, r.HandleDBError(result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// CleanExpired 清理在指定时间之前过期的登录会话
+func (r *UserSessionRepositoryImpl) CleanExpired(before time.Time) error {
+	if err := r.db.Where("expires_at < ?", before).Delete(&model.UserSession{}).Error; err != nil {
+		return r.HandleDBError(err)
+	}
+	return nil
+}
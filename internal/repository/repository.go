@@ -8,18 +8,29 @@ import (
 )
 
 var (
-	userRepository           repository.UserRepository
-	roleRepository           repository.RoleRepository
-	menuRepository           repository.MenuRepository
-	apiRepository            repository.APIRepository
-	deptRepository           repository.DeptRepository
-	postRepository           repository.PostRepository
-	dictRepository           repository.DictRepository
-	loginLogRepository       repository.LoginLogRepository
-	operationLogRepository   repository.OperationLogRepository
-	loginAttemptRepository   repository.LoginAttemptRepository
-	tokenBlacklistRepository repository.TokenBlacklistRepository
-	once                     sync.Once
+	userRepository            repository.UserRepository
+	roleRepository            repository.RoleRepository
+	menuRepository            repository.MenuRepository
+	apiRepository             repository.APIRepository
+	deptRepository            repository.DeptRepository
+	postRepository            repository.PostRepository
+	dictRepository            repository.DictRepository
+	loginLogRepository        repository.LoginLogRepository
+	operationLogRepository    repository.OperationLogRepository
+	loginAttemptRepository    repository.LoginAttemptRepository
+	tokenBlacklistRepository  repository.TokenBlacklistRepository
+	smsCodeRepository         repository.SMSCodeRepository
+	twoFactorRepository       repository.TwoFactorRepository
+	refreshTokenRepository    repository.RefreshTokenRepository
+	userSessionRepository     repository.UserSessionRepository
+	jobRepository             repository.JobRepository
+	jobLogRepository          repository.JobLogRepository
+	passwordHistoryRepository repository.PasswordHistoryRepository
+	passwordResetRepository   repository.PasswordResetRepository
+	oidcStateRepository       repository.OIDCStateRepository
+	userIdentityRepository    repository.UserIdentityRepository
+	apiNonceRepository        repository.APINonceRepository
+	once                      sync.Once
 )
 
 // Init 初始化所有仓储
@@ -41,6 +52,28 @@ func Init() {
 		loginAttemptRepository = impl.NewLoginAttemptRepository()
 		// 初始化token黑名单仓储
 		tokenBlacklistRepository = impl.NewTokenBlacklistRepository()
+		// 初始化短信验证码仓储
+		smsCodeRepository = impl.NewSMSCodeRepository()
+		// 初始化双因素认证仓储
+		twoFactorRepository = impl.NewTwoFactorRepository()
+		// 初始化refresh token签发记录仓储
+		refreshTokenRepository = impl.NewRefreshTokenRepository()
+		// 初始化用户登录会话仓储
+		userSessionRepository = impl.NewUserSessionRepository()
+		// 初始化定时任务仓储
+		jobRepository = impl.NewJobRepository()
+		// 初始化定时任务执行记录仓储
+		jobLogRepository = impl.NewJobLogRepository()
+		// 初始化密码历史仓储
+		passwordHistoryRepository = impl.NewPasswordHistoryRepository()
+		// 初始化找回密码请求记录仓储
+		passwordResetRepository = impl.NewPasswordResetRepository()
+		// 初始化单点登录授权请求记录仓储
+		oidcStateRepository = impl.NewOIDCStateRepository()
+		// 初始化用户外部身份仓储
+		userIdentityRepository = impl.NewUserIdentityRepository()
+		// 初始化签名请求随机串仓储
+		apiNonceRepository = impl.NewAPINonceRepository()
 	})
 }
 
@@ -98,3 +131,58 @@ func GetLoginAttemptRepository() repository.LoginAttemptRepository {
 func GetTokenBlacklistRepository() repository.TokenBlacklistRepository {
 	return tokenBlacklistRepository
 }
+
+// GetSMSCodeRepository 获取短信验证码仓储
+func GetSMSCodeRepository() repository.SMSCodeRepository {
+	return smsCodeRepository
+}
+
+// GetTwoFactorRepository 获取双因素认证仓储
+func GetTwoFactorRepository() repository.TwoFactorRepository {
+	return twoFactorRepository
+}
+
+// GetRefreshTokenRepository 获取refresh token签发记录仓储
+func GetRefreshTokenRepository() repository.RefreshTokenRepository {
+	return refreshTokenRepository
+}
+
+// GetUserSessionRepository 获取用户登录会话仓储
+func GetUserSessionRepository() repository.UserSessionRepository {
+	return userSessionRepository
+}
+
+// GetJobRepository 获取定时任务仓储
+func GetJobRepository() repository.JobRepository {
+	return jobRepository
+}
+
+// GetJobLogRepository 获取定时任务执行记录仓储
+func GetJobLogRepository() repository.JobLogRepository {
+	return jobLogRepository
+}
+
+// GetPasswordHistoryRepository 获取密码历史仓储
+func GetPasswordHistoryRepository() repository.PasswordHistoryRepository {
+	return passwordHistoryRepository
+}
+
+// GetPasswordResetRepository 获取找回密码请求记录仓储
+func GetPasswordResetRepository() repository.PasswordResetRepository {
+	return passwordResetRepository
+}
+
+// GetOIDCStateRepository 获取单点登录授权请求记录仓储
+func GetOIDCStateRepository() repository.OIDCStateRepository {
+	return oidcStateRepository
+}
+
+// GetUserIdentityRepository 获取用户外部身份仓储
+func GetUserIdentityRepository() repository.UserIdentityRepository {
+	return userIdentityRepository
+}
+
+// GetAPINonceRepository 获取签名请求随机串仓储
+func GetAPINonceRepository() repository.APINonceRepository {
+	return apiNonceRepository
+}
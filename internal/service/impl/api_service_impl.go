@@ -2,16 +2,21 @@ package impl
 
 import (
 	"errors"
+	"sync"
 
 	"github.com/cuiyuanxin/kunpeng/internal/model"
 	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/repository"
 	"github.com/cuiyuanxin/kunpeng/pkg/database"
 	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
 	"gorm.io/gorm"
 )
 
 // APIServiceImpl API服务实现
-type APIServiceImpl struct{}
+type APIServiceImpl struct {
+	mu     sync.RWMutex
+	routes []*dto.RouteInfo // 已注册的路由
+}
 
 // GetAPIList 获取API列表
 func (s *APIServiceImpl) GetAPIList(req *dto.APIPageReq) (*dto.PageResp, error) {
@@ -121,7 +126,7 @@ func (s *APIServiceImpl) CreateAPI(req *dto.APICreateReq) (uint, error) {
 }
 
 // UpdateAPI 更新API
-func (s *APIServiceImpl) UpdateAPI(req *dto.APIUpdateReq) error {
+func (s *APIServiceImpl) UpdateAPI(operatorID uint, req *dto.APIUpdateReq) error {
 	// 检查API是否存在
 	var api model.API
 	err := database.GetDB().First(&api, req.ID).Error
@@ -144,26 +149,27 @@ func (s *APIServiceImpl) UpdateAPI(req *dto.APIUpdateReq) error {
 		}
 	}
 
-	// 更新API
-	updates := map[string]interface{}{
-		"group":  req.Group,
-		"name":   req.Name,
-		"method": req.Method,
-		"path":   req.Path,
-		"status": req.Status,
-		"remark": req.Remark,
-	}
+	// 更新API，同步关联角色的权限策略
+	api.Group = req.Group
+	api.Name = req.Name
+	api.Method = req.Method
+	api.Path = req.Path
+	api.Status = req.Status
+	api.Remark = req.Remark
 
-	err = database.GetDB().Model(&api).Updates(updates).Error
+	// 检查是否会导致操作者失去权限管理能力
+	err = checkSelfLockout(operatorID, &permissionChange{
+		apis: map[uint]*model.API{api.ID: &api},
+	})
 	if err != nil {
-		return kperrors.New(kperrors.ErrDatabase, err)
+		return err
 	}
 
-	return nil
+	return repository.GetAPIRepository().Update(&api)
 }
 
 // DeleteAPI 删除API
-func (s *APIServiceImpl) DeleteAPI(id uint) error {
+func (s *APIServiceImpl) DeleteAPI(operatorID uint, id uint) error {
 	// 检查API是否存在
 	var api model.API
 	err := database.GetDB().First(&api, id).Error
@@ -174,34 +180,138 @@ func (s *APIServiceImpl) DeleteAPI(id uint) error {
 		return kperrors.New(kperrors.ErrDatabase, err)
 	}
 
-	// 删除API
-	err = database.GetDB().Delete(&api).Error
+	// 检查是否会导致操作者失去权限管理能力
+	err = checkSelfLockout(operatorID, &permissionChange{
+		apis: map[uint]*model.API{api.ID: nil},
+	})
 	if err != nil {
-		return kperrors.New(kperrors.ErrDatabase, err)
+		return err
 	}
 
-	// 删除角色API关联
-	err = database.GetDB().Where("api_id = ?", id).Delete(&model.RoleAPI{}).Error
-	if err != nil {
-		return kperrors.New(kperrors.ErrDatabase, err)
+	// 删除API及角色API关联，同步关联角色的权限策略
+	return repository.GetAPIRepository().Delete(id)
+}
+
+// BatchDeleteAPI 批量删除API
+func (s *APIServiceImpl) BatchDeleteAPI(operatorID uint, ids []uint) error {
+	// 检查是否会导致操作者失去权限管理能力
+	deleted := make(map[uint]*model.API, len(ids))
+	for _, id := range ids {
+		deleted[id] = nil
+	}
+	if err := checkSelfLockout(operatorID, &permissionChange{apis: deleted}); err != nil {
+		return err
 	}
 
-	return nil
+	// 删除API及角色API关联，同步关联角色的权限策略
+	return repository.GetAPIRepository().BatchDelete(ids)
 }
 
-// BatchDeleteAPI 批量删除API
-func (s *APIServiceImpl) BatchDeleteAPI(ids []uint) error {
-	// 删除API
-	err := database.GetDB().Where("id IN ?", ids).Delete(&model.API{}).Error
+// SetRoutes 设置已注册的路由
+func (s *APIServiceImpl) SetRoutes(routes []*dto.RouteInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes = routes
+}
+
+// SyncRoutes 将已注册的路由同步到API表，dryRun为true时仅返回差异
+func (s *APIServiceImpl) SyncRoutes(dryRun bool) (*dto.APISyncResp, error) {
+	s.mu.RLock()
+	routes := s.routes
+	s.mu.RUnlock()
+
+	// 包含已删除的API，管理员删除的API不再自动新增
+	var apis []*model.API
+	err := database.GetDB().Unscoped().Find(&apis).Error
 	if err != nil {
-		return kperrors.New(kperrors.ErrDatabase, err)
+		return nil, kperrors.New(kperrors.ErrDatabase, err)
+	}
+
+	catalog := make(map[string]*model.API, len(apis))
+	for _, api := range apis {
+		catalog[api.Method+" "+api.Path] = api
+	}
+
+	resp := &dto.APISyncResp{
+		Added:     []*dto.RouteInfo{},
+		Stale:     []*dto.RouteInfo{},
+		Recovered: []*dto.RouteInfo{},
+	}
+
+	var recoveredIDs []uint
+	registered := make(map[string]struct{}, len(routes))
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		registered[key] = struct{}{}
+
+		api, ok := catalog[key]
+		if !ok {
+			resp.Added = append(resp.Added, route)
+			continue
+		}
+		if api.Stale && !api.DeletedAt.Valid {
+			resp.Recovered = append(resp.Recovered, apiRouteInfo(api))
+			recoveredIDs = append(recoveredIDs, api.ID)
+		}
+	}
+
+	var staleIDs []uint
+	for _, api := range apis {
+		if api.Stale || api.DeletedAt.Valid {
+			continue
+		}
+		if _, ok := registered[api.Method+" "+api.Path]; !ok {
+			resp.Stale = append(resp.Stale, apiRouteInfo(api))
+			staleIDs = append(staleIDs, api.ID)
+		}
 	}
 
-	// 删除角色API关联
-	err = database.GetDB().Where("api_id IN ?", ids).Delete(&model.RoleAPI{}).Error
+	if dryRun {
+		return resp, nil
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		if len(resp.Added) > 0 {
+			added := make([]*model.API, 0, len(resp.Added))
+			for _, route := range resp.Added {
+				added = append(added, &model.API{
+					Group:  route.Group,
+					Name:   route.Name,
+					Method: route.Method,
+					Path:   route.Path,
+					Status: 1,
+					Remark: "启动时自动注册",
+				})
+			}
+			if err := tx.Create(&added).Error; err != nil {
+				return err
+			}
+		}
+		if len(staleIDs) > 0 {
+			if err := tx.Model(&model.API{}).Where("id IN ?", staleIDs).Update("stale", true).Error; err != nil {
+				return err
+			}
+		}
+		if len(recoveredIDs) > 0 {
+			if err := tx.Model(&model.API{}).Where("id IN ?", recoveredIDs).Update("stale", false).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return kperrors.New(kperrors.ErrDatabase, err)
+		return nil, kperrors.New(kperrors.ErrDatabase, err)
 	}
 
-	return nil
+	return resp, nil
+}
+
+// apiRouteInfo 将API转换为路由信息
+func apiRouteInfo(api *model.API) *dto.RouteInfo {
+	return &dto.RouteInfo{
+		Method: api.Method,
+		Path:   api.Path,
+		Group:  api.Group,
+		Name:   api.Name,
+	}
 }
@@ -0,0 +1,217 @@
+package impl
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/cuiyuanxin/kunpeng/pkg/logger"
+	"github.com/cuiyuanxin/kunpeng/pkg/signature"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// defaultSignatureClockSkew 默认允许的时钟偏差
+const defaultSignatureClockSkew = 5 * time.Minute
+
+// 随机串长度限制
+const (
+	minNonceLength = 8
+	maxNonceLength = 64
+)
+
+// APISignatureServiceImpl AppKey/AppSecret签名认证服务实现
+type APISignatureServiceImpl struct {
+	userRepo            repository.UserRepository
+	nonceRepo           repository.APINonceRepository
+	twoFactorService    service.TwoFactorService
+	loginAttemptService service.LoginAttemptService
+}
+
+// NewAPISignatureService 创建签名认证服务实例
+func NewAPISignatureService(
+	userRepo repository.UserRepository,
+	nonceRepo repository.APINonceRepository,
+	twoFactorService service.TwoFactorService,
+	loginAttemptService service.LoginAttemptService,
+) *APISignatureServiceImpl {
+	return &APISignatureServiceImpl{
+		userRepo:            userRepo,
+		nonceRepo:           nonceRepo,
+		twoFactorService:    twoFactorService,
+		loginAttemptService: loginAttemptService,
+	}
+}
+
+// Verify 校验签名请求，签名通过后才记录随机串，避免伪造的请求占用随机串
+func (s *APISignatureServiceImpl) Verify(req *signature.Request, sign string) (*model.User, error) {
+	cfg := apiSignatureConfig()
+	if !cfg.Enable {
+		return nil, kperrors.New(kperrors.ErrUnauthorized, nil).WithMessage("未启用签名认证")
+	}
+
+	if req.AppKey == "" || req.Timestamp == "" || req.Nonce == "" || sign == "" {
+		return nil, kperrors.New(kperrors.ErrSignature, nil).WithMessage("缺少签名参数")
+	}
+	if len(req.Nonce) < minNonceLength || len(req.Nonce) > maxNonceLength {
+		return nil, kperrors.New(kperrors.ErrSignature, nil).WithMessage("随机串长度必须为8-64个字符")
+	}
+
+	// 时间戳超出时钟偏差范围的请求直接拒绝，随机串只需保留到时间戳失效为止
+	ts, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return nil, kperrors.New(kperrors.ErrSignature, err).WithMessage("无效的时间戳")
+	}
+	now := time.Now()
+	requestTime := time.Unix(ts, 0)
+	if requestTime.Before(now.Add(-cfg.ClockSkew)) || requestTime.After(now.Add(cfg.ClockSkew)) {
+		return nil, kperrors.New(kperrors.ErrSignature, nil).WithMessage("请求时间戳超出允许范围，请校准客户端时间")
+	}
+
+	// AppKey不存在与签名错误返回相同的错误，避免探测AppKey
+	user, err := s.userRepo.FindByAppKey(req.AppKey)
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return nil, kperrors.New(kperrors.ErrInvalidSign, nil)
+		}
+		return nil, err
+	}
+	if !verifyAppSecret(user, req, sign, now) {
+		return nil, kperrors.New(kperrors.ErrInvalidSign, nil)
+	}
+	// 未轮换过的AppSecret可能是初始化脚本中公开的值或从未下发给用户，不能用于签名认证
+	if user.AppSecretRotatedAt == nil {
+		return nil, kperrors.New(kperrors.ErrSignature, nil).WithMessage("AppSecret尚未轮换，请先轮换AppSecret后再使用签名认证")
+	}
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.nonceRepo.Use(req.AppKey, req.Nonce, requestTime.Add(cfg.ClockSkew))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, kperrors.New(kperrors.ErrSignature, nil).WithMessage("重复的请求")
+	}
+
+	return user, nil
+}
+
+// RotateSecret 为用户生成新的AppSecret，配置了过渡期时原AppSecret在过渡期内仍然有效
+func (s *APISignatureServiceImpl) RotateSecret(userID uint, req *dto.AppSecretRotateReq) (*dto.AppSecretResp, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return nil, kperrors.New(kperrors.ErrUserNotFound, err)
+		}
+		return nil, err
+	}
+
+	// AppSecret不随token撤销而失效，仅凭token不能获取，需重新校验用户身份
+	if err := s.verifyIdentity(user, req); err != nil {
+		return nil, err
+	}
+
+	_, appSecret := jwt.GenerateAppKeyAndSecret(user.Username)
+
+	var prevSecret string
+	var prevUntil *time.Time
+	if grace := apiSignatureConfig().RotationGrace; grace > 0 {
+		until := time.Now().Add(grace)
+		prevSecret, prevUntil = user.AppSecret, &until
+	}
+
+	if err := s.userRepo.UpdateAppSecret(user.ID, appSecret, prevSecret, prevUntil); err != nil {
+		return nil, err
+	}
+
+	logger.GetLogger().Info("轮换AppSecret", zap.Uint("user_id", user.ID), zap.String("app_key", user.AppKey))
+
+	return &dto.AppSecretResp{
+		AppKey:        user.AppKey,
+		AppSecret:     appSecret,
+		PrevExpiresAt: prevUntil,
+	}, nil
+}
+
+// ResetSecret 重置用户的AppSecret，用于AppSecret泄露等情况，新的AppSecret不下发给任何人，用户需自行轮换后才能继续使用签名认证
+func (s *APISignatureServiceImpl) ResetSecret(userID uint) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return kperrors.New(kperrors.ErrUserNotFound, err)
+		}
+		return err
+	}
+
+	_, appSecret := jwt.GenerateAppKeyAndSecret(user.Username)
+	if err := s.userRepo.ResetAppSecret(user.ID, appSecret); err != nil {
+		return err
+	}
+
+	logger.GetLogger().Info("重置AppSecret", zap.Uint("user_id", user.ID), zap.String("app_key", user.AppKey))
+	return nil
+}
+
+// verifyIdentity 校验用户身份，与登录共用账号的失败次数，账号锁定期间不允许校验，避免通过该接口暴力猜测密码
+func (s *APISignatureServiceImpl) verifyIdentity(user *model.User, req *dto.AppSecretRotateReq) error {
+	if req.Code == "" && req.Password == "" {
+		return kperrors.New(kperrors.ErrParam, nil).WithMessage("请输入当前密码或双因素认证动态码")
+	}
+
+	if s.loginAttemptService != nil {
+		if err := s.loginAttemptService.CheckLocked(user.Username, ""); err != nil {
+			return err
+		}
+	}
+
+	err := s.checkCredential(user, req)
+	// 只记录凭据的校验结果，数据库错误等不计入失败次数
+	if s.loginAttemptService != nil && (err == nil || kperrors.IsCode(err, kperrors.ErrUserPassword) || kperrors.IsCode(err, kperrors.ErrAuthTwoFactor)) {
+		s.loginAttemptService.CheckAndRecordAttempt(user.Username, "", err == nil)
+	}
+	return err
+}
+
+// checkCredential 校验双因素认证动态码或当前密码，同时提供时使用动态码
+func (s *APISignatureServiceImpl) checkCredential(user *model.User, req *dto.AppSecretRotateReq) error {
+	if req.Code != "" {
+		return s.twoFactorService.Verify(user.ID, req.Code)
+	}
+
+	// 单点登录或LDAP自动创建的用户没有本地密码，需使用双因素认证动态码
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return kperrors.New(kperrors.ErrUserPassword, err)
+	}
+	return nil
+}
+
+// CleanupExpiredNonces 清理过期的随机串记录
+func (s *APISignatureServiceImpl) CleanupExpiredNonces() error {
+	return s.nonceRepo.CleanExpired()
+}
+
+// verifyAppSecret 使用当前AppSecret校验签名，失败时尝试仍在过渡期内的原AppSecret
+func verifyAppSecret(user *model.User, req *signature.Request, sign string, now time.Time) bool {
+	if signature.Verify(user.AppSecret, req, sign) {
+		return true
+	}
+	return user.PrevAppSecretUntil != nil && now.Before(*user.PrevAppSecretUntil) &&
+		signature.Verify(user.PrevAppSecret, req, sign)
+}
+
+// apiSignatureConfig 获取签名认证配置，未配置的项使用默认值
+func apiSignatureConfig() config.APISignatureConfig {
+	cfg := config.GetAPISignatureConfig()
+	if cfg.ClockSkew <= 0 {
+		cfg.ClockSkew = defaultSignatureClockSkew
+	}
+	return cfg
+}
@@ -0,0 +1,42 @@
+package impl
+
+import (
+	"testing"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/repository"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestVerifyIdentityLocksAfterFailures(t *testing.T) {
+	resetTables(t)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("Passw0rd!"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword error: %v", err)
+	}
+	user := &model.User{ID: 1, Username: "zhangsan", Password: string(hash), Email: "zhangsan@example.com", Mobile: "13800000001"}
+	mustCreate(t, user)
+
+	loginAttemptService := NewLoginAttemptService(repository.GetLoginAttemptRepository())
+	s := NewAPISignatureService(repository.GetUserRepository(), repository.GetAPINonceRepository(), nil, loginAttemptService)
+
+	if err := s.verifyIdentity(user, &dto.AppSecretRotateReq{Password: "Passw0rd!"}); err != nil {
+		t.Fatalf("verifyIdentity error: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := s.verifyIdentity(user, &dto.AppSecretRotateReq{Password: "wrong"}); !kperrors.IsCode(err, kperrors.ErrUserPassword) {
+			t.Fatalf("第%d次 verifyIdentity error = %v, want ErrUserPassword", i+1, err)
+		}
+	}
+
+	// 失败次数达到阈值后账号被锁定，正确的密码也不能通过校验，登录同样被拒绝
+	if err := s.verifyIdentity(user, &dto.AppSecretRotateReq{Password: "Passw0rd!"}); !kperrors.IsCode(err, kperrors.ErrAuthLocked) {
+		t.Fatalf("verifyIdentity error = %v, want ErrAuthLocked", err)
+	}
+	if err := loginAttemptService.CheckLocked(user.Username, "10.0.0.1"); !kperrors.IsCode(err, kperrors.ErrAuthLocked) {
+		t.Fatalf("CheckLocked error = %v, want ErrAuthLocked", err)
+	}
+}
@@ -0,0 +1,371 @@
+package impl
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/repository"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	"github.com/cuiyuanxin/kunpeng/pkg/constants"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/cuiyuanxin/kunpeng/pkg/ldap"
+	"github.com/cuiyuanxin/kunpeng/pkg/logger"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// LDAP默认属性映射
+const (
+	defaultLDAPNicknameAttr = "displayName"
+	defaultLDAPRealNameAttr = "cn"
+	defaultLDAPEmailAttr    = "mail"
+	defaultLDAPMobileAttr   = "mobile"
+)
+
+// LDAP同步时更新的用户字段，同步用户组时还会更新部门
+var (
+	ldapAttributeColumns = []string{"nickname", "real_name", "email", "mobile"}
+	ldapSyncColumns      = []string{"nickname", "real_name", "email", "mobile", "dept_id"}
+)
+
+// ldapIdentityIssuer 外部身份表中LDAP目录账号的身份提供方标识，subject为用户条目的DN
+const ldapIdentityIssuer = "ldap"
+
+// authProvider 登录认证方式，按登录类型校验凭据并返回对应的本地用户
+type authProvider interface {
+	// Authenticate 校验登录凭据，凭据错误时返回 credentialError
+	Authenticate(req *dto.UserLoginReq, clientIP string) (*model.User, error)
+}
+
+// credentialError 登录凭据错误，登录时需记录失败次数
+type credentialError struct {
+	err error
+}
+
+// Error 实现error接口
+func (e *credentialError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap 获取原始错误
+func (e *credentialError) Unwrap() error {
+	return e.err
+}
+
+// checkUserStatus 检查用户状态是否允许登录
+func checkUserStatus(user *model.User) error {
+	if user.Status == 0 {
+		return kperrors.New(kperrors.ErrUserDisabled, nil)
+	}
+	if user.Status == 2 {
+		return kperrors.New(kperrors.ErrUserLocked, nil)
+	}
+	return nil
+}
+
+// passwordAuthProvider 用户名及本地密码认证
+type passwordAuthProvider struct {
+	verifyCaptcha func(req *dto.UserLoginReq, clientIP string) error
+}
+
+// Authenticate 校验本地密码，连续失败达到阈值后需要校验图片验证码
+func (p *passwordAuthProvider) Authenticate(req *dto.UserLoginReq, clientIP string) (*model.User, error) {
+	user, err := repository.GetUserRepository().FindByUsername(req.Account)
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return nil, kperrors.New(kperrors.ErrUserNotFound, err)
+		}
+		return nil, err
+	}
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	if err := p.verifyCaptcha(req, clientIP); err != nil {
+		return nil, err
+	}
+
+	// 单点登录或LDAP自动创建的用户没有本地密码，校验必然失败
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return nil, &credentialError{err: kperrors.New(kperrors.ErrUserPassword, err)}
+	}
+	return user, nil
+}
+
+// mobileAuthProvider 手机号及短信验证码认证
+type mobileAuthProvider struct {
+	smsService service.SMSService
+}
+
+// Authenticate 校验短信验证码
+func (p *mobileAuthProvider) Authenticate(req *dto.UserLoginReq, clientIP string) (*model.User, error) {
+	if p.smsService == nil {
+		return nil, kperrors.New(kperrors.ErrSystem, nil).WithMessage("短信验证码服务未初始化")
+	}
+
+	user, err := repository.GetUserRepository().FindByMobile(req.Account)
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return nil, kperrors.New(kperrors.ErrUserNotFound, err)
+		}
+		return nil, err
+	}
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	if err := p.smsService.VerifyCode(req.Account, model.SMSSceneLogin, req.Captcha); err != nil {
+		return nil, &credentialError{err: err}
+	}
+	return user, nil
+}
+
+// ldapAuthProvider LDAP目录账号认证，认证通过后同步用户属性、角色及部门
+type ldapAuthProvider struct {
+	local          authProvider
+	verifyCaptcha  func(req *dto.UserLoginReq, clientIP string) error
+	sessionService service.SessionService
+}
+
+// Authenticate 以目录账号绑定校验密码，应急账号使用本地密码认证
+func (p *ldapAuthProvider) Authenticate(req *dto.UserLoginReq, clientIP string) (*model.User, error) {
+	cfg := ldapConfig()
+	if !cfg.Enable {
+		return nil, kperrors.New(kperrors.ErrAuthLogin, nil).WithMessage("未启用LDAP登录")
+	}
+
+	// 应急账号不依赖目录，目录不可用时仍可登录
+	if slices.ContainsFunc(cfg.LocalUsers, func(username string) bool { return strings.EqualFold(username, req.Account) }) {
+		return p.local.Authenticate(req, clientIP)
+	}
+
+	if err := p.verifyCaptcha(req, clientIP); err != nil {
+		return nil, err
+	}
+
+	attrs := cfg.Attributes
+	entry, err := ldap.Authenticate(req.Account, req.Password, attrs.Nickname, attrs.RealName, attrs.Email, attrs.Mobile)
+	if err != nil {
+		if errors.Is(err, ldap.ErrInvalidCredentials) {
+			return nil, &credentialError{err: kperrors.New(kperrors.ErrUserPassword, err)}
+		}
+		logger.GetLogger().Error("LDAP认证失败", zap.String("account", req.Account), zap.Error(err))
+		return nil, kperrors.New(kperrors.ErrThirdParty, err).WithMessage("LDAP服务暂时不可用，请稍后重试")
+	}
+
+	user, err := p.syncUser(req.Account, entry, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// syncUser 按目录属性及用户组创建或更新本地用户
+func (p *ldapAuthProvider) syncUser(username string, entry *ldap.User, cfg config.LDAPConfig) (*model.User, error) {
+	userRepo := repository.GetUserRepository()
+	roleIDs, deptID := mapLDAPGroups(entry.Groups, cfg)
+
+	user, err := userRepo.FindByUsername(username)
+	if err != nil {
+		if !kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return nil, err
+		}
+		if !cfg.AutoProvision {
+			return nil, kperrors.New(kperrors.ErrUserNotFound, err).WithMessage("目录账号尚未开通系统账号，请联系管理员")
+		}
+		return p.provision(username, entry, roleIDs, deptID, cfg)
+	}
+
+	// 已有的本地账号只有在由LDAP创建或管理员明确允许关联时才能通过目录登录，避免目录中的同名账号接管本地账号
+	if err := p.checkLinked(user, entry, cfg); err != nil {
+		return nil, err
+	}
+
+	changed := applyLDAPAttributes(user, entry, cfg)
+
+	// 内置用户必须保留其内置角色，不按用户组同步
+	if !cfg.SyncRoles || user.IsBuiltin {
+		if changed {
+			if err := userRepo.Update(user, ldapAttributeColumns...); err != nil {
+				return nil, err
+			}
+		}
+		return user, nil
+	}
+
+	oldRoleIDs, err := userRepo.FindRoleIDs(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	rolesChanged := !sameIDs(oldRoleIDs, roleIDs)
+	if !rolesChanged && !changed && user.DeptID == deptID {
+		return user, nil
+	}
+	if err := checkRoleIDs(roleIDs); err != nil {
+		return nil, err
+	}
+
+	user.DeptID = deptID
+	if err := userRepo.UpdateWithRoles(user, roleIDs, ldapSyncColumns...); err != nil {
+		return nil, err
+	}
+	if !rolesChanged {
+		return user, nil
+	}
+
+	// 角色变更后使此前签发的token失效，重新获取用户以使用递增后的token版本号签发token
+	if err := p.sessionService.RevokeUserTokens(user.ID, "LDAP用户组变更"); err != nil {
+		return nil, err
+	}
+	return userRepo.FindByID(user.ID)
+}
+
+// provision 自动创建目录用户，用户没有本地密码，只能通过LDAP登录
+func (p *ldapAuthProvider) provision(username string, entry *ldap.User, roleIDs []uint, deptID uint, cfg config.LDAPConfig) (*model.User, error) {
+	if matched, _ := regexp.MatchString(constants.UsernameRegex, username); !matched {
+		return nil, kperrors.New(kperrors.ErrUserNotFound, nil).WithMessage("目录账号的用户名不符合要求，无法自动创建账号")
+	}
+	if err := checkRoleIDs(roleIDs); err != nil {
+		return nil, err
+	}
+
+	appKey, appSecret := jwt.GenerateAppKeyAndSecret(username)
+	now := time.Now()
+	user := &model.User{
+		Username:          username,
+		DeptID:            deptID,
+		Status:            1,
+		AppKey:            appKey,
+		AppSecret:         appSecret,
+		PasswordChangedAt: &now,
+		Remark:            "LDAP登录自动创建",
+	}
+	applyLDAPAttributes(user, entry, cfg)
+
+	if err := repository.GetUserRepository().CreateWithRoles(user, roleIDs); err != nil {
+		return nil, err
+	}
+
+	if err := repository.GetUserIdentityRepository().Create(&model.UserIdentity{
+		UserID:      user.ID,
+		Issuer:      ldapIdentityIssuer,
+		Subject:     entry.DN,
+		Email:       user.Email,
+		LastLoginAt: &now,
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.GetLogger().Info("LDAP登录自动创建用户", zap.Uint("user_id", user.ID), zap.String("username", username))
+	return user, nil
+}
+
+// checkLinked 检查本地账号是否已关联目录账号，未关联时仅允许关联link_users中的账号
+func (p *ldapAuthProvider) checkLinked(user *model.User, entry *ldap.User, cfg config.LDAPConfig) error {
+	identityRepo := repository.GetUserIdentityRepository()
+	identity, err := identityRepo.FindByUserID(user.ID, ldapIdentityIssuer)
+	if err == nil {
+		if err := identityRepo.UpdateLogin(identity.ID, entry.GetAttributeValue(cfg.Attributes.Email)); err != nil {
+			logger.GetLogger().Error("更新LDAP身份登录时间失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+		return nil
+	}
+	if !kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+		return err
+	}
+
+	if !slices.ContainsFunc(cfg.LinkUsers, func(username string) bool { return strings.EqualFold(username, user.Username) }) {
+		logger.GetLogger().Warn("LDAP登录拒绝关联本地账号", zap.Uint("user_id", user.ID), zap.String("dn", entry.DN))
+		return kperrors.New(kperrors.ErrUserExists, nil).WithMessage("该账号不是目录账号，不能使用LDAP登录，请联系管理员")
+	}
+
+	now := time.Now()
+	if err := identityRepo.Create(&model.UserIdentity{
+		UserID:      user.ID,
+		Issuer:      ldapIdentityIssuer,
+		Subject:     entry.DN,
+		Email:       entry.GetAttributeValue(cfg.Attributes.Email),
+		LastLoginAt: &now,
+	}); err != nil {
+		return err
+	}
+
+	logger.GetLogger().Info("LDAP登录关联本地账号", zap.Uint("user_id", user.ID), zap.String("dn", entry.DN))
+	return nil
+}
+
+// applyLDAPAttributes 使用目录中的属性更新用户信息，目录中为空的属性不覆盖，返回是否有变更
+func applyLDAPAttributes(user *model.User, entry *ldap.User, cfg config.LDAPConfig) bool {
+	changed := false
+	for _, field := range []struct {
+		target *string
+		attr   string
+	}{
+		{&user.Nickname, cfg.Attributes.Nickname},
+		{&user.RealName, cfg.Attributes.RealName},
+		{&user.Email, cfg.Attributes.Email},
+		{&user.Mobile, cfg.Attributes.Mobile},
+	} {
+		value := entry.GetAttributeValue(field.attr)
+		if value != "" && value != *field.target {
+			*field.target = value
+			changed = true
+		}
+	}
+	return changed
+}
+
+// mapLDAPGroups 按用户组映射获取角色及部门，未匹配到时使用默认角色及部门
+func mapLDAPGroups(groups []string, cfg config.LDAPConfig) ([]uint, uint) {
+	var roleIDs []uint
+	var deptID uint
+	for _, mapping := range cfg.GroupMappings {
+		if !slices.ContainsFunc(groups, func(group string) bool { return strings.EqualFold(group, mapping.Group) }) {
+			continue
+		}
+		for _, roleID := range mapping.RoleIDs {
+			if !slices.Contains(roleIDs, roleID) {
+				roleIDs = append(roleIDs, roleID)
+			}
+		}
+		if deptID == 0 {
+			deptID = mapping.DeptID
+		}
+	}
+
+	if len(roleIDs) == 0 {
+		roleIDs = cfg.DefaultRoleIDs
+	}
+	if deptID == 0 {
+		deptID = cfg.DefaultDeptID
+	}
+	return roleIDs, deptID
+}
+
+// ldapConfig 获取LDAP认证配置，未配置的属性映射使用默认值
+func ldapConfig() config.LDAPConfig {
+	cfg := config.GetLDAPConfig()
+	if cfg.Attributes.Nickname == "" {
+		cfg.Attributes.Nickname = defaultLDAPNicknameAttr
+	}
+	if cfg.Attributes.RealName == "" {
+		cfg.Attributes.RealName = defaultLDAPRealNameAttr
+	}
+	if cfg.Attributes.Email == "" {
+		cfg.Attributes.Email = defaultLDAPEmailAttr
+	}
+	if cfg.Attributes.Mobile == "" {
+		cfg.Attributes.Mobile = defaultLDAPMobileAttr
+	}
+	return cfg
+}
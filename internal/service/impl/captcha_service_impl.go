@@ -0,0 +1,55 @@
+package impl
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/pkg/captcha"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+)
+
+// CaptchaServiceImpl 图片验证码服务实现
+type CaptchaServiceImpl struct {
+	loginAttemptService service.LoginAttemptService
+}
+
+// NewCaptchaService 创建图片验证码服务实例
+func NewCaptchaService(loginAttemptService service.LoginAttemptService) *CaptchaServiceImpl {
+	return &CaptchaServiceImpl{
+		loginAttemptService: loginAttemptService,
+	}
+}
+
+// Generate 生成图片验证码
+func (s *CaptchaServiceImpl) Generate() (*dto.CaptchaResp, error) {
+	id, b64, err := captcha.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.CaptchaResp{
+		CaptchaID:     id,
+		CaptchaBase64: b64,
+	}, nil
+}
+
+// Verify 校验图片验证码，验证码只能使用一次
+func (s *CaptchaServiceImpl) Verify(captchaID, code string) (bool, error) {
+	return captcha.Verify(captchaID, code)
+}
+
+// IsRequired 检查账号登录是否需要图片验证码，连续登录失败达到阈值后需要
+func (s *CaptchaServiceImpl) IsRequired(account, ip string) (bool, error) {
+	cfg := config.GetCaptchaConfig()
+	if !cfg.Enable {
+		return false, nil
+	}
+	if cfg.FailThreshold <= 0 || s.loginAttemptService == nil {
+		return true, nil
+	}
+
+	attempts, err := s.loginAttemptService.GetFailedAttempts(account, ip)
+	if err != nil {
+		return false, err
+	}
+	return attempts >= cfg.FailThreshold, nil
+}
@@ -1,19 +1,26 @@
 package impl
 
 import (
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
 	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
 	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
 	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
 )
 
+// 登录锁定默认配置，未配置时与此前固定的失败5次锁定2小时一致
 const (
-	// MaxFailedAttempts 最大失败尝试次数
-	MaxFailedAttempts = 5
-	// BlockDuration 拉黑时长（2小时）
-	BlockDuration = 2 * time.Hour
+	defaultAccountThreshold  = 5
+	defaultIPThreshold       = 50
+	defaultLockDuration      = 2 * time.Hour
+	defaultBackoffMultiplier = 1
+	defaultMaxLockDuration   = 24 * time.Hour
+	defaultResetAfter        = 24 * time.Hour
 )
 
 // LoginAttemptServiceImpl 登录尝试服务实现
@@ -28,85 +35,200 @@ func NewLoginAttemptService(loginAttemptRepo repository.LoginAttemptRepository)
 	}
 }
 
-// CheckAndRecordAttempt 检查并记录登录尝试
+// CheckAndRecordAttempt 记录登录结果
+// 登录成功只重置账号的失败次数，IP的失败次数不因其中某个账号登录成功而清零
 func (s *LoginAttemptServiceImpl) CheckAndRecordAttempt(account, ip string, success bool) (blocked bool, err error) {
-	// 获取现有记录
-	attempt, err := s.loginAttemptRepo.FindByAccountAndIP(account, ip)
+	if success {
+		return false, s.loginAttemptRepo.ResetByAccount(account)
+	}
+
+	policy := getLockPolicy()
+	accountBlocked, err := s.recordFailure(model.LoginAttemptScopeAccount, account, account, ip, policy.AccountThreshold, policy)
 	if err != nil {
-		if kpErr, ok := err.(*kperrors.Error); ok && kpErr.Code == kperrors.ErrDBNotFound {
-			// 记录不存在，继续创建新记录
-		} else {
-			return false, err
-		}
+		return false, err
+	}
+	ipBlocked, err := s.recordFailure(model.LoginAttemptScopeIP, ip, account, ip, policy.IPThreshold, policy)
+	if err != nil {
+		return false, err
 	}
 
-	// 如果没有记录，创建新记录
-	if attempt == nil {
-		attempt = &model.LoginAttempt{
-			Account:  account,
-			IP:       ip,
-			Attempts: 0,
-			LastTry:  time.Now(),
-		}
+	return accountBlocked || ipBlocked, nil
+}
+
+// CheckLocked 检查账号或IP是否处于锁定中
+func (s *LoginAttemptServiceImpl) CheckLocked(account, ip string) error {
+	attempt, err := s.findAttempt(model.LoginAttemptScopeAccount, account)
+	if err != nil {
+		return err
+	}
+	if attempt != nil && attempt.IsBlocked() {
+		return kperrors.New(kperrors.ErrAuthLocked, nil).
+			WithMessage(fmt.Sprintf("登录失败次数过多，账号已被锁定，请%s后重试", formatLockRemaining(*attempt.LockedUntil)))
 	}
 
-	// 检查是否已被拉黑
-	if attempt.IsBlocked() {
-		return true, nil
+	attempt, err = s.findAttempt(model.LoginAttemptScopeIP, ip)
+	if err != nil {
+		return err
+	}
+	if attempt != nil && attempt.IsBlocked() {
+		return kperrors.New(kperrors.ErrAuthLocked, nil).
+			WithMessage(fmt.Sprintf("登录失败次数过多，当前IP已被锁定，请%s后重试", formatLockRemaining(*attempt.LockedUntil)))
 	}
 
-	// 更新尝试记录
-	attempt.LastTry = time.Now()
+	return nil
+}
 
-	if success {
-		// 登录成功，重置失败次数
-		attempt.Reset()
-	} else {
-		// 登录失败，增加失败次数
-		attempt.Attempts++
-
-		// 检查是否需要拉黑
-		if attempt.ShouldBlock() {
-			attempt.Block()
-			blocked = true
+// ResetAttempts 重置账号的登录尝试记录
+func (s *LoginAttemptServiceImpl) ResetAttempts(account string) error {
+	return s.loginAttemptRepo.ResetByAccount(account)
+}
+
+// CleanupExpiredRecords 清理过期的登录尝试记录
+func (s *LoginAttemptServiceImpl) CleanupExpiredRecords() error {
+	// 清理过期的记录
+	return s.loginAttemptRepo.CleanExpired()
+}
+
+// GetFailedAttempts 获取账号及IP的连续登录失败次数，取两者中较大的值
+func (s *LoginAttemptServiceImpl) GetFailedAttempts(account, ip string) (int, error) {
+	policy := getLockPolicy()
+
+	var attempts int
+	for scope, subject := range map[string]string{
+		model.LoginAttemptScopeAccount: account,
+		model.LoginAttemptScopeIP:      ip,
+	} {
+		attempt, err := s.findAttempt(scope, subject)
+		if err != nil {
+			return 0, err
+		}
+		if attempt != nil && time.Since(attempt.LastTry) <= policy.ResetAfter {
+			attempts = max(attempts, attempt.Attempts)
 		}
 	}
 
-	// 保存记录
-	if attempt.ID == 0 {
-		err = s.loginAttemptRepo.Create(attempt)
-	} else {
-		err = s.loginAttemptRepo.Update(attempt)
+	return attempts, nil
+}
+
+// GetLockedList 获取锁定中的账号及IP列表
+func (s *LoginAttemptServiceImpl) GetLockedList(req *dto.LoginLockListReq) (*dto.PageResp, error) {
+	attempts, total, err := s.loginAttemptRepo.FindLockedList(req)
+	if err != nil {
+		return nil, err
 	}
 
-	return blocked, err
+	// 计算总页数
+	totalPages := int(math.Ceil(float64(total) / float64(req.PageSize)))
+
+	return &dto.PageResp{
+		List:       dto.ToLoginLockRespList(attempts),
+		Total:      total,
+		PageNum:    req.PageNum,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+	}, nil
 }
 
-// IsBlocked 检查账号是否被拉黑
-func (s *LoginAttemptServiceImpl) IsBlocked(account, ip string) (bool, error) {
-	attempt, err := s.loginAttemptRepo.FindByAccountAndIP(account, ip)
-	if err != nil {
+// Unlock 解除锁定并清零失败次数
+func (s *LoginAttemptServiceImpl) Unlock(id uint) error {
+	if _, err := s.loginAttemptRepo.FindByID(id); err != nil {
 		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
-			return false, nil
+			return kperrors.New(kperrors.ErrParam, err).WithMessage("锁定记录不存在")
 		}
+		return err
+	}
+
+	return s.loginAttemptRepo.Delete(id)
+}
+
+// recordFailure 累计账号或IP的失败次数，达到阈值后锁定
+// 锁定期满后失败次数不清零，再次失败时立即重新锁定，锁定时长按倍数递增
+func (s *LoginAttemptServiceImpl) recordFailure(scope, subject, account, ip string, threshold int, policy config.LoginLockConfig) (bool, error) {
+	if subject == "" {
+		return false, nil
+	}
+
+	// 先在数据库中原子地累计失败次数，再按累计后的次数判断是否锁定
+	attempt, err := s.loginAttemptRepo.IncrFailure(scope, subject, account, ip, time.Now().Add(-policy.ResetAfter))
+	if err != nil {
 		return false, err
 	}
+	if attempt.IsBlocked() {
+		return true, nil
+	}
+	if attempt.Attempts < threshold {
+		return false, nil
+	}
 
-	return attempt.IsBlocked(), nil
+	if err := s.loginAttemptRepo.Block(attempt.ID, lockDuration(policy, attempt.LockCount)); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-// ResetAttempts 重置账号的登录尝试记录
-func (s *LoginAttemptServiceImpl) ResetAttempts(account string) error {
-	return s.loginAttemptRepo.ResetByAccount(account)
+// findAttempt 获取登录尝试记录，不存在时返回nil
+func (s *LoginAttemptServiceImpl) findAttempt(scope, subject string) (*model.LoginAttempt, error) {
+	if subject == "" {
+		return nil, nil
+	}
+
+	attempt, err := s.loginAttemptRepo.FindBySubject(scope, subject)
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return attempt, nil
 }
 
-// CleanupExpiredRecords 清理过期的登录尝试记录
-func (s *LoginAttemptServiceImpl) CleanupExpiredRecords() error {
-	// 清理过期的记录
-	return s.loginAttemptRepo.CleanExpired()
+// getLockPolicy 获取登录锁定配置，未配置的项使用默认值
+func getLockPolicy() config.LoginLockConfig {
+	policy := config.GetLoginLockConfig()
+	if policy.AccountThreshold <= 0 {
+		policy.AccountThreshold = defaultAccountThreshold
+	}
+	if policy.IPThreshold <= 0 {
+		policy.IPThreshold = defaultIPThreshold
+	}
+	if policy.LockDuration <= 0 {
+		policy.LockDuration = defaultLockDuration
+	}
+	if policy.BackoffMultiplier < 1 {
+		policy.BackoffMultiplier = defaultBackoffMultiplier
+	}
+	if policy.MaxLockDuration <= 0 {
+		policy.MaxLockDuration = defaultMaxLockDuration
+	}
+	if policy.MaxLockDuration < policy.LockDuration {
+		policy.MaxLockDuration = policy.LockDuration
+	}
+	if policy.ResetAfter <= 0 {
+		policy.ResetAfter = defaultResetAfter
+	}
+	return policy
 }
 
-// GetAttempt 获取登录尝试记录
-func (s *LoginAttemptServiceImpl) GetAttempt(account, ip string) (*model.LoginAttempt, error) {
-	return s.loginAttemptRepo.FindByAccountAndIP(account, ip)
+// lockDuration 计算第lockCount+1次锁定的时长
+func lockDuration(policy config.LoginLockConfig, lockCount int) time.Duration {
+	d := float64(policy.LockDuration) * math.Pow(policy.BackoffMultiplier, float64(lockCount))
+	if d >= float64(policy.MaxLockDuration) {
+		return policy.MaxLockDuration
+	}
+	return time.Duration(d)
+}
+
+// formatLockRemaining 格式化剩余锁定时间，不足1分钟按1分钟计
+func formatLockRemaining(until time.Time) string {
+	minutes := int(math.Ceil(time.Until(until).Minutes()))
+	if minutes < 1 {
+		minutes = 1
+	}
+	if minutes < 60 {
+		return fmt.Sprintf("%d分钟", minutes)
+	}
+	if minutes%60 == 0 {
+		return fmt.Sprintf("%d小时", minutes/60)
+	}
+	return fmt.Sprintf("%d小时%d分钟", minutes/60, minutes%60)
 }
@@ -0,0 +1,146 @@
+package impl
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/repository"
+	"github.com/cuiyuanxin/kunpeng/pkg/database"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+)
+
+const (
+	testLoginAccount = "zhangsan"
+	testLoginIP      = "10.0.0.1"
+)
+
+func newTestLoginAttemptService(t *testing.T) *LoginAttemptServiceImpl {
+	t.Helper()
+	resetTables(t)
+	return NewLoginAttemptService(repository.GetLoginAttemptRepository()).(*LoginAttemptServiceImpl)
+}
+
+// findTestAttempt 获取账号的登录尝试记录
+func findTestAttempt(t *testing.T, scope, subject string) *model.LoginAttempt {
+	t.Helper()
+
+	var attempt model.LoginAttempt
+	if err := database.GetDB().Where("scope = ? AND subject = ?", scope, subject).First(&attempt).Error; err != nil {
+		t.Fatalf("查询登录尝试记录失败: %v", err)
+	}
+	return &attempt
+}
+
+func TestRecordFailureLocksAtThreshold(t *testing.T) {
+	s := newTestLoginAttemptService(t)
+
+	for i := 1; i <= 3; i++ {
+		blocked, err := s.CheckAndRecordAttempt(testLoginAccount, testLoginIP, false)
+		if err != nil {
+			t.Fatalf("CheckAndRecordAttempt error: %v", err)
+		}
+		if blocked != (i == 3) {
+			t.Fatalf("第%d次失败 blocked = %v", i, blocked)
+		}
+	}
+	if err := s.CheckLocked(testLoginAccount, testLoginIP); !kperrors.IsCode(err, kperrors.ErrAuthLocked) {
+		t.Fatalf("CheckLocked error = %v, want ErrAuthLocked", err)
+	}
+
+	// 锁定期间不再累计
+	if _, err := s.CheckAndRecordAttempt(testLoginAccount, testLoginIP, false); err != nil {
+		t.Fatalf("CheckAndRecordAttempt error: %v", err)
+	}
+	attempt := findTestAttempt(t, model.LoginAttemptScopeAccount, testLoginAccount)
+	if attempt.Attempts != 3 || attempt.LockCount != 1 {
+		t.Errorf("Attempts = %d, LockCount = %d, want 3, 1", attempt.Attempts, attempt.LockCount)
+	}
+	if ip := findTestAttempt(t, model.LoginAttemptScopeIP, testLoginIP); ip.Attempts != 4 {
+		t.Errorf("IP Attempts = %d, want 4", ip.Attempts)
+	}
+}
+
+func TestRecordFailureRelocksAfterExpiry(t *testing.T) {
+	s := newTestLoginAttemptService(t)
+
+	past := time.Now().Add(-time.Minute)
+	mustCreate(t, &model.LoginAttempt{
+		Scope:       model.LoginAttemptScopeAccount,
+		Subject:     testLoginAccount,
+		Attempts:    3,
+		LockCount:   1,
+		LastTry:     time.Now().Add(-20 * time.Minute),
+		BlockedAt:   &past,
+		LockedUntil: &past,
+	})
+
+	blocked, err := s.CheckAndRecordAttempt(testLoginAccount, testLoginIP, false)
+	if err != nil {
+		t.Fatalf("CheckAndRecordAttempt error: %v", err)
+	}
+	if !blocked {
+		t.Fatalf("锁定期满后再次失败应立即重新锁定")
+	}
+	attempt := findTestAttempt(t, model.LoginAttemptScopeAccount, testLoginAccount)
+	if attempt.Attempts != 4 || attempt.LockCount != 2 {
+		t.Errorf("Attempts = %d, LockCount = %d, want 4, 2", attempt.Attempts, attempt.LockCount)
+	}
+	// 第二次锁定时长按倍数递增
+	if d := time.Until(*attempt.LockedUntil); d < 29*time.Minute || d > 30*time.Minute {
+		t.Errorf("锁定剩余时长 = %v, want 30m", d)
+	}
+}
+
+func TestRecordFailureResetsAfterQuietPeriod(t *testing.T) {
+	s := newTestLoginAttemptService(t)
+
+	past := time.Now().Add(-2 * time.Hour)
+	mustCreate(t, &model.LoginAttempt{
+		Scope:       model.LoginAttemptScopeAccount,
+		Subject:     testLoginAccount,
+		Attempts:    3,
+		LockCount:   2,
+		LastTry:     past,
+		BlockedAt:   &past,
+		LockedUntil: &past,
+	})
+
+	blocked, err := s.CheckAndRecordAttempt(testLoginAccount, testLoginIP, false)
+	if err != nil {
+		t.Fatalf("CheckAndRecordAttempt error: %v", err)
+	}
+	if blocked {
+		t.Fatalf("超过重置时间后失败次数应重新计数")
+	}
+	attempt := findTestAttempt(t, model.LoginAttemptScopeAccount, testLoginAccount)
+	if attempt.Attempts != 1 || attempt.LockCount != 0 || attempt.LockedUntil != nil {
+		t.Errorf("Attempts = %d, LockCount = %d, LockedUntil = %v, want 1, 0, nil", attempt.Attempts, attempt.LockCount, attempt.LockedUntil)
+	}
+}
+
+func TestRecordFailureConcurrent(t *testing.T) {
+	s := newTestLoginAttemptService(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.CheckAndRecordAttempt(testLoginAccount, testLoginIP, false); err != nil {
+				t.Errorf("CheckAndRecordAttempt error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 锁定前并发的失败仍会累计，但只锁定一次
+	attempt := findTestAttempt(t, model.LoginAttemptScopeAccount, testLoginAccount)
+	if attempt.Attempts < 3 || attempt.LockCount != 1 || !attempt.IsBlocked() {
+		t.Errorf("Attempts = %d, LockCount = %d, blocked = %v, want >=3, 1, true", attempt.Attempts, attempt.LockCount, attempt.IsBlocked())
+	}
+	if ip := findTestAttempt(t, model.LoginAttemptScopeIP, testLoginIP); ip.Attempts != 20 {
+		t.Errorf("IP Attempts = %d, want 20", ip.Attempts)
+	}
+}
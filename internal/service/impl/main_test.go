@@ -0,0 +1,129 @@
+package impl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/repository"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	"github.com/cuiyuanxin/kunpeng/pkg/database"
+	"github.com/cuiyuanxin/kunpeng/pkg/logger"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// testConfig 测试使用的配置，启用接口权限校验
+const testConfig = `
+app:
+  name: "kunpeng-test"
+  mode: "development"
+log:
+  level: "error"
+casbin:
+  enable: true
+  super_admin: "admin"
+sms:
+  max_verify_attempts: 3
+login_lock:
+  account_threshold: 3
+  ip_threshold: 100
+  lock_duration: 15m
+  backoff_multiplier: 2
+  reset_after: 1h
+`
+
+// testModels 测试数据库中创建的表
+var testModels = []interface{}{
+	&model.User{},
+	&model.Role{},
+	&model.UserRole{},
+	&model.API{},
+	&model.RoleAPI{},
+	&model.RoleMenu{},
+	&model.SMSCode{},
+	&model.LoginAttempt{},
+}
+
+// TestMain 使用临时配置及SQLite内存数据库初始化仓储
+func TestMain(m *testing.M) {
+	if err := setupTestEnv(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func setupTestEnv() error {
+	dir, err := os.MkdirTemp("", "kunpeng-test")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(testConfig), 0o600); err != nil {
+		return err
+	}
+	if err := config.Init(path); err != nil {
+		return err
+	}
+	if err := logger.Init(); err != nil {
+		return err
+	}
+
+	db, err := gorm.Open(sqlite.Open("file:kunpeng?mode=memory&cache=shared"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{TablePrefix: "kp_", SingularTable: true},
+		Logger:         gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		return err
+	}
+	// 内存数据库的连接关闭后数据即丢失，使用单个连接，并发请求在数据库中依次执行
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(testModels...); err != nil {
+		return err
+	}
+
+	database.SetDB(db)
+	repository.Init()
+	return nil
+}
+
+// resetTables 清空测试表
+func resetTables(t *testing.T) {
+	t.Helper()
+
+	db := database.GetDB()
+	for _, m := range testModels {
+		if err := db.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(m).Error; err != nil {
+			t.Fatalf("清空测试表失败: %v", err)
+		}
+	}
+}
+
+// mustCreate 创建测试数据
+func mustCreate(t *testing.T, values ...interface{}) {
+	t.Helper()
+
+	for _, value := range values {
+		if err := database.GetDB().Create(value).Error; err != nil {
+			t.Fatalf("创建测试数据失败: %v", err)
+		}
+	}
+}
+
+// mustFind 根据ID获取测试数据
+func mustFind(t *testing.T, dest interface{}, id uint) {
+	t.Helper()
+
+	if err := database.GetDB().First(dest, id).Error; err != nil {
+		t.Fatalf("查询测试数据失败: %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/cuiyuanxin/kunpeng/internal/model"
 	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/repository"
 	"github.com/cuiyuanxin/kunpeng/pkg/database"
 	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
 	"gorm.io/gorm"
@@ -59,32 +60,41 @@ func (s *MenuServiceImpl) GetUserMenuTree(userID uint) ([]*dto.MenuTreeResp, err
 		return nil, kperrors.New(kperrors.ErrDatabase, err)
 	}
 
-	// 获取用户角色
-	var role model.Role
-	err = database.GetDB().First(&role, user.RoleID).Error
+	// 获取用户的有效角色，包括继承的上级角色
+	roles, err := repository.GetRoleRepository().FindEffectiveByUserID(user.ID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, kperrors.New(kperrors.ErrRoleNotFound, err)
-		}
-		return nil, kperrors.New(kperrors.ErrDatabase, err)
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return []*dto.MenuTreeResp{}, nil
 	}
 
-	// 获取角色菜单
+	roleIDs := make([]uint, 0, len(roles))
+	for _, role := range roles {
+		roleIDs = append(roleIDs, role.ID)
+	}
+
+	// 获取所有角色菜单
 	var roleMenus []model.RoleMenu
-	err = database.GetDB().Where("role_id = ?", role.ID).Find(&roleMenus).Error
+	err = database.GetDB().Where("role_id IN ?", roleIDs).Find(&roleMenus).Error
 	if err != nil {
 		return nil, kperrors.New(kperrors.ErrDatabase, err)
 	}
 
-	// 提取菜单ID
+	// 提取菜单ID，合并多个角色的菜单
 	menuIDs := make([]uint, 0, len(roleMenus))
+	seen := make(map[uint]struct{}, len(roleMenus))
 	for _, rm := range roleMenus {
+		if _, ok := seen[rm.MenuID]; ok {
+			continue
+		}
+		seen[rm.MenuID] = struct{}{}
 		menuIDs = append(menuIDs, rm.MenuID)
 	}
 
-	// 获取菜单
+	// 获取菜单，按钮不参与菜单树构建
 	var menus []*model.Menu
-	err = database.GetDB().Where("id IN ? AND status = ? AND visible = ?", menuIDs, 1, 1).Order("sort ASC").Find(&menus).Error
+	err = database.GetDB().Where("id IN ? AND status = ? AND visible = ? AND type IN (0, 1)", menuIDs, 1, 1).Order("sort ASC").Find(&menus).Error
 	if err != nil {
 		return nil, kperrors.New(kperrors.ErrDatabase, err)
 	}
@@ -93,6 +103,26 @@ func (s *MenuServiceImpl) GetUserMenuTree(userID uint) ([]*dto.MenuTreeResp, err
 	return s.buildMenuTree(menus, 0), nil
 }
 
+// GetUserPermissions 获取用户的权限标识
+func (s *MenuServiceImpl) GetUserPermissions(userID uint) ([]string, error) {
+	return repository.GetMenuRepository().FindUserPermissions(userID)
+}
+
+// HasPermission 检查用户是否拥有指定权限标识
+func (s *MenuServiceImpl) HasPermission(userID uint, permission string) (bool, error) {
+	permissions, err := s.GetUserPermissions(userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range permissions {
+		if p == model.AllPermission || p == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // GetMenuByID 根据ID获取菜单
 func (s *MenuServiceImpl) GetMenuByID(id uint) (*model.Menu, error) {
 	var menu model.Menu
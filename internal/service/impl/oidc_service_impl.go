@@ -0,0 +1,347 @@
+package impl
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	"github.com/cuiyuanxin/kunpeng/pkg/constants"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/cuiyuanxin/kunpeng/pkg/logger"
+	"github.com/cuiyuanxin/kunpeng/pkg/oidc"
+	"go.uber.org/zap"
+)
+
+// 默认配置
+const (
+	defaultOIDCStateTTL      = 10 * time.Minute
+	defaultOIDCUsernameClaim = "preferred_username"
+	defaultOIDCEmailClaim    = "email"
+	defaultOIDCNicknameClaim = "name"
+)
+
+// OIDCServiceImpl 单点登录服务实现
+type OIDCServiceImpl struct {
+	stateRepo    repository.OIDCStateRepository
+	identityRepo repository.UserIdentityRepository
+	userRepo     repository.UserRepository
+}
+
+// NewOIDCService 创建单点登录服务实例
+func NewOIDCService(stateRepo repository.OIDCStateRepository, identityRepo repository.UserIdentityRepository, userRepo repository.UserRepository) *OIDCServiceImpl {
+	return &OIDCServiceImpl{
+		stateRepo:    stateRepo,
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// Authorize 发起授权请求
+func (s *OIDCServiceImpl) Authorize(userID uint, clientIP string) (*dto.OIDCAuthorizeResp, error) {
+	provider, err := oidc.GetProvider()
+	if err != nil {
+		return nil, err
+	}
+	cfg := oidcConfig()
+
+	state, err := oidc.RandomString(32)
+	if err != nil {
+		return nil, kperrors.New(kperrors.ErrSystem, err)
+	}
+	nonce, err := oidc.RandomString(32)
+	if err != nil {
+		return nil, kperrors.New(kperrors.ErrSystem, err)
+	}
+	verifier, challenge, err := oidc.GeneratePKCE()
+	if err != nil {
+		return nil, kperrors.New(kperrors.ErrSystem, err)
+	}
+
+	record := &model.OIDCState{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		UserID:       userID,
+		IP:           clientIP,
+		ExpiresAt:    time.Now().Add(cfg.StateTTL),
+	}
+	if err := s.stateRepo.Create(record); err != nil {
+		return nil, err
+	}
+
+	return &dto.OIDCAuthorizeResp{
+		AuthorizeURL: provider.AuthCodeURL(state, nonce, challenge),
+		State:        state,
+		ExpiresIn:    int64(cfg.StateTTL.Seconds()),
+	}, nil
+}
+
+// Authenticate 校验登录回调并返回身份关联的用户
+func (s *OIDCServiceImpl) Authenticate(req *dto.OIDCCallbackReq) (*model.User, error) {
+	provider, claims, err := s.verifyCallback(req, 0)
+	if err != nil {
+		return nil, err
+	}
+	cfg := oidcConfig()
+	email := claims.String(cfg.Claims.Email)
+
+	identity, err := s.identityRepo.FindBySubject(provider.Issuer(), claims.Subject())
+	if err == nil {
+		user, err := s.userRepo.FindByID(identity.UserID)
+		if err != nil {
+			if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+				return nil, kperrors.New(kperrors.ErrUserNotFound, err)
+			}
+			return nil, err
+		}
+		if err := s.identityRepo.UpdateLogin(identity.ID, email); err != nil {
+			logger.GetLogger().Error("更新外部身份登录时间失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+		return user, nil
+	}
+	if !kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+		return nil, err
+	}
+
+	// 身份未关联账号时，按已验证的邮箱关联已有用户
+	if cfg.LinkByEmail && email != "" && claims.Bool("email_verified") {
+		user, err := s.userRepo.FindByEmail(email)
+		if err == nil {
+			// 内置用户及超级管理员不按邮箱自动关联，避免身份提供方的同邮箱账号接管高权限账号
+			explicit, err := requiresExplicitLink(user)
+			if err != nil {
+				return nil, err
+			}
+			if explicit {
+				logger.GetLogger().Warn("OIDC登录拒绝按邮箱关联高权限账号", zap.Uint("user_id", user.ID), zap.String("subject", claims.Subject()))
+				return nil, kperrors.New(kperrors.ErrAuthSSO, nil).WithMessage("该身份尚未关联系统账号，请先使用账号密码登录后关联")
+			}
+			if err := s.createIdentity(user.ID, provider.Issuer(), claims.Subject(), email); err != nil {
+				return nil, err
+			}
+			return user, nil
+		}
+		if !kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return nil, err
+		}
+	}
+
+	if !cfg.AutoProvision {
+		return nil, kperrors.New(kperrors.ErrAuthSSO, nil).WithMessage("该身份尚未关联系统账号，请先使用账号密码登录后关联")
+	}
+
+	user, err := s.provision(claims, email, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.createIdentity(user.ID, provider.Issuer(), claims.Subject(), email); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// requiresExplicitLink 检查用户是否只能登录后通过 /user/oidc/link 明确关联身份
+func requiresExplicitLink(user *model.User) (bool, error) {
+	if user.IsBuiltin {
+		return true, nil
+	}
+	return isSuperAdmin(user.ID)
+}
+
+// Link 校验关联回调并将身份关联到当前用户
+func (s *OIDCServiceImpl) Link(userID uint, req *dto.OIDCCallbackReq) error {
+	provider, claims, err := s.verifyCallback(req, userID)
+	if err != nil {
+		return err
+	}
+
+	identity, err := s.identityRepo.FindBySubject(provider.Issuer(), claims.Subject())
+	if err == nil {
+		if identity.UserID == userID {
+			return nil
+		}
+		return kperrors.New(kperrors.ErrAuthSSO, nil).WithMessage("该身份已关联其他账号")
+	}
+	if !kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+		return err
+	}
+
+	if _, err := s.identityRepo.FindByUserID(userID, provider.Issuer()); err == nil {
+		return kperrors.New(kperrors.ErrAuthSSO, nil).WithMessage("当前账号已关联其他身份，请先解除关联")
+	} else if !kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+		return err
+	}
+
+	return s.createIdentity(userID, provider.Issuer(), claims.Subject(), claims.String(oidcConfig().Claims.Email))
+}
+
+// Unlink 解除当前用户关联的身份
+func (s *OIDCServiceImpl) Unlink(userID uint) error {
+	provider, err := oidc.GetProvider()
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	// 自动创建的用户没有本地密码，解除关联后将无法登录
+	if user.Password == "" {
+		return kperrors.New(kperrors.ErrAuthSSO, nil).WithMessage("账号未设置密码，不能解除关联")
+	}
+
+	return s.identityRepo.Delete(userID, provider.Issuer())
+}
+
+// GetIdentity 获取当前用户的身份关联状态
+func (s *OIDCServiceImpl) GetIdentity(userID uint) (*dto.OIDCIdentityResp, error) {
+	if !config.GetOIDCConfig().Enable {
+		return &dto.OIDCIdentityResp{}, nil
+	}
+	provider, err := oidc.GetProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.OIDCIdentityResp{Enabled: true, Issuer: provider.Issuer()}
+	identity, err := s.identityRepo.FindByUserID(userID, provider.Issuer())
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return resp, nil
+		}
+		return nil, err
+	}
+
+	resp.Linked = true
+	resp.Email = identity.Email
+	resp.LastLoginAt = identity.LastLoginAt
+	resp.LinkedAt = &identity.CreatedAt
+	return resp, nil
+}
+
+// verifyCallback 校验回调的state，使用授权码换取ID token并验证
+// 授权请求只能使用一次，且必须由同一用户发起
+func (s *OIDCServiceImpl) verifyCallback(req *dto.OIDCCallbackReq, userID uint) (*oidc.Provider, oidc.Claims, error) {
+	provider, err := oidc.GetProvider()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	record, err := s.stateRepo.FindByState(req.State)
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return nil, nil, kperrors.New(kperrors.ErrAuthSSO, err).WithMessage("单点登录请求无效或已过期，请重新登录")
+		}
+		return nil, nil, err
+	}
+	if record.UsedAt != nil || record.IsExpired() || record.UserID != userID {
+		return nil, nil, kperrors.New(kperrors.ErrAuthSSO, nil).WithMessage("单点登录请求无效或已过期，请重新登录")
+	}
+
+	used, err := s.stateRepo.MarkUsed(record.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !used {
+		return nil, nil, kperrors.New(kperrors.ErrAuthSSO, nil).WithMessage("单点登录请求无效或已过期，请重新登录")
+	}
+
+	idToken, err := provider.Exchange(req.Code, record.CodeVerifier)
+	if err != nil {
+		logger.GetLogger().Warn("单点登录授权码换取令牌失败", zap.Error(err))
+		return nil, nil, kperrors.New(kperrors.ErrAuthSSO, err).WithMessage("身份提供方认证失败")
+	}
+
+	claims, err := provider.VerifyIDToken(idToken, record.Nonce)
+	if err != nil {
+		logger.GetLogger().Warn("单点登录ID token校验失败", zap.Error(err))
+		return nil, nil, kperrors.New(kperrors.ErrAuthSSO, err).WithMessage("身份令牌校验失败")
+	}
+
+	return provider, claims, nil
+}
+
+// provision 按声明映射自动创建用户，用户没有本地密码，只能通过单点登录登录
+func (s *OIDCServiceImpl) provision(claims oidc.Claims, email string, cfg config.OIDCConfig) (*model.User, error) {
+	username := claims.String(cfg.Claims.Username)
+	if matched, _ := regexp.MatchString(constants.UsernameRegex, username); !matched {
+		return nil, kperrors.New(kperrors.ErrAuthSSO, nil).WithMessage("身份提供方返回的用户名不符合要求，无法自动创建账号")
+	}
+
+	if _, err := s.userRepo.FindByUsername(username); err == nil {
+		return nil, kperrors.New(kperrors.ErrAuthSSO, nil).WithMessage("用户名已被使用，请使用该账号登录后关联身份")
+	} else if !kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+		return nil, err
+	}
+	if email != "" {
+		if _, err := s.userRepo.FindByEmail(email); err == nil {
+			return nil, kperrors.New(kperrors.ErrAuthSSO, nil).WithMessage("邮箱已被使用，请使用该账号登录后关联身份")
+		} else if !kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return nil, err
+		}
+	}
+
+	if err := checkRoleIDs(cfg.DefaultRoleIDs); err != nil {
+		return nil, err
+	}
+
+	appKey, appSecret := jwt.GenerateAppKeyAndSecret(username)
+	now := time.Now()
+	user := &model.User{
+		Username:          username,
+		Nickname:          claims.String(cfg.Claims.Nickname),
+		Email:             email,
+		DeptID:            cfg.DefaultDeptID,
+		Status:            1,
+		AppKey:            appKey,
+		AppSecret:         appSecret,
+		PasswordChangedAt: &now,
+		Remark:            "单点登录自动创建",
+	}
+	if err := s.userRepo.CreateWithRoles(user, cfg.DefaultRoleIDs); err != nil {
+		return nil, err
+	}
+
+	logger.GetLogger().Info("单点登录自动创建用户", zap.Uint("user_id", user.ID), zap.String("username", username))
+	return user, nil
+}
+
+// createIdentity 关联外部身份
+func (s *OIDCServiceImpl) createIdentity(userID uint, issuer, subject, email string) error {
+	now := time.Now()
+	return s.identityRepo.Create(&model.UserIdentity{
+		UserID:      userID,
+		Issuer:      issuer,
+		Subject:     subject,
+		Email:       email,
+		LastLoginAt: &now,
+	})
+}
+
+// CleanupExpiredStates 清理过期的授权请求记录
+func (s *OIDCServiceImpl) CleanupExpiredStates() error {
+	return s.stateRepo.CleanExpired(time.Now())
+}
+
+// oidcConfig 获取单点登录配置，未配置的项使用默认值
+func oidcConfig() config.OIDCConfig {
+	cfg := config.GetOIDCConfig()
+	if cfg.StateTTL <= 0 {
+		cfg.StateTTL = defaultOIDCStateTTL
+	}
+	if cfg.Claims.Username == "" {
+		cfg.Claims.Username = defaultOIDCUsernameClaim
+	}
+	if cfg.Claims.Email == "" {
+		cfg.Claims.Email = defaultOIDCEmailClaim
+	}
+	if cfg.Claims.Nickname == "" {
+		cfg.Claims.Nickname = defaultOIDCNicknameClaim
+	}
+	return cfg
+}
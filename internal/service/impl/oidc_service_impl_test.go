@@ -0,0 +1,32 @@
+package impl
+
+import (
+	"testing"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+)
+
+func TestRequiresExplicitLink(t *testing.T) {
+	resetTables(t)
+	mustCreate(t,
+		&model.User{ID: 1, Username: "admin", Password: "x", Email: "admin@example.com", Mobile: "13800000001", IsBuiltin: true},
+		&model.User{ID: 2, Username: "root", Password: "x", Email: "root@example.com", Mobile: "13800000002"},
+		&model.User{ID: 3, Username: "zhangsan", Password: "x", Email: "zhangsan@example.com", Mobile: "13800000003"},
+		&model.Role{ID: 1, Name: "超级管理员", Code: "admin", Status: 1},
+		&model.Role{ID: 2, Name: "普通用户", Code: "user", Status: 1},
+		&model.UserRole{UserID: 2, RoleID: 1},
+		&model.UserRole{UserID: 3, RoleID: 2},
+	)
+
+	for id, want := range map[uint]bool{1: true, 2: true, 3: false} {
+		var user model.User
+		mustFind(t, &user, id)
+		got, err := requiresExplicitLink(&user)
+		if err != nil {
+			t.Fatalf("requiresExplicitLink(%d) error: %v", id, err)
+		}
+		if got != want {
+			t.Errorf("requiresExplicitLink(%d) = %v, want %v", id, got, want)
+		}
+	}
+}
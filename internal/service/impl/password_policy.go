@@ -0,0 +1,67 @@
+package impl
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/repository"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/password"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// checkNewPassword 按密码策略检查用户的新密码，启用历史限制时不能与当前密码及最近使用过的密码相同
+func checkNewPassword(user *model.User, newPassword string) error {
+	policy := password.GetPolicy()
+	if err := policy.Validate(newPassword, user.Username); err != nil {
+		return err
+	}
+	if policy.HistoryCount <= 0 {
+		return nil
+	}
+
+	hashes := []string{user.Password}
+	histories, err := repository.GetPasswordHistoryRepository().FindRecent(user.ID, policy.HistoryCount)
+	if err != nil {
+		return err
+	}
+	for _, history := range histories {
+		hashes = append(hashes, history.Password)
+	}
+
+	for _, hash := range hashes {
+		if hash != "" && bcrypt.CompareHashAndPassword([]byte(hash), []byte(newPassword)) == nil {
+			return kperrors.New(kperrors.ErrUserPasswordReused, nil)
+		}
+	}
+	return nil
+}
+
+// recordPasswordHistory 记录用户设置的密码，只保留策略要求的条数
+func recordPasswordHistory(userID uint, hash string) error {
+	policy := password.GetPolicy()
+	if policy.HistoryCount <= 0 {
+		return nil
+	}
+
+	historyRepo := repository.GetPasswordHistoryRepository()
+	if err := historyRepo.Create(&model.PasswordHistory{UserID: userID, Password: hash}); err != nil {
+		return err
+	}
+	return historyRepo.DeleteExceptRecent(userID, policy.HistoryCount)
+}
+
+// passwordChangeRequired 判断用户是否必须先修改密码，管理员设置的密码及超过有效期的密码需要修改
+// 单点登录自动创建的用户没有本地密码，不需要修改
+func passwordChangeRequired(user *model.User) bool {
+	if user.Password == "" {
+		return false
+	}
+	if user.MustChangePassword {
+		return true
+	}
+
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		changedAt = *user.PasswordChangedAt
+	}
+	return password.GetPolicy().IsExpired(changedAt)
+}
@@ -0,0 +1,281 @@
+package impl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/i18n"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/cuiyuanxin/kunpeng/pkg/logger"
+	"github.com/cuiyuanxin/kunpeng/pkg/mail"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 默认配置
+const (
+	defaultResetTokenTTL  = 30 * time.Minute
+	defaultResetCooldown  = time.Minute
+	defaultResetWindow    = 24 * time.Hour
+	defaultResetUserLimit = 5
+)
+
+// PasswordResetServiceImpl 找回密码服务实现
+type PasswordResetServiceImpl struct {
+	resetRepo           repository.PasswordResetRepository
+	userRepo            repository.UserRepository
+	sessionService      service.SessionService
+	loginAttemptService service.LoginAttemptService
+}
+
+// NewPasswordResetService 创建找回密码服务实例
+func NewPasswordResetService(resetRepo repository.PasswordResetRepository, userRepo repository.UserRepository, sessionService service.SessionService, loginAttemptService service.LoginAttemptService) *PasswordResetServiceImpl {
+	return &PasswordResetServiceImpl{
+		resetRepo:           resetRepo,
+		userRepo:            userRepo,
+		sessionService:      sessionService,
+		loginAttemptService: loginAttemptService,
+	}
+}
+
+// Forgot 发送重置密码邮件
+func (s *PasswordResetServiceImpl) Forgot(req *dto.PasswordForgotReq, clientIP string, lang string) (*dto.PasswordForgotResp, error) {
+	cfg := passwordResetConfig()
+	now := time.Now()
+	account := strings.TrimSpace(req.Account)
+
+	// 检查同一账号的重发间隔
+	latest, err := s.resetRepo.FindLatest(account)
+	if err != nil && !kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+		return nil, err
+	}
+	if latest != nil {
+		if wait := latest.CreatedAt.Add(cfg.Cooldown).Sub(now); wait > 0 {
+			return nil, kperrors.New(kperrors.ErrUserResetFreq, nil).
+				WithMessage(fmt.Sprintf("重置密码邮件发送过于频繁，请%d秒后重试", int64(wait.Seconds())+1))
+		}
+	}
+
+	// 检查发送次数限制
+	if cfg.IPLimit > 0 {
+		count, err := s.resetRepo.CountByIP(clientIP, now.Add(-cfg.Window))
+		if err != nil {
+			return nil, err
+		}
+		if count >= int64(cfg.IPLimit) {
+			return nil, kperrors.New(kperrors.ErrUserResetFreq, nil).WithMessage("当前IP重置密码邮件发送次数已达上限")
+		}
+	}
+
+	record := &model.PasswordReset{
+		Account:   account,
+		IP:        clientIP,
+		ExpiresAt: now.Add(cfg.TokenTTL),
+	}
+
+	// 账号不存在、已禁用、未绑定邮箱或超出该用户的发送限制时不发送邮件，但返回相同结果，避免通过该接口探测账号是否存在
+	user := s.findUser(account)
+	if user != nil {
+		allowed, err := s.checkUserLimit(user.ID, cfg, now)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			logger.GetLogger().Warn("重置密码邮件发送超出用户限制", zap.Uint("user_id", user.ID), zap.String("ip", clientIP))
+			user = nil
+		}
+	}
+	var token string
+	if user != nil {
+		token, record.JTI, err = jwt.GenerateResetToken(user.ID, user.Username, user.TokenVersion, cfg.TokenTTL)
+		if err != nil {
+			return nil, err
+		}
+		record.UserID = user.ID
+	}
+
+	if err := s.resetRepo.Create(record); err != nil {
+		return nil, err
+	}
+
+	if user != nil {
+		msg := buildResetMail(user, token, cfg, lang)
+		// 异步发送，避免响应耗时暴露账号是否存在
+		go func() {
+			mailer, err := mail.GetMailer()
+			if err == nil {
+				err = mailer.Send(msg)
+			}
+			if err != nil {
+				logger.GetLogger().Error("发送重置密码邮件失败", zap.Uint("user_id", user.ID), zap.Error(err))
+			}
+		}()
+	}
+
+	return &dto.PasswordForgotResp{
+		ExpiresIn: int64(cfg.TokenTTL.Seconds()),
+		Cooldown:  int64(cfg.Cooldown.Seconds()),
+	}, nil
+}
+
+// Reset 校验重置凭证并设置新密码
+func (s *PasswordResetServiceImpl) Reset(req *dto.PasswordResetReq) error {
+	claims, err := jwt.ParseToken(req.Token)
+	if err != nil {
+		return kperrors.New(kperrors.ErrUserResetToken, err)
+	}
+	if claims.TokenType != jwt.ResetTokenType {
+		return kperrors.New(kperrors.ErrUserResetToken, nil)
+	}
+
+	record, err := s.resetRepo.FindByJTI(claims.ID)
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return kperrors.New(kperrors.ErrUserResetToken, err)
+		}
+		return err
+	}
+	if record.UsedAt != nil || time.Now().After(record.ExpiresAt) || record.UserID != claims.UserID {
+		return kperrors.New(kperrors.ErrUserResetToken, nil)
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return kperrors.New(kperrors.ErrUserResetToken, err)
+		}
+		return err
+	}
+	if user.Status != 1 {
+		return kperrors.New(kperrors.ErrUserDisabled, nil)
+	}
+	// 签发后用户修改过密码或被强制下线时，重置链接随之失效
+	if claims.Version != user.TokenVersion {
+		return kperrors.New(kperrors.ErrUserResetToken, nil)
+	}
+
+	// 检查新密码是否符合密码策略且未被使用过
+	if err := checkNewPassword(user, req.NewPassword); err != nil {
+		return err
+	}
+
+	// 标记为已使用，并发请求时只有一个能成功
+	used, err := s.resetRepo.MarkUsed(record.ID)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return kperrors.New(kperrors.ErrUserResetToken, nil)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return kperrors.New(kperrors.ErrSystem, err)
+	}
+	if err := s.userRepo.UpdatePassword(user.ID, string(hashedPassword)); err != nil {
+		return err
+	}
+	if err := recordPasswordHistory(user.ID, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	// 重置密码后解除账号的登录锁定
+	if err := s.loginAttemptService.ResetAttempts(user.Username); err != nil {
+		logger.GetLogger().Error("重置登录尝试记录失败", zap.String("username", user.Username), zap.Error(err))
+	}
+
+	// 递增token版本号，使其他重置链接及全部登录失效
+	return s.sessionService.RevokeUserTokens(user.ID, "用户通过邮件重置密码")
+}
+
+// checkUserLimit 按用户检查重发间隔及统计周期内的发送次数，同一用户使用用户名或邮箱请求时共用限制
+func (s *PasswordResetServiceImpl) checkUserLimit(userID uint, cfg config.PasswordResetConfig, now time.Time) (bool, error) {
+	latest, err := s.resetRepo.FindLatestByUserID(userID)
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+	if now.Before(latest.CreatedAt.Add(cfg.Cooldown)) {
+		return false, nil
+	}
+
+	count, err := s.resetRepo.CountByUserID(userID, now.Add(-cfg.Window))
+	if err != nil {
+		return false, err
+	}
+	return count < int64(cfg.UserLimit), nil
+}
+
+// findUser 根据用户名或邮箱查找可以接收重置邮件的用户，不满足条件时返回nil
+func (s *PasswordResetServiceImpl) findUser(account string) *model.User {
+	var user *model.User
+	var err error
+	if strings.Contains(account, "@") {
+		user, err = s.userRepo.FindByEmail(account)
+	} else {
+		user, err = s.userRepo.FindByUsername(account)
+	}
+	if err != nil {
+		if !kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			logger.GetLogger().Error("查询找回密码用户失败", zap.String("account", account), zap.Error(err))
+		}
+		return nil
+	}
+	if user.Status != 1 || user.Email == "" {
+		return nil
+	}
+	return user
+}
+
+// buildResetMail 按请求语言生成重置密码邮件
+func buildResetMail(user *model.User, token string, cfg config.PasswordResetConfig, lang string) *mail.Message {
+	link := token
+	if cfg.ResetURL != "" {
+		link = strings.ReplaceAll(cfg.ResetURL, "{token}", url.QueryEscape(token))
+	}
+
+	data := map[string]interface{}{
+		"AppName":  config.GetAppConfig().Name,
+		"Username": user.Username,
+		"Minutes":  int64(cfg.TokenTTL.Minutes()),
+		"Link":     link,
+	}
+	return &mail.Message{
+		To:      []string{user.Email},
+		Subject: i18n.TWithLanguage(lang, "mail.password_reset.subject", data),
+		Body:    i18n.TWithLanguage(lang, "mail.password_reset.body", data),
+	}
+}
+
+// CleanupExpiredTokens 清理过期的重置密码记录，统计发送次数所需的记录保留到统计时间窗口结束
+func (s *PasswordResetServiceImpl) CleanupExpiredTokens() error {
+	return s.resetRepo.CleanExpired(time.Now().Add(-passwordResetConfig().Window))
+}
+
+// passwordResetConfig 获取找回密码配置，未配置的项使用默认值
+func passwordResetConfig() config.PasswordResetConfig {
+	cfg := config.GetPasswordResetConfig()
+	if cfg.TokenTTL <= 0 {
+		cfg.TokenTTL = defaultResetTokenTTL
+	}
+	if cfg.Cooldown < 0 {
+		cfg.Cooldown = defaultResetCooldown
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultResetWindow
+	}
+	if cfg.UserLimit <= 0 {
+		cfg.UserLimit = defaultResetUserLimit
+	}
+	return cfg
+}
@@ -0,0 +1,138 @@
+package impl
+
+import (
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/repository"
+	"github.com/cuiyuanxin/kunpeng/pkg/casbin"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+)
+
+// 权限管理接口，权限变更后操作者必须仍可访问该接口
+const (
+	permManageMethod = "PUT"
+	permManagePath   = "/api/v1/roles/apis"
+)
+
+// permissionChange 待检查的权限变更，描述变更后的状态，未包含的部分保持不变
+type permissionChange struct {
+	userRoleIDs map[uint][]uint     // 变更后用户的角色ID
+	roleStatus  map[uint]int8       // 变更后角色的状态
+	roleParent  map[uint]uint       // 变更后角色的上级角色
+	roleAPIs    map[uint][]uint     // 变更后角色的API
+	apis        map[uint]*model.API // 变更后的API，为nil表示被删除
+}
+
+// checkSelfLockout 检查权限变更是否会导致操作者失去权限管理能力
+// 仅当操作者变更前可以管理权限、变更后不能管理时拒绝
+func checkSelfLockout(operatorID uint, change *permissionChange) error {
+	if operatorID == 0 || !config.GetCasbinConfig().Enable {
+		return nil
+	}
+
+	before, err := canManagePermissions(operatorID, &permissionChange{})
+	if err != nil || !before {
+		return err
+	}
+
+	after, err := canManagePermissions(operatorID, change)
+	if err != nil {
+		return err
+	}
+	if !after {
+		return kperrors.New(kperrors.ErrPermSelfLockout, nil)
+	}
+	return nil
+}
+
+// canManagePermissions 按变更后的状态判断用户是否可以管理权限，判定规则与Casbin权限中间件一致
+func canManagePermissions(userID uint, change *permissionChange) (bool, error) {
+	manageAPIs, err := findPermManageAPIIDs(change.apis)
+	if err != nil {
+		return false, err
+	}
+
+	roleIDs, ok := change.userRoleIDs[userID]
+	if !ok {
+		roleIDs, err = repository.GetUserRepository().FindRoleIDs(userID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	superAdmin := config.GetCasbinConfig().SuperAdmin
+	visited := make(map[uint]bool)
+	for len(roleIDs) > 0 {
+		roleID := roleIDs[0]
+		roleIDs = roleIDs[1:]
+		if roleID == 0 || visited[roleID] {
+			continue
+		}
+		visited[roleID] = true
+
+		role, err := repository.GetRoleRepository().FindByID(roleID)
+		if err != nil {
+			// 角色不存在时跳过
+			continue
+		}
+
+		// 禁用的角色不授予权限，也不再继承上级角色的权限
+		status, ok := change.roleStatus[roleID]
+		if !ok {
+			status = role.Status
+		}
+		if status != 1 {
+			continue
+		}
+
+		if superAdmin != "" && role.Code == superAdmin {
+			return true, nil
+		}
+
+		apiIDs, ok := change.roleAPIs[roleID]
+		if !ok {
+			apiIDs, err = repository.GetRoleRepository().GetRoleAPIs(roleID)
+			if err != nil {
+				return false, err
+			}
+		}
+		for _, apiID := range apiIDs {
+			if manageAPIs[apiID] {
+				return true, nil
+			}
+		}
+
+		parentID, ok := change.roleParent[roleID]
+		if !ok {
+			parentID = role.ParentID
+		}
+		roleIDs = append(roleIDs, parentID)
+	}
+
+	return false, nil
+}
+
+// findPermManageAPIIDs 按变更后的API查询匹配权限管理接口的启用状态的API
+func findPermManageAPIIDs(changed map[uint]*model.API) (map[uint]bool, error) {
+	apis, err := repository.GetAPIRepository().FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[uint]bool)
+	for _, api := range apis {
+		if changedAPI, ok := changed[api.ID]; ok {
+			api = changedAPI
+		}
+		if api == nil || api.Status != 1 {
+			continue
+		}
+		if api.Method != permManageMethod && api.Method != "*" {
+			continue
+		}
+		if api.Path == permManagePath || casbin.KeyMatch2(permManagePath, api.Path) {
+			ids[api.ID] = true
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,134 @@
+package impl
+
+import (
+	"testing"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/pkg/database"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+)
+
+// 权限测试数据的ID
+const (
+	guardOperatorID  = 10
+	guardOtherUserID = 11
+	guardOpsRoleID   = 20
+	guardBaseRoleID  = 21
+	guardViewRoleID  = 22
+	guardManageAPIID = 30
+	guardListAPIID   = 31
+)
+
+// setupGuardData 创建非超级管理员的操作者：角色ops继承base，权限管理接口授予base，ops仅有查询接口
+func setupGuardData(t *testing.T) {
+	t.Helper()
+	resetTables(t)
+
+	mustCreate(t,
+		&model.User{ID: guardOperatorID, Username: "operator", Password: "x", Email: "operator@example.com", Mobile: "13800000010"},
+		&model.User{ID: guardOtherUserID, Username: "viewer", Password: "x", Email: "viewer@example.com", Mobile: "13800000011"},
+		&model.Role{ID: guardBaseRoleID, Name: "权限管理", Code: "perm-manager", Status: 1},
+		&model.Role{ID: guardOpsRoleID, ParentID: guardBaseRoleID, Name: "运维", Code: "ops", Status: 1},
+		&model.Role{ID: guardViewRoleID, Name: "只读", Code: "viewer", Status: 1},
+		&model.UserRole{UserID: guardOperatorID, RoleID: guardOpsRoleID},
+		&model.UserRole{UserID: guardOtherUserID, RoleID: guardViewRoleID},
+		&model.API{ID: guardManageAPIID, Group: "角色管理", Name: "更新角色API", Method: "PUT", Path: permManagePath, Status: 1},
+		&model.API{ID: guardListAPIID, Group: "角色管理", Name: "获取角色列表", Method: "GET", Path: "/api/v1/roles", Status: 1},
+		&model.RoleAPI{RoleID: guardBaseRoleID, APIID: guardManageAPIID},
+		&model.RoleAPI{RoleID: guardOpsRoleID, APIID: guardListAPIID},
+		&model.RoleAPI{RoleID: guardViewRoleID, APIID: guardListAPIID},
+	)
+}
+
+func TestCanManagePermissionsThroughParentRole(t *testing.T) {
+	setupGuardData(t)
+
+	ok, err := canManagePermissions(guardOperatorID, &permissionChange{})
+	if err != nil {
+		t.Fatalf("canManagePermissions error: %v", err)
+	}
+	if !ok {
+		t.Fatalf("操作者通过上级角色拥有权限管理接口，应可以管理权限")
+	}
+
+	ok, err = canManagePermissions(guardOtherUserID, &permissionChange{})
+	if err != nil {
+		t.Fatalf("canManagePermissions error: %v", err)
+	}
+	if ok {
+		t.Fatalf("只读用户不应可以管理权限")
+	}
+}
+
+func TestCheckSelfLockout(t *testing.T) {
+	tests := []struct {
+		name       string
+		operatorID uint
+		change     *permissionChange
+		lockout    bool
+	}{
+		{"no change", guardOperatorID, &permissionChange{}, false},
+		{"unrelated role change", guardOperatorID, &permissionChange{roleAPIs: map[uint][]uint{guardViewRoleID: nil}}, false},
+		{"add role keeps access", guardOperatorID, &permissionChange{userRoleIDs: map[uint][]uint{guardOperatorID: {guardOpsRoleID, guardViewRoleID}}}, false},
+		{"revoke api from parent", guardOperatorID, &permissionChange{roleAPIs: map[uint][]uint{guardBaseRoleID: {guardListAPIID}}}, true},
+		{"detach parent role", guardOperatorID, &permissionChange{roleParent: map[uint]uint{guardOpsRoleID: 0}}, true},
+		{"disable own role", guardOperatorID, &permissionChange{roleStatus: map[uint]int8{guardOpsRoleID: 0}}, true},
+		{"remove own role", guardOperatorID, &permissionChange{userRoleIDs: map[uint][]uint{guardOperatorID: {guardViewRoleID}}}, true},
+		{"disable manage api", guardOperatorID, &permissionChange{apis: map[uint]*model.API{guardManageAPIID: {ID: guardManageAPIID, Method: "PUT", Path: permManagePath, Status: 0}}}, true},
+		{"delete manage api", guardOperatorID, &permissionChange{apis: map[uint]*model.API{guardManageAPIID: nil}}, true},
+		{"operator without access", guardOtherUserID, &permissionChange{roleAPIs: map[uint][]uint{guardViewRoleID: nil}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupGuardData(t)
+
+			err := checkSelfLockout(tt.operatorID, tt.change)
+			if tt.lockout {
+				if !kperrors.IsCode(err, kperrors.ErrPermSelfLockout) {
+					t.Fatalf("checkSelfLockout error = %v, want ErrPermSelfLockout", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkSelfLockout error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestCheckSelfLockoutDisabledRoleInDB(t *testing.T) {
+	setupGuardData(t)
+	if err := database.GetDB().Model(&model.Role{}).Where("id = ?", guardBaseRoleID).Update("status", 0).Error; err != nil {
+		t.Fatalf("禁用角色失败: %v", err)
+	}
+
+	// 变更前已不能管理权限，不做限制；重新启用上级角色后可以管理
+	if err := checkSelfLockout(guardOperatorID, &permissionChange{roleAPIs: map[uint][]uint{guardOpsRoleID: nil}}); err != nil {
+		t.Fatalf("checkSelfLockout error = %v, want nil", err)
+	}
+	ok, err := canManagePermissions(guardOperatorID, &permissionChange{roleStatus: map[uint]int8{guardBaseRoleID: 1}})
+	if err != nil || !ok {
+		t.Fatalf("canManagePermissions = %v, %v, want true", ok, err)
+	}
+}
+
+func TestGetRoleAPIsAndMenus(t *testing.T) {
+	setupGuardData(t)
+	mustCreate(t, &model.RoleMenu{RoleID: guardOpsRoleID, MenuID: 5})
+
+	apiIDs, err := (&RoleServiceImpl{}).GetRoleAPIs(guardBaseRoleID)
+	if err != nil {
+		t.Fatalf("GetRoleAPIs error: %v", err)
+	}
+	if len(apiIDs.APIIDs) != 1 || apiIDs.APIIDs[0] != guardManageAPIID {
+		t.Errorf("GetRoleAPIs = %v", apiIDs.APIIDs)
+	}
+
+	menuIDs, err := (&RoleServiceImpl{}).GetRoleMenus(guardOpsRoleID)
+	if err != nil {
+		t.Fatalf("GetRoleMenus error: %v", err)
+	}
+	if len(menuIDs.MenuIDs) != 1 || menuIDs.MenuIDs[0] != 5 {
+		t.Errorf("GetRoleMenus = %v", menuIDs.MenuIDs)
+	}
+}
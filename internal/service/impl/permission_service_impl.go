@@ -0,0 +1,157 @@
+package impl
+
+import (
+	"strings"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/repository"
+	"github.com/cuiyuanxin/kunpeng/pkg/casbin"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+)
+
+// PermissionServiceImpl 权限服务实现
+type PermissionServiceImpl struct {
+	roleService service.RoleService
+}
+
+// NewPermissionService 创建权限服务
+func NewPermissionService(roleService service.RoleService) *PermissionServiceImpl {
+	return &PermissionServiceImpl{
+		roleService: roleService,
+	}
+}
+
+// Explain 说明用户或角色访问指定接口的权限判定过程，判定顺序与Casbin权限中间件一致
+func (s *PermissionServiceImpl) Explain(req *dto.PermissionExplainReq) (*dto.PermissionExplainResp, error) {
+	casbinConfig := config.GetCasbinConfig()
+	resp := &dto.PermissionExplainResp{
+		Method:        strings.ToUpper(req.Method),
+		Path:          req.Path,
+		CasbinEnabled: casbinConfig.Enable,
+		MatchedPolicy: []string{},
+		RoleChain:     []string{},
+	}
+
+	// 查找匹配的API记录
+	api, err := s.matchAPI(resp.Method, resp.Path)
+	if err != nil {
+		return nil, err
+	}
+	resp.API = api
+
+	// 确定Casbin主体及其有效角色
+	var roles []*model.Role
+	if req.UserID != 0 {
+		if _, err := repository.GetUserRepository().FindByID(req.UserID); err != nil {
+			return nil, err
+		}
+
+		resp.Subject = casbin.UserSubject(req.UserID)
+		roles, err = repository.GetRoleRepository().FindEffectiveByUserID(req.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.DataScope, err = s.roleService.GetDataScope(req.UserID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		role, err := repository.GetRoleRepository().FindByID(req.RoleID)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Subject = role.Code
+		if role.Status == 1 {
+			roles = append(roles, role)
+		}
+
+		resp.RoleDataScope, err = s.roleService.GetRoleDataScope(role.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 角色链以Casbin中实际生效的分组为准
+	chain, err := casbin.GetImplicitRolesForUser(resp.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID == 0 && len(roles) > 0 {
+		chain = append([]string{resp.Subject}, chain...)
+	}
+	resp.RoleChain = append(resp.RoleChain, chain...)
+
+	// 角色及其继承的上级角色中包含超级管理员角色时跳过权限校验
+	for _, code := range resp.RoleChain {
+		if casbinConfig.SuperAdmin != "" && code == casbinConfig.SuperAdmin {
+			resp.SuperAdmin = true
+		}
+	}
+
+	switch {
+	case !casbinConfig.Enable:
+		resp.Allowed = true
+		resp.Reason = "未启用权限校验，允许访问"
+		return resp, nil
+	case len(roles) == 0:
+		resp.Reason = "没有启用状态的角色，拒绝访问"
+		return resp, nil
+	case resp.SuperAdmin:
+		resp.Allowed = true
+		resp.Reason = "超级管理员角色跳过权限校验，允许访问"
+		return resp, nil
+	}
+
+	allowed, explain, err := casbin.EnforceEx(resp.Subject, resp.Path, resp.Method)
+	if err != nil {
+		return nil, err
+	}
+	resp.Allowed = allowed
+	if len(explain) > 0 {
+		resp.MatchedPolicy = explain
+	}
+
+	switch {
+	case allowed:
+		resp.Reason = "命中角色策略，允许访问"
+	case api == nil:
+		resp.Reason = "API未登记，没有可命中的策略，拒绝访问"
+	case api.Disabled:
+		resp.Reason = "API已禁用，不会同步到权限策略，拒绝访问"
+	default:
+		resp.Reason = "角色未授权该API，拒绝访问"
+	}
+
+	return resp, nil
+}
+
+// matchAPI 按请求方法和路径查找匹配的API记录，路径匹配规则与Casbin模型一致
+func (s *PermissionServiceImpl) matchAPI(method, path string) (*dto.PermissionAPIInfo, error) {
+	apis, err := repository.GetAPIRepository().FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, api := range apis {
+		if api.Method != method && api.Method != "*" {
+			continue
+		}
+		if api.Path != path && !casbin.KeyMatch2(path, api.Path) {
+			continue
+		}
+		return &dto.PermissionAPIInfo{
+			ID:       api.ID,
+			Name:     api.Name,
+			Method:   api.Method,
+			Path:     api.Path,
+			Disabled: api.Status != 1,
+			Stale:    api.Stale,
+		}, nil
+	}
+
+	return nil, nil
+}
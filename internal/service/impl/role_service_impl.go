@@ -4,6 +4,7 @@ import (
 	"github.com/cuiyuanxin/kunpeng/internal/model"
 	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
 	"github.com/cuiyuanxin/kunpeng/internal/repository"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
 	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
 )
 
@@ -33,6 +34,11 @@ func (s *RoleServiceImpl) GetRoleList(req *dto.RolePageReq) (*dto.PageResp, erro
 	}, nil
 }
 
+// GetRoleTree 获取角色树
+func (s *RoleServiceImpl) GetRoleTree() ([]*dto.RoleTreeResp, error) {
+	return repository.GetRoleRepository().FindTree()
+}
+
 // GetAllRoles 获取所有角色
 func (s *RoleServiceImpl) GetAllRoles() ([]*model.Role, error) {
 	return repository.GetRoleRepository().FindAll()
@@ -45,13 +51,20 @@ func (s *RoleServiceImpl) GetRoleByID(id uint) (*model.Role, error) {
 
 // CreateRole 创建角色
 func (s *RoleServiceImpl) CreateRole(req *dto.RoleCreateReq) (uint, error) {
+	// 检查上级角色
+	if err := checkRoleParent(0, req.ParentID); err != nil {
+		return 0, err
+	}
+
 	// 创建角色
 	role := model.Role{
-		Name:   req.Name,
-		Code:   req.Code,
-		Sort:   req.Sort,
-		Status: req.Status,
-		Remark: req.Remark,
+		ParentID:         req.ParentID,
+		Name:             req.Name,
+		Code:             req.Code,
+		Sort:             req.Sort,
+		Status:           req.Status,
+		Remark:           req.Remark,
+		RequireTwoFactor: req.RequireTwoFactor,
 	}
 
 	err := repository.GetRoleRepository().Create(&role)
@@ -63,19 +76,40 @@ func (s *RoleServiceImpl) CreateRole(req *dto.RoleCreateReq) (uint, error) {
 }
 
 // UpdateRole 更新角色
-func (s *RoleServiceImpl) UpdateRole(req *dto.RoleUpdateReq) error {
+func (s *RoleServiceImpl) UpdateRole(operatorID uint, req *dto.RoleUpdateReq) error {
 	// 检查角色是否存在
 	role, err := repository.GetRoleRepository().FindByID(req.ID)
 	if err != nil {
 		return err
 	}
 
+	// 内置角色不允许禁用或修改编码
+	if role.IsBuiltin && (req.Code != role.Code || req.Status != 1) {
+		return kperrors.New(kperrors.ErrRoleBuiltin, nil)
+	}
+
+	// 检查上级角色，避免形成循环继承
+	if err := checkRoleParent(role.ID, req.ParentID); err != nil {
+		return err
+	}
+
+	// 检查是否会导致操作者失去权限管理能力
+	err = checkSelfLockout(operatorID, &permissionChange{
+		roleStatus: map[uint]int8{role.ID: req.Status},
+		roleParent: map[uint]uint{role.ID: req.ParentID},
+	})
+	if err != nil {
+		return err
+	}
+
 	// 更新角色信息
+	role.ParentID = req.ParentID
 	role.Name = req.Name
 	role.Code = req.Code
 	role.Sort = req.Sort
 	role.Status = req.Status
 	role.Remark = req.Remark
+	role.RequireTwoFactor = req.RequireTwoFactor
 
 	return repository.GetRoleRepository().Update(role)
 }
@@ -83,23 +117,48 @@ func (s *RoleServiceImpl) UpdateRole(req *dto.RoleUpdateReq) error {
 // DeleteRole 删除角色
 func (s *RoleServiceImpl) DeleteRole(id uint) error {
 	// 检查角色是否存在
-	_, err := repository.GetRoleRepository().FindByID(id)
+	role, err := repository.GetRoleRepository().FindByID(id)
 	if err != nil {
 		return err
 	}
 
+	// 内置角色不允许删除
+	if role.IsBuiltin {
+		return kperrors.New(kperrors.ErrRoleBuiltin, nil)
+	}
+
 	// 检查角色是否有关联用户
 	err = repository.GetUserRepository().FindByRoleID(id)
 	if err == nil {
 		return kperrors.New(kperrors.ErrRoleHasUsers, nil)
 	}
 
+	// 检查角色是否有子角色
+	hasChildren, err := repository.GetRoleRepository().HasChildren(id)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return kperrors.New(kperrors.ErrRoleHasChildren, nil)
+	}
+
 	// 删除角色
 	return repository.GetRoleRepository().Delete(id)
 }
 
 // BatchDeleteRole 批量删除角色
 func (s *RoleServiceImpl) BatchDeleteRole(ids []uint) error {
+	// 内置角色不允许删除
+	roles, err := repository.GetRoleRepository().FindByIDs(ids)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if role.IsBuiltin {
+			return kperrors.New(kperrors.ErrRoleBuiltin, nil)
+		}
+	}
+
 	// 检查角色是否有关联用户
 	for _, id := range ids {
 		err := repository.GetUserRepository().FindByRoleID(id)
@@ -108,12 +167,42 @@ func (s *RoleServiceImpl) BatchDeleteRole(ids []uint) error {
 		}
 	}
 
+	// 检查角色是否有子角色
+	for _, id := range ids {
+		hasChildren, err := repository.GetRoleRepository().HasChildren(id)
+		if err != nil {
+			return err
+		}
+		if hasChildren {
+			return kperrors.New(kperrors.ErrRoleHasChildren, nil)
+		}
+	}
+
 	// 删除角色
 	return repository.GetRoleRepository().BatchDelete(ids)
 }
 
 // ChangeRoleStatus 修改角色状态
-func (s *RoleServiceImpl) ChangeRoleStatus(req *dto.StatusReq) error {
+func (s *RoleServiceImpl) ChangeRoleStatus(operatorID uint, req *dto.StatusReq) error {
+	// 检查角色是否存在
+	role, err := repository.GetRoleRepository().FindByID(req.ID)
+	if err != nil {
+		return err
+	}
+
+	// 内置角色不允许禁用
+	if role.IsBuiltin && req.Status != 1 {
+		return kperrors.New(kperrors.ErrRoleBuiltin, nil)
+	}
+
+	// 检查是否会导致操作者失去权限管理能力
+	err = checkSelfLockout(operatorID, &permissionChange{
+		roleStatus: map[uint]int8{role.ID: req.Status},
+	})
+	if err != nil {
+		return err
+	}
+
 	return repository.GetRoleRepository().UpdateStatus(req.ID, int(req.Status))
 }
 
@@ -168,13 +257,199 @@ func (s *RoleServiceImpl) GetRoleAPIs(roleID uint) (*dto.APIRoleResp, error) {
 }
 
 // UpdateRoleAPIs 更新角色API
-func (s *RoleServiceImpl) UpdateRoleAPIs(req *dto.RoleAPIReq) error {
+func (s *RoleServiceImpl) UpdateRoleAPIs(operatorID uint, req *dto.RoleAPIReq) error {
 	// 检查角色是否存在
 	_, err := repository.GetRoleRepository().FindByID(req.RoleID)
 	if err != nil {
 		return err
 	}
 
+	// 检查是否会导致操作者失去权限管理能力
+	err = checkSelfLockout(operatorID, &permissionChange{
+		roleAPIs: map[uint][]uint{req.RoleID: req.APIIDs},
+	})
+	if err != nil {
+		return err
+	}
+
 	// 更新角色API
 	return repository.GetRoleRepository().UpdateRoleAPIs(req.RoleID, req.APIIDs)
 }
+
+// RebuildPolicies 重建权限策略
+func (s *RoleServiceImpl) RebuildPolicies() error {
+	return repository.GetRoleRepository().RebuildPolicies()
+}
+
+// GetUserRoles 获取用户的所有角色
+func (s *RoleServiceImpl) GetUserRoles(userID uint) ([]*model.Role, error) {
+	return repository.GetRoleRepository().FindByUserID(userID)
+}
+
+// GetEffectiveRoles 获取用户的有效角色，包括继承的上级角色
+func (s *RoleServiceImpl) GetEffectiveRoles(userID uint) ([]*model.Role, error) {
+	return repository.GetRoleRepository().FindEffectiveByUserID(userID)
+}
+
+// GetRoleDataScope 获取角色数据权限
+func (s *RoleServiceImpl) GetRoleDataScope(roleID uint) (*dto.RoleDataScopeResp, error) {
+	// 检查角色是否存在
+	role, err := repository.GetRoleRepository().FindByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取自定义数据权限部门
+	deptIDs, err := repository.GetRoleRepository().GetRoleDepts(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RoleDataScopeResp{
+		DataScope: role.DataScope,
+		DeptIDs:   deptIDs,
+	}, nil
+}
+
+// UpdateRoleDataScope 更新角色数据权限
+func (s *RoleServiceImpl) UpdateRoleDataScope(req *dto.RoleDataScopeReq) error {
+	// 检查角色是否存在
+	_, err := repository.GetRoleRepository().FindByID(req.RoleID)
+	if err != nil {
+		return err
+	}
+
+	// 检查自定义部门是否存在
+	if req.DataScope == model.DataScopeCustom {
+		for _, deptID := range req.DeptIDs {
+			if _, err := repository.GetDeptRepository().FindByID(deptID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return repository.GetRoleRepository().UpdateDataScope(req.RoleID, req.DataScope, req.DeptIDs)
+}
+
+// GetDataScope 合并用户有效角色的数据权限，计算用户可访问的数据范围
+// 多个角色取并集，任一角色为全部数据权限或用户为超级管理员时不限制；没有有效角色时仅可访问本人数据
+func (s *RoleServiceImpl) GetDataScope(userID uint) (*dto.DataScope, error) {
+	roles, err := repository.GetRoleRepository().FindEffectiveByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := &dto.DataScope{UserID: userID}
+	if len(roles) == 0 {
+		scope.Self = true
+		return scope, nil
+	}
+
+	superAdmin := config.GetCasbinConfig().SuperAdmin
+	deptSet := make(map[uint]struct{})
+	addDepts := func(ids ...uint) {
+		for _, id := range ids {
+			deptSet[id] = struct{}{}
+		}
+	}
+
+	var user *model.User
+	getUser := func() (*model.User, error) {
+		if user == nil {
+			u, err := repository.GetUserRepository().FindByID(userID)
+			if err != nil {
+				return nil, err
+			}
+			user = u
+		}
+		return user, nil
+	}
+
+	for _, role := range roles {
+		if superAdmin != "" && role.Code == superAdmin {
+			scope.All = true
+			return scope, nil
+		}
+
+		switch role.DataScope {
+		case model.DataScopeAll:
+			scope.All = true
+			return scope, nil
+		case model.DataScopeCustom:
+			deptIDs, err := repository.GetRoleRepository().GetRoleDepts(role.ID)
+			if err != nil {
+				return nil, err
+			}
+			addDepts(deptIDs...)
+		case model.DataScopeDept:
+			u, err := getUser()
+			if err != nil {
+				return nil, err
+			}
+			if u.DeptID != 0 {
+				addDepts(u.DeptID)
+			}
+		case model.DataScopeDeptAndChild:
+			u, err := getUser()
+			if err != nil {
+				return nil, err
+			}
+			if u.DeptID != 0 {
+				deptIDs, err := repository.GetDeptRepository().FindChildIDs(u.DeptID)
+				if err != nil {
+					return nil, err
+				}
+				addDepts(deptIDs...)
+			}
+		default:
+			scope.Self = true
+		}
+	}
+
+	scope.DeptIDs = make([]uint, 0, len(deptSet))
+	for id := range deptSet {
+		scope.DeptIDs = append(scope.DeptIDs, id)
+	}
+
+	return scope, nil
+}
+
+// checkRoleParent 检查上级角色是否存在，且不是角色自身、其下级角色或超级管理员角色
+func checkRoleParent(roleID, parentID uint) error {
+	if parentID == 0 {
+		return nil
+	}
+	if parentID == roleID {
+		return kperrors.New(kperrors.ErrRoleParentInvalid, nil).WithMessage("上级角色不能是自身")
+	}
+
+	// 沿上级角色向上查找，若经过当前角色则说明形成了循环
+	visited := make(map[uint]struct{})
+	for id := parentID; id != 0; {
+		if _, ok := visited[id]; ok {
+			break
+		}
+		visited[id] = struct{}{}
+
+		roles, err := repository.GetRoleRepository().FindByIDs([]uint{id})
+		if err != nil {
+			return err
+		}
+		if len(roles) == 0 {
+			if id == parentID {
+				return kperrors.New(kperrors.ErrRoleParentInvalid, nil).WithMessage("上级角色不存在")
+			}
+			break
+		}
+		// 下级角色会继承上级角色的全部权限，超级管理员角色不能作为上级角色
+		if superAdmin := config.GetCasbinConfig().SuperAdmin; superAdmin != "" && roles[0].Code == superAdmin {
+			return kperrors.New(kperrors.ErrRoleParentInvalid, nil).WithMessage("上级角色不能是超级管理员角色")
+		}
+		if roleID != 0 && roles[0].ParentID == roleID {
+			return kperrors.New(kperrors.ErrRoleParentInvalid, nil).WithMessage("上级角色不能是自身的下级角色")
+		}
+		id = roles[0].ParentID
+	}
+
+	return nil
+}
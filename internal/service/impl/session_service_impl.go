@@ -0,0 +1,312 @@
+package impl
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/cuiyuanxin/kunpeng/pkg/logger"
+	"github.com/cuiyuanxin/kunpeng/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// sessionTouchInterval 同一会话更新最近活跃时间的最小间隔，避免每个请求都写数据库
+const sessionTouchInterval = time.Minute
+
+// userAgentMaxLength User-Agent最大保存长度
+const userAgentMaxLength = 500
+
+// defaultTokenVersionCacheTTL 用户token版本号默认缓存时间
+const defaultTokenVersionCacheTTL = 10 * time.Second
+
+// tokenVersionEntry token版本号缓存条目
+type tokenVersionEntry struct {
+	version  uint
+	expireAt time.Time
+}
+
+// sessionTouch 会话最近一次写入的活跃时间及访问IP
+type sessionTouch struct {
+	ip string
+	at time.Time
+}
+
+// SessionServiceImpl 用户登录会话服务实现
+type SessionServiceImpl struct {
+	sessionRepo           repository.UserSessionRepository
+	refreshTokenRepo      repository.RefreshTokenRepository
+	userRepo              repository.UserRepository
+	tokenBlacklistService service.TokenBlacklistService
+
+	// 用户token版本号缓存，本实例递增版本号时立即更新
+	versionMu        sync.RWMutex
+	versions         map[uint]tokenVersionEntry
+	versionLastSweep time.Time
+
+	// 会话最近活跃时间的写入记录，用于控制写数据库的频率
+	touchMu        sync.Mutex
+	touches        map[string]sessionTouch
+	touchLastSweep time.Time
+}
+
+// NewSessionService 创建用户登录会话服务实例
+func NewSessionService(
+	sessionRepo repository.UserSessionRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	userRepo repository.UserRepository,
+	tokenBlacklistService service.TokenBlacklistService,
+) *SessionServiceImpl {
+	return &SessionServiceImpl{
+		sessionRepo:           sessionRepo,
+		refreshTokenRepo:      refreshTokenRepo,
+		userRepo:              userRepo,
+		tokenBlacklistService: tokenBlacklistService,
+		versions:              make(map[uint]tokenVersionEntry),
+		versionLastSweep:      time.Now(),
+		touches:               make(map[string]sessionTouch),
+		touchLastSweep:        time.Now(),
+	}
+}
+
+// CreateSession 登录成功后创建会话
+func (s *SessionServiceImpl) CreateSession(userID uint, username string, tokenPair *jwt.TokenPair, clientIP string, userAgent string) error {
+	if len(userAgent) > userAgentMaxLength {
+		userAgent = userAgent[:userAgentMaxLength]
+	}
+	ua := utils.ParseUserAgent(userAgent)
+
+	now := time.Now()
+	return s.sessionRepo.Create(&model.UserSession{
+		SessionID:  tokenPair.FamilyID,
+		UserID:     userID,
+		Username:   username,
+		JTI:        tokenPair.RefreshTokenID,
+		IP:         clientIP,
+		Browser:    ua.Browser,
+		OS:         ua.OS,
+		Device:     ua.Device,
+		UserAgent:  userAgent,
+		LoginAt:    now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(time.Duration(tokenPair.RefreshExpiresIn) * time.Second),
+	})
+}
+
+// RefreshSession 刷新token后更新会话
+func (s *SessionServiceImpl) RefreshSession(tokenPair *jwt.TokenPair, clientIP string) error {
+	expiresAt := time.Now().Add(time.Duration(tokenPair.RefreshExpiresIn) * time.Second)
+	return s.sessionRepo.UpdateRefresh(tokenPair.FamilyID, tokenPair.RefreshTokenID, clientIP, expiresAt)
+}
+
+// TouchSession 按间隔异步更新会话的最近活跃时间及访问IP
+func (s *SessionServiceImpl) TouchSession(sessionID string, clientIP string) {
+	if sessionID == "" {
+		return
+	}
+
+	now := time.Now()
+	s.touchMu.Lock()
+	last, ok := s.touches[sessionID]
+	if ok && last.ip == clientIP && now.Sub(last.at) < sessionTouchInterval {
+		s.touchMu.Unlock()
+		return
+	}
+	s.touches[sessionID] = sessionTouch{ip: clientIP, at: now}
+
+	// 定期清理超过间隔的条目，避免已结束的会话占用内存
+	if now.Sub(s.touchLastSweep) >= sessionTouchInterval {
+		for id, item := range s.touches {
+			if now.Sub(item.at) >= sessionTouchInterval {
+				delete(s.touches, id)
+			}
+		}
+		s.touchLastSweep = now
+	}
+	s.touchMu.Unlock()
+
+	// 异步写入，不阻塞请求
+	go func() {
+		if err := s.sessionRepo.Touch(sessionID, clientIP, now); err != nil {
+			logger.GetLogger().Warn("更新会话最近活跃时间失败", zap.String("session_id", sessionID), zap.Error(err))
+		}
+	}()
+}
+
+// ListUserSessions 获取用户的有效会话
+func (s *SessionServiceImpl) ListUserSessions(userID uint, currentSessionID string) ([]*dto.UserSessionResp, error) {
+	sessions, err := s.sessionRepo.FindActiveByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*dto.UserSessionResp, 0, len(sessions))
+	for _, session := range sessions {
+		list = append(list, &dto.UserSessionResp{
+			ID:         session.ID,
+			IP:         session.IP,
+			Browser:    session.Browser,
+			OS:         session.OS,
+			Device:     session.Device,
+			LoginAt:    session.LoginAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    currentSessionID != "" && session.SessionID == currentSessionID,
+		})
+	}
+	return list, nil
+}
+
+// RevokeUserSession 撤销用户的指定会话
+func (s *SessionServiceImpl) RevokeUserSession(userID uint, id uint, reason string) error {
+	session, err := s.sessionRepo.FindByID(id)
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return kperrors.New(kperrors.ErrNotFound, err).WithMessage("会话不存在")
+		}
+		return err
+	}
+	if session.UserID != userID || !session.IsActive() {
+		return kperrors.New(kperrors.ErrNotFound, nil).WithMessage("会话不存在")
+	}
+
+	return s.revoke(session.SessionID, session.UserID, session.Username, reason, session.ExpiresAt)
+}
+
+// RevokeUserSessions 撤销用户的全部会话
+func (s *SessionServiceImpl) RevokeUserSessions(userID uint, reason string) error {
+	sessions, err := s.sessionRepo.FindActiveByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if err := s.revoke(session.SessionID, session.UserID, session.Username, reason, session.ExpiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeSession 根据会话ID撤销会话
+func (s *SessionServiceImpl) RevokeSession(sessionID string, userID uint, username string, reason string) error {
+	if sessionID == "" {
+		return nil
+	}
+
+	// 没有会话记录时按最长的refresh token有效期撤销
+	expiresAt := time.Now().Add(jwt.RefreshTokenTTL(true))
+	session, err := s.sessionRepo.FindBySessionID(sessionID)
+	if err == nil {
+		expiresAt = session.ExpiresAt
+	} else if !kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+		return err
+	}
+
+	return s.revoke(sessionID, userID, username, reason, expiresAt)
+}
+
+// revoke 撤销会话、会话的全部refresh token，并将token族加入黑名单使已签发的access token失效
+func (s *SessionServiceImpl) revoke(sessionID string, userID uint, username string, reason string, expiresAt time.Time) error {
+	if _, err := s.sessionRepo.Revoke(sessionID, reason); err != nil {
+		return err
+	}
+	if err := s.refreshTokenRepo.RevokeFamily(sessionID); err != nil {
+		return err
+	}
+	if s.tokenBlacklistService != nil {
+		return s.tokenBlacklistService.BlacklistFamily(sessionID, userID, username, reason, expiresAt)
+	}
+	return nil
+}
+
+// CheckTokenVersion 校验token签发时的版本号是否与用户当前版本号一致
+func (s *SessionServiceImpl) CheckTokenVersion(userID uint, version uint) error {
+	current, err := s.tokenVersion(userID)
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return kperrors.New(kperrors.ErrUserNotFound, err)
+		}
+		return err
+	}
+	if version != current {
+		return kperrors.New(kperrors.ErrAuthSession, nil).WithMessage("登录状态已失效，请重新登录")
+	}
+	return nil
+}
+
+// RevokeUserTokens 递增用户的token版本号使此前签发的全部token失效，重置AppSecret，并撤销用户的全部会话
+func (s *SessionServiceImpl) RevokeUserTokens(userID uint, reason string) error {
+	version, err := s.userRepo.IncrTokenVersion(userID)
+	if err != nil {
+		return err
+	}
+	s.cacheTokenVersion(userID, version)
+
+	// 通过已失效的token轮换得到的AppSecret同样失效，用户重新登录后需再次轮换
+	_, appSecret := jwt.GenerateAppKeyAndSecret("")
+	if err := s.userRepo.ResetAppSecret(userID, appSecret); err != nil {
+		return err
+	}
+
+	_, err = s.sessionRepo.RevokeByUserID(userID, reason)
+	return err
+}
+
+// CleanupExpiredSessions 清理过期的登录会话及refresh token签发记录
+func (s *SessionServiceImpl) CleanupExpiredSessions() error {
+	now := time.Now()
+	if err := s.sessionRepo.CleanExpired(now); err != nil {
+		return err
+	}
+	return s.refreshTokenRepo.CleanExpired(now)
+}
+
+// tokenVersion 获取用户当前的token版本号，优先使用缓存
+func (s *SessionServiceImpl) tokenVersion(userID uint) (uint, error) {
+	s.versionMu.RLock()
+	entry, ok := s.versions[userID]
+	s.versionMu.RUnlock()
+	if ok && time.Now().Before(entry.expireAt) {
+		return entry.version, nil
+	}
+
+	version, err := s.userRepo.FindTokenVersion(userID)
+	if err != nil {
+		return 0, err
+	}
+	s.cacheTokenVersion(userID, version)
+	return version, nil
+}
+
+// cacheTokenVersion 缓存用户的token版本号
+func (s *SessionServiceImpl) cacheTokenVersion(userID uint, version uint) {
+	ttl := config.GetJWTConfig().VersionCacheTTL
+	if ttl <= 0 {
+		ttl = defaultTokenVersionCacheTTL
+	}
+
+	s.versionMu.Lock()
+	defer s.versionMu.Unlock()
+
+	now := time.Now()
+	s.versions[userID] = tokenVersionEntry{
+		version:  version,
+		expireAt: now.Add(ttl),
+	}
+
+	// 定期清理过期条目，避免长期不活跃的用户占用内存
+	if now.Sub(s.versionLastSweep) >= ttl {
+		for id, item := range s.versions {
+			if now.After(item.expireAt) {
+				delete(s.versions, id)
+			}
+		}
+		s.versionLastSweep = now
+	}
+}
@@ -0,0 +1,213 @@
+package impl
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/logger"
+	"github.com/cuiyuanxin/kunpeng/pkg/sms"
+	"go.uber.org/zap"
+)
+
+// 默认配置
+const (
+	defaultSMSTTL               = 5 * time.Minute
+	defaultSMSCooldown          = time.Minute
+	defaultSMSWindow            = 24 * time.Hour
+	defaultSMSMaxVerifyAttempts = 5
+)
+
+// SMSServiceImpl 短信验证码服务实现
+type SMSServiceImpl struct {
+	smsCodeRepo repository.SMSCodeRepository
+	userRepo    repository.UserRepository
+}
+
+// NewSMSService 创建短信验证码服务实例
+func NewSMSService(smsCodeRepo repository.SMSCodeRepository, userRepo repository.UserRepository) *SMSServiceImpl {
+	return &SMSServiceImpl{
+		smsCodeRepo: smsCodeRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// SendCode 发送短信验证码
+func (s *SMSServiceImpl) SendCode(req *dto.SMSCodeReq, scene, clientIP string) (*dto.SMSCodeResp, error) {
+	cfg := smsConfig()
+	now := time.Now()
+
+	// 检查重发间隔
+	latest, err := s.smsCodeRepo.FindLatest(req.Mobile, scene)
+	if err != nil && !kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+		return nil, err
+	}
+	if latest != nil {
+		if wait := latest.CreatedAt.Add(cfg.Cooldown).Sub(now); wait > 0 {
+			return nil, kperrors.New(kperrors.ErrAuthCaptchaFreq, nil).
+				WithMessage(fmt.Sprintf("验证码发送过于频繁，请%d秒后重试", int64(wait.Seconds())+1))
+		}
+	}
+
+	// 检查发送次数限制
+	since := now.Add(-cfg.Window)
+	if cfg.MobileLimit > 0 {
+		count, err := s.smsCodeRepo.CountByMobile(req.Mobile, since)
+		if err != nil {
+			return nil, err
+		}
+		if count >= int64(cfg.MobileLimit) {
+			return nil, kperrors.New(kperrors.ErrAuthCaptchaFreq, nil).WithMessage("该手机号验证码发送次数已达上限")
+		}
+	}
+	if cfg.IPLimit > 0 {
+		count, err := s.smsCodeRepo.CountByIP(clientIP, since)
+		if err != nil {
+			return nil, err
+		}
+		if count >= int64(cfg.IPLimit) {
+			return nil, kperrors.New(kperrors.ErrAuthCaptchaFreq, nil).WithMessage("当前IP验证码发送次数已达上限")
+		}
+	}
+
+	code, err := sms.GenerateCode()
+	if err != nil {
+		return nil, err
+	}
+
+	record := &model.SMSCode{
+		Mobile:    req.Mobile,
+		Scene:     scene,
+		CodeHash:  hashSMSCode(req.Mobile, code),
+		IP:        clientIP,
+		ExpiresAt: now.Add(cfg.TTL),
+	}
+	if err := s.smsCodeRepo.Create(record); err != nil {
+		return nil, err
+	}
+
+	// 手机号未注册时不发送短信，但返回相同结果，避免通过该接口探测手机号是否注册
+	if s.shouldSend(req.Mobile, scene) {
+		sender, err := sms.GetSender()
+		if err != nil {
+			return nil, err
+		}
+		if err := sender.SendCode(req.Mobile, code, cfg.TTL); err != nil {
+			logger.GetLogger().Error("发送短信验证码失败", zap.String("mobile", req.Mobile), zap.Error(err))
+			return nil, kperrors.New(kperrors.ErrThirdParty, err).WithMessage("短信发送失败，请稍后重试")
+		}
+	}
+
+	return &dto.SMSCodeResp{
+		ExpiresIn: int64(cfg.TTL.Seconds()),
+		Cooldown:  int64(cfg.Cooldown.Seconds()),
+	}, nil
+}
+
+// VerifyCode 校验短信验证码
+func (s *SMSServiceImpl) VerifyCode(mobile, scene, code string) error {
+	if code == "" {
+		return kperrors.New(kperrors.ErrAuthCaptchaEmpty, nil)
+	}
+
+	record, err := s.smsCodeRepo.FindLatest(mobile, scene)
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return kperrors.New(kperrors.ErrAuthCaptcha, err).WithMessage("验证码不存在或已过期")
+		}
+		return err
+	}
+	if record.UsedAt != nil {
+		return kperrors.New(kperrors.ErrAuthCaptchaUsed, nil)
+	}
+	if record.IsExpired() {
+		return kperrors.New(kperrors.ErrAuthCaptcha, nil).WithMessage("验证码不存在或已过期")
+	}
+
+	// 比较验证码之前先占用一次校验次数，并发校验时总次数也不会超过上限，防止暴力猜测
+	maxAttempts := smsConfig().MaxVerifyAttempts
+	ok, err := s.smsCodeRepo.UseAttempt(record.ID, maxAttempts)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return s.invalidateCode(record.ID)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSMSCode(mobile, code)), []byte(record.CodeHash)) != 1 {
+		// 最后一次校验失败后使验证码失效
+		if record.Attempts+1 >= maxAttempts {
+			return s.invalidateCode(record.ID)
+		}
+		return kperrors.New(kperrors.ErrAuthCaptcha, nil)
+	}
+
+	used, err := s.smsCodeRepo.MarkUsed(record.ID)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return kperrors.New(kperrors.ErrAuthCaptchaUsed, nil)
+	}
+	return nil
+}
+
+// invalidateCode 校验次数用完后使验证码失效
+func (s *SMSServiceImpl) invalidateCode(id uint) error {
+	if _, err := s.smsCodeRepo.MarkUsed(id); err != nil {
+		return err
+	}
+	return kperrors.New(kperrors.ErrAuthCaptcha, nil).WithMessage("验证码错误次数过多，请重新获取")
+}
+
+// shouldSend 检查是否需要真正发送短信
+func (s *SMSServiceImpl) shouldSend(mobile, scene string) bool {
+	if scene != model.SMSSceneLogin {
+		return true
+	}
+
+	_, err := s.userRepo.FindByMobile(mobile)
+	if err != nil {
+		if !kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			logger.GetLogger().Error("查询手机号用户失败", zap.String("mobile", mobile), zap.Error(err))
+		}
+		return false
+	}
+	return true
+}
+
+// CleanupExpiredCodes 清理过期的短信验证码记录，统计发送次数所需的记录保留到统计时间窗口结束
+func (s *SMSServiceImpl) CleanupExpiredCodes() error {
+	return s.smsCodeRepo.CleanExpired(time.Now().Add(-smsConfig().Window))
+}
+
+// smsConfig 获取短信验证码配置，未配置的项使用默认值
+func smsConfig() config.SMSConfig {
+	cfg := config.GetSMSConfig()
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultSMSTTL
+	}
+	if cfg.Cooldown < 0 {
+		cfg.Cooldown = defaultSMSCooldown
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultSMSWindow
+	}
+	if cfg.MaxVerifyAttempts <= 0 {
+		cfg.MaxVerifyAttempts = defaultSMSMaxVerifyAttempts
+	}
+	return cfg
+}
+
+// hashSMSCode 计算验证码哈希，数据库中不保存验证码明文
+func hashSMSCode(mobile, code string) string {
+	sum := sha256.Sum256([]byte(mobile + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
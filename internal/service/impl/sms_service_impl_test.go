@@ -0,0 +1,77 @@
+package impl
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/repository"
+	"github.com/cuiyuanxin/kunpeng/pkg/database"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+)
+
+const (
+	testSMSMobile = "13800000001"
+	testSMSCode   = "123456"
+)
+
+// setupSMSCode 创建一条未使用的登录验证码
+func setupSMSCode(t *testing.T) (*SMSServiceImpl, *model.SMSCode) {
+	t.Helper()
+	resetTables(t)
+
+	record := &model.SMSCode{
+		Mobile:    testSMSMobile,
+		Scene:     model.SMSSceneLogin,
+		CodeHash:  hashSMSCode(testSMSMobile, testSMSCode),
+		IP:        "127.0.0.1",
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	mustCreate(t, record)
+	return NewSMSService(repository.GetSMSCodeRepository(), repository.GetUserRepository()), record
+}
+
+func TestVerifyCode(t *testing.T) {
+	s, _ := setupSMSCode(t)
+
+	if err := s.VerifyCode(testSMSMobile, model.SMSSceneLogin, "000000"); !kperrors.IsCode(err, kperrors.ErrAuthCaptcha) {
+		t.Fatalf("VerifyCode error = %v, want ErrAuthCaptcha", err)
+	}
+	if err := s.VerifyCode(testSMSMobile, model.SMSSceneLogin, testSMSCode); err != nil {
+		t.Fatalf("VerifyCode error: %v", err)
+	}
+	if err := s.VerifyCode(testSMSMobile, model.SMSSceneLogin, testSMSCode); !kperrors.IsCode(err, kperrors.ErrAuthCaptchaUsed) {
+		t.Fatalf("VerifyCode error = %v, want ErrAuthCaptchaUsed", err)
+	}
+}
+
+func TestVerifyCodeConcurrentAttemptsLimited(t *testing.T) {
+	s, record := setupSMSCode(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.VerifyCode(testSMSMobile, model.SMSSceneLogin, "000000"); err == nil {
+				t.Error("错误的验证码校验通过")
+			}
+		}()
+	}
+	wg.Wait()
+
+	var stored model.SMSCode
+	if err := database.GetDB().First(&stored, record.ID).Error; err != nil {
+		t.Fatalf("查询验证码失败: %v", err)
+	}
+	if stored.Attempts != 3 {
+		t.Errorf("Attempts = %d, want 3", stored.Attempts)
+	}
+	if stored.UsedAt == nil {
+		t.Errorf("校验次数用完后验证码应失效")
+	}
+	if err := s.VerifyCode(testSMSMobile, model.SMSSceneLogin, testSMSCode); err == nil {
+		t.Errorf("校验次数用完后正确的验证码不应校验通过")
+	}
+}
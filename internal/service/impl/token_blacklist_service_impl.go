@@ -1,22 +1,27 @@
 package impl
 
 import (
+	"time"
+
 	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
 	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
 	"github.com/cuiyuanxin/kunpeng/internal/model"
 	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
 	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/cuiyuanxin/kunpeng/pkg/revocation"
 )
 
 // TokenBlacklistServiceImpl token黑名单服务实现
 type TokenBlacklistServiceImpl struct {
 	tokenBlacklistRepository repository.TokenBlacklistRepository
+	store                    revocation.Store
 }
 
-// NewTokenBlacklistService 创建token黑名单服务
-func NewTokenBlacklistService(tokenBlacklistRepository repository.TokenBlacklistRepository) service.TokenBlacklistService {
+// NewTokenBlacklistService 创建token黑名单服务，store为nil时直接查询数据库
+func NewTokenBlacklistService(tokenBlacklistRepository repository.TokenBlacklistRepository, store revocation.Store) service.TokenBlacklistService {
 	return &TokenBlacklistServiceImpl{
 		tokenBlacklistRepository: tokenBlacklistRepository,
+		store:                    store,
 	}
 }
 
@@ -28,21 +33,47 @@ func (s *TokenBlacklistServiceImpl) BlacklistToken(token string, userID uint, us
 		return kperrors.New(kperrors.ErrInvalidToken, err)
 	}
 
-	// 创建黑名单记录
+	// 创建黑名单记录，仅保存token的哈希
 	blacklist := &model.TokenBlacklist{
-		Token:     token,
+		TokenKey:  revocation.TokenKey(token),
 		UserID:    userID,
 		Username:  username,
 		Reason:    reason,
 		ExpiresAt: claims.ExpiresAt.Time,
 	}
 
-	return s.tokenBlacklistRepository.Create(blacklist)
+	return s.add(blacklist)
 }
 
 // IsTokenBlacklisted 检查token是否在黑名单中
 func (s *TokenBlacklistServiceImpl) IsTokenBlacklisted(token string) (bool, error) {
-	return s.tokenBlacklistRepository.IsTokenBlacklisted(token)
+	return s.contains(revocation.TokenKey(token))
+}
+
+// BlacklistFamily 撤销token族，expiresAt为该族中token的最晚过期时间
+func (s *TokenBlacklistServiceImpl) BlacklistFamily(familyID string, userID uint, username string, reason string, expiresAt time.Time) error {
+	if familyID == "" {
+		return nil
+	}
+
+	blacklist := &model.TokenBlacklist{
+		TokenKey:  revocation.FamilyKey(familyID),
+		FamilyID:  familyID,
+		UserID:    userID,
+		Username:  username,
+		Reason:    reason,
+		ExpiresAt: expiresAt,
+	}
+
+	return s.add(blacklist)
+}
+
+// IsFamilyBlacklisted 检查token族是否已被撤销
+func (s *TokenBlacklistServiceImpl) IsFamilyBlacklisted(familyID string) (bool, error) {
+	if familyID == "" {
+		return false, nil
+	}
+	return s.contains(revocation.FamilyKey(familyID))
 }
 
 // GetUserBlacklistTokens 根据用户ID获取黑名单记录
@@ -57,5 +88,44 @@ func (s *TokenBlacklistServiceImpl) CleanExpiredTokens() error {
 
 // DeleteBlacklistToken 删除黑名单记录
 func (s *TokenBlacklistServiceImpl) DeleteBlacklistToken(id uint) error {
-	return s.tokenBlacklistRepository.Delete(id)
+	blacklist, err := s.tokenBlacklistRepository.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.tokenBlacklistRepository.Delete(id); err != nil {
+		return err
+	}
+	if s.store != nil {
+		if err := s.store.Remove(blacklist.TokenKey); err != nil {
+			return kperrors.New(kperrors.ErrSystem, err)
+		}
+	}
+	return nil
+}
+
+// add 保存黑名单记录并写入撤销存储，数据库记录用于审计及其他实例同步
+func (s *TokenBlacklistServiceImpl) add(blacklist *model.TokenBlacklist) error {
+	if err := s.tokenBlacklistRepository.Create(blacklist); err != nil {
+		return err
+	}
+	if s.store != nil {
+		if err := s.store.Add(blacklist.TokenKey, blacklist.ExpiresAt); err != nil {
+			return kperrors.New(kperrors.ErrSystem, err)
+		}
+	}
+	return nil
+}
+
+// contains 检查撤销key是否已被撤销，未初始化撤销存储时直接查询数据库
+func (s *TokenBlacklistServiceImpl) contains(key string) (bool, error) {
+	if s.store == nil {
+		return s.tokenBlacklistRepository.ExistsKey(key)
+	}
+
+	revoked, err := s.store.Contains(key)
+	if err != nil {
+		return false, kperrors.New(kperrors.ErrSystem, err)
+	}
+	return revoked, nil
 }
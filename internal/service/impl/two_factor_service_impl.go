@@ -0,0 +1,285 @@
+package impl
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/repository"
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/totp"
+)
+
+// 默认配置
+const (
+	defaultRecoveryCodes = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789" // 去除易混淆的字符
+)
+
+// totpCodeRegex 动态码格式
+var totpCodeRegex = regexp.MustCompile(`^\d{6}$`)
+
+// TwoFactorServiceImpl 双因素认证服务实现
+type TwoFactorServiceImpl struct {
+	twoFactorRepo repository.TwoFactorRepository
+	userRepo      repository.UserRepository
+}
+
+// NewTwoFactorService 创建双因素认证服务实例
+func NewTwoFactorService(twoFactorRepo repository.TwoFactorRepository, userRepo repository.UserRepository) *TwoFactorServiceImpl {
+	return &TwoFactorServiceImpl{
+		twoFactorRepo: twoFactorRepo,
+		userRepo:      userRepo,
+	}
+}
+
+// GetStatus 获取用户的双因素认证状态
+func (s *TwoFactorServiceImpl) GetStatus(userID uint) (*dto.TwoFactorStatusResp, error) {
+	required, err := s.twoFactorRepo.IsRequiredByRole(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.TwoFactorStatusResp{Required: required}
+
+	twoFactor, err := s.findTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return resp, nil
+	}
+
+	resp.Enabled = true
+	resp.RecoveryCodes, err = s.twoFactorRepo.CountRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Setup 生成新的TOTP密钥
+func (s *TwoFactorServiceImpl) Setup(userID uint) (*dto.TwoFactorSetupResp, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := s.findTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor != nil && twoFactor.Enabled {
+		return nil, kperrors.New(kperrors.ErrAuthTwoFactor, nil).WithMessage("双因素认证已启用，如需更换验证器请先关闭")
+	}
+	if twoFactor == nil {
+		twoFactor = &model.UserTwoFactor{UserID: userID}
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, kperrors.New(kperrors.ErrSystem, err)
+	}
+	twoFactor.Secret = secret
+	twoFactor.LastStep = 0
+	if err := s.twoFactorRepo.Save(twoFactor); err != nil {
+		return nil, err
+	}
+
+	issuer := config.GetTwoFactorConfig().Issuer
+	if issuer == "" {
+		issuer = config.GetAppConfig().Name
+	}
+
+	return &dto.TwoFactorSetupResp{
+		Secret:     secret,
+		OtpauthURI: totp.URI(issuer, user.Username, secret),
+	}, nil
+}
+
+// Enable 校验动态码并启用双因素认证
+func (s *TwoFactorServiceImpl) Enable(userID uint, code string) (*dto.TwoFactorRecoveryCodesResp, error) {
+	twoFactor, err := s.findTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil {
+		return nil, kperrors.New(kperrors.ErrAuthTwoFactor, nil).WithMessage("请先获取双因素认证密钥")
+	}
+	if twoFactor.Enabled {
+		return nil, kperrors.New(kperrors.ErrAuthTwoFactor, nil).WithMessage("双因素认证已启用")
+	}
+
+	if err := s.verifyTOTP(twoFactor, code); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	twoFactor.Enabled = true
+	twoFactor.EnabledAt = &now
+	if err := s.twoFactorRepo.Save(twoFactor); err != nil {
+		return nil, err
+	}
+
+	return s.generateRecoveryCodes(userID)
+}
+
+// Disable 关闭双因素认证
+func (s *TwoFactorServiceImpl) Disable(userID uint, code string) error {
+	required, err := s.twoFactorRepo.IsRequiredByRole(userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return kperrors.New(kperrors.ErrAuthTwoFactor, nil).WithMessage("所属角色要求启用双因素认证，不能关闭")
+	}
+
+	if err := s.Verify(userID, code); err != nil {
+		return err
+	}
+
+	return s.twoFactorRepo.DeleteByUserID(userID)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (s *TwoFactorServiceImpl) RegenerateRecoveryCodes(userID uint, code string) (*dto.TwoFactorRecoveryCodesResp, error) {
+	twoFactor, err := s.enabledTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 恢复码可能已泄露，只允许使用动态码
+	if err := s.verifyTOTP(twoFactor, code); err != nil {
+		return nil, err
+	}
+
+	return s.generateRecoveryCodes(userID)
+}
+
+// Verify 校验动态码或恢复码
+func (s *TwoFactorServiceImpl) Verify(userID uint, code string) error {
+	twoFactor, err := s.enabledTwoFactor(userID)
+	if err != nil {
+		return err
+	}
+
+	code = strings.TrimSpace(code)
+	if totpCodeRegex.MatchString(code) {
+		return s.verifyTOTP(twoFactor, code)
+	}
+
+	used, err := s.twoFactorRepo.UseRecoveryCode(userID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return kperrors.New(kperrors.ErrAuthTwoFactor, nil).WithMessage("动态码或恢复码错误")
+	}
+	return nil
+}
+
+// Reset 重置用户的双因素认证
+func (s *TwoFactorServiceImpl) Reset(userID uint) error {
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return err
+	}
+	return s.twoFactorRepo.DeleteByUserID(userID)
+}
+
+// findTwoFactor 查询用户的双因素认证信息，不存在时返回nil
+func (s *TwoFactorServiceImpl) findTwoFactor(userID uint) (*model.UserTwoFactor, error) {
+	twoFactor, err := s.twoFactorRepo.FindByUserID(userID)
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return twoFactor, nil
+}
+
+// enabledTwoFactor 查询用户已启用的双因素认证信息
+func (s *TwoFactorServiceImpl) enabledTwoFactor(userID uint) (*model.UserTwoFactor, error) {
+	twoFactor, err := s.findTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return nil, kperrors.New(kperrors.ErrAuthTwoFactor, nil).WithMessage("未启用双因素认证")
+	}
+	return twoFactor, nil
+}
+
+// verifyTOTP 校验动态码，同一时间步的动态码只能使用一次
+func (s *TwoFactorServiceImpl) verifyTOTP(twoFactor *model.UserTwoFactor, code string) error {
+	step, ok := totp.Validate(twoFactor.Secret, code, time.Now(), config.GetTwoFactorConfig().Skew)
+	if !ok {
+		return kperrors.New(kperrors.ErrAuthTwoFactor, nil).WithMessage("动态码错误")
+	}
+
+	consumed, err := s.twoFactorRepo.ConsumeStep(twoFactor.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return kperrors.New(kperrors.ErrAuthTwoFactor, nil).WithMessage("动态码已使用，请等待下一个动态码")
+	}
+	twoFactor.LastStep = step
+	return nil
+}
+
+// generateRecoveryCodes 生成并保存恢复码，数据库中只保存哈希
+func (s *TwoFactorServiceImpl) generateRecoveryCodes(userID uint) (*dto.TwoFactorRecoveryCodesResp, error) {
+	count := config.GetTwoFactorConfig().RecoveryCodes
+	if count <= 0 {
+		count = defaultRecoveryCodes
+	}
+
+	codes := make([]string, 0, count)
+	hashes := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		code, err := randomRecoveryCode()
+		if err != nil {
+			return nil, kperrors.New(kperrors.ErrSystem, err)
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorRecoveryCodesResp{RecoveryCodes: codes}, nil
+}
+
+// randomRecoveryCode 生成随机恢复码，格式为 xxxxx-xxxxx
+func randomRecoveryCode() (string, error) {
+	var sb strings.Builder
+	for i := 0; i < recoveryCodeLength; i++ {
+		if i == recoveryCodeLength/2 {
+			sb.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(recoveryCodeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(recoveryCodeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// hashRecoveryCode 计算恢复码哈希，忽略大小写、空格和分隔符
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,113 +2,333 @@ package impl
 
 import (
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
 	"github.com/cuiyuanxin/kunpeng/internal/model"
 	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
 	"github.com/cuiyuanxin/kunpeng/internal/repository"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
 	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
 	"github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/cuiyuanxin/kunpeng/pkg/password"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // UserServiceImpl 用户服务实现
 type UserServiceImpl struct {
-	loginAttemptService service.LoginAttemptService
+	loginAttemptService   service.LoginAttemptService
+	captchaService        service.CaptchaService
+	smsService            service.SMSService
+	twoFactorService      service.TwoFactorService
+	tokenBlacklistService service.TokenBlacklistService
+	sessionService        service.SessionService
+	oidcService           service.OIDCService
+	authProviders         map[string]authProvider // 按登录类型划分的认证方式
 }
 
+// userProfileColumns 编辑用户时更新的字段，密码、AppSecret等字段通过专门的接口修改
+var userProfileColumns = []string{"nickname", "real_name", "avatar", "gender", "email", "mobile", "dept_id", "post_id", "status", "remark"}
+
 // NewUserService 创建用户服务实例
-func NewUserService(loginAttemptService service.LoginAttemptService) *UserServiceImpl {
-	return &UserServiceImpl{
-		loginAttemptService: loginAttemptService,
+func NewUserService(
+	loginAttemptService service.LoginAttemptService,
+	captchaService service.CaptchaService,
+	smsService service.SMSService,
+	twoFactorService service.TwoFactorService,
+	tokenBlacklistService service.TokenBlacklistService,
+	sessionService service.SessionService,
+	oidcService service.OIDCService,
+) *UserServiceImpl {
+	s := &UserServiceImpl{
+		loginAttemptService:   loginAttemptService,
+		captchaService:        captchaService,
+		smsService:            smsService,
+		twoFactorService:      twoFactorService,
+		tokenBlacklistService: tokenBlacklistService,
+		sessionService:        sessionService,
+		oidcService:           oidcService,
+	}
+
+	passwordProvider := &passwordAuthProvider{verifyCaptcha: s.verifyLoginCaptcha}
+	s.authProviders = map[string]authProvider{
+		dto.LoginTypeUsername: passwordProvider,
+		dto.LoginTypeMobile:   &mobileAuthProvider{smsService: smsService},
+		dto.LoginTypeLDAP: &ldapAuthProvider{
+			local:          passwordProvider,
+			verifyCaptcha:  s.verifyLoginCaptcha,
+			sessionService: sessionService,
+		},
 	}
+	return s
 }
 
 // Login 用户登录
-func (s *UserServiceImpl) Login(req *dto.UserLoginReq, clientIP string) (*dto.UserLoginResp, error) {
-	// 检查是否被拉黑
+func (s *UserServiceImpl) Login(req *dto.UserLoginReq, clientIP string, userAgent string) (*dto.UserLoginResp, error) {
+	// 检查账号或IP是否被锁定
 	if s.loginAttemptService != nil {
-		blocked, err := s.loginAttemptService.IsBlocked(req.Account, clientIP)
-		if err != nil {
-			return nil, kperrors.New(kperrors.ErrSystem, err)
+		if err := s.loginAttemptService.CheckLocked(req.Account, clientIP); err != nil {
+			return nil, err
 		}
-		if blocked {
-			return nil, kperrors.New(kperrors.ErrAuthLocked, nil).WithMessage("登录失败次数过多，账号已被锁定2小时")
+	}
+
+	provider, ok := s.authProviders[req.LoginType]
+	if !ok {
+		return nil, kperrors.New(kperrors.ErrParam, nil)
+	}
+
+	// 根据登录类型校验凭据
+	user, err := provider.Authenticate(req, clientIP)
+	if err != nil {
+		var credErr *credentialError
+		if errors.As(err, &credErr) {
+			// 记录登录失败
+			if s.loginAttemptService != nil {
+				s.loginAttemptService.CheckAndRecordAttempt(req.Account, clientIP, false)
+			}
+			return nil, credErr.err
+		}
+		return nil, err
+	}
+
+	// 启用了双因素认证或所属角色要求双因素认证时，先签发登录第二步凭证
+	// 此时不重置登录失败次数，避免通过反复登录绕过第二步的失败次数限制
+	challenge, err := s.twoFactorChallenge(user, req.RememberMe)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return challenge, nil
+	}
+
+	// 记录登录成功
+	if s.loginAttemptService != nil {
+		s.loginAttemptService.CheckAndRecordAttempt(req.Account, clientIP, true)
+	}
+
+	return s.completeLogin(user, clientIP, userAgent, req.RememberMe)
+}
+
+// LoginOIDC 单点登录，校验身份提供方回调后按账号登录的流程完成双因素认证及签发token对
+func (s *UserServiceImpl) LoginOIDC(req *dto.OIDCCallbackReq, clientIP string, userAgent string) (*dto.UserLoginResp, error) {
+	if s.oidcService == nil {
+		return nil, kperrors.New(kperrors.ErrSystem, nil).WithMessage("单点登录服务未初始化")
+	}
+
+	user, err := s.oidcService.Authenticate(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查用户状态
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	// 检查账号或IP是否被锁定，锁定期间同样不允许通过单点登录
+	if s.loginAttemptService != nil {
+		if err := s.loginAttemptService.CheckLocked(user.Username, clientIP); err != nil {
+			return nil, err
+		}
+	}
+
+	challenge, err := s.twoFactorChallenge(user, req.RememberMe)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return challenge, nil
+	}
+
+	return s.completeLogin(user, clientIP, userAgent, req.RememberMe)
+}
+
+// LoginTwoFactor 双因素认证登录，校验动态码或恢复码后签发token对
+// 所属角色要求双因素认证但尚未启用时，校验动态码的同时完成绑定
+func (s *UserServiceImpl) LoginTwoFactor(req *dto.TwoFactorLoginReq, clientIP string, userAgent string) (*dto.UserLoginResp, error) {
+	claims, user, err := s.parseTwoFactorToken(req.TwoFactorToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查账号或IP是否被锁定
+	if s.loginAttemptService != nil {
+		if err := s.loginAttemptService.CheckLocked(user.Username, clientIP); err != nil {
+			return nil, err
 		}
 	}
 
-	var user *model.User
-	var err error
+	status, err := s.twoFactorService.GetStatus(user.ID)
+	if err != nil {
+		return nil, err
+	}
 
-	// 根据登录类型查询用户
-	switch req.LoginType {
-	case "username":
-		user, err = repository.GetUserRepository().FindByUsername(req.Account)
-	case "mobile":
-		user, err = repository.GetUserRepository().FindByMobile(req.Account)
+	var recoveryCodes []string
+	switch {
+	case status.Enabled:
+		err = s.twoFactorService.Verify(user.ID, req.Code)
+	case status.Required:
+		var codes *dto.TwoFactorRecoveryCodesResp
+		codes, err = s.twoFactorService.Enable(user.ID, req.Code)
+		if err == nil {
+			recoveryCodes = codes.RecoveryCodes
+		}
 	default:
-		return nil, kperrors.New(kperrors.ErrParam, nil)
+		// 签发凭证后双因素认证被关闭或重置
+		return nil, kperrors.New(kperrors.ErrAuthTwoFactor, nil).WithMessage("双因素认证状态已变更，请重新登录")
+	}
+	if err != nil {
+		// 记录登录失败
+		if s.loginAttemptService != nil {
+			s.loginAttemptService.CheckAndRecordAttempt(user.Username, clientIP, false)
+		}
+		return nil, err
+	}
+
+	// 记录登录成功
+	if s.loginAttemptService != nil {
+		s.loginAttemptService.CheckAndRecordAttempt(user.Username, clientIP, true)
+	}
+
+	// 登录第二步凭证只能使用一次
+	if s.tokenBlacklistService != nil {
+		if err := s.tokenBlacklistService.BlacklistToken(req.TwoFactorToken, user.ID, user.Username, "双因素认证完成"); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := s.completeLogin(user, clientIP, userAgent, claims.RememberMe)
+	if err != nil {
+		return nil, err
+	}
+	resp.RecoveryCodes = recoveryCodes
+	return resp, nil
+}
+
+// SetupLoginTwoFactor 登录时绑定验证器，仅用于所属角色要求双因素认证但尚未启用的用户
+func (s *UserServiceImpl) SetupLoginTwoFactor(req *dto.TwoFactorTokenReq) (*dto.TwoFactorSetupResp, error) {
+	_, user, err := s.parseTwoFactorToken(req.TwoFactorToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.twoFactorService.Setup(user.ID)
+}
+
+// twoFactorChallenge 检查登录是否需要双因素认证，需要时返回登录第二步凭证
+func (s *UserServiceImpl) twoFactorChallenge(user *model.User, rememberMe bool) (*dto.UserLoginResp, error) {
+	if s.twoFactorService == nil {
+		return nil, nil
+	}
+
+	status, err := s.twoFactorService.GetStatus(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !status.Enabled && !status.Required {
+		return nil, nil
 	}
 
+	token, _, err := jwt.GenerateTwoFactorToken(user.ID, user.Username, user.TokenVersion, rememberMe)
+	if err != nil {
+		return nil, kperrors.New(kperrors.ErrSystem, err)
+	}
+
+	return &dto.UserLoginResp{
+		TwoFactorRequired: true,
+		TwoFactorSetup:    !status.Enabled,
+		TwoFactorToken:    token,
+	}, nil
+}
+
+// parseTwoFactorToken 解析登录第二步凭证并检查用户状态
+func (s *UserServiceImpl) parseTwoFactorToken(token string) (*jwt.CustomClaims, *model.User, error) {
+	if s.twoFactorService == nil {
+		return nil, nil, kperrors.New(kperrors.ErrSystem, nil).WithMessage("双因素认证服务未初始化")
+	}
+
+	claims, err := jwt.ParseToken(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if claims.TokenType != jwt.TwoFactorTokenType {
+		return nil, nil, kperrors.New(kperrors.ErrInvalidToken, nil)
+	}
+
+	if s.tokenBlacklistService != nil {
+		blacklisted, err := s.tokenBlacklistService.IsTokenBlacklisted(token)
+		if err != nil {
+			return nil, nil, kperrors.New(kperrors.ErrSystem, err)
+		}
+		if blacklisted {
+			return nil, nil, kperrors.New(kperrors.ErrInvalidToken, nil)
+		}
+	}
+
+	user, err := repository.GetUserRepository().FindByID(claims.UserID)
 	if err != nil {
 		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
-			return nil, kperrors.New(kperrors.ErrUserNotFound, err)
+			return nil, nil, kperrors.New(kperrors.ErrUserNotFound, err)
 		}
-		return nil, err
+		return nil, nil, err
 	}
 
 	// 检查用户状态
 	if user.Status == 0 {
-		return nil, kperrors.New(kperrors.ErrUserDisabled, nil)
+		return nil, nil, kperrors.New(kperrors.ErrUserDisabled, nil)
 	}
 	if user.Status == 2 {
-		return nil, kperrors.New(kperrors.ErrUserLocked, nil)
+		return nil, nil, kperrors.New(kperrors.ErrUserLocked, nil)
 	}
 
-	// 根据登录类型进行不同的验证
-	var loginSuccess bool
-	switch req.LoginType {
-	case "username":
-		// 账号登录：验证密码
-		if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-			// 记录登录失败
-			if s.loginAttemptService != nil {
-				s.loginAttemptService.CheckAndRecordAttempt(req.Account, clientIP, false)
-			}
-			return nil, kperrors.New(kperrors.ErrUserPassword, err)
-		}
-		loginSuccess = true
-	case "mobile":
-		// 手机号登录：验证验证码
-		// TODO: 这里应该调用验证码服务验证验证码的有效性
-		// 暂时跳过验证码验证，实际项目中需要实现验证码验证逻辑
-		if req.Captcha == "" {
-			// 记录登录失败
-			if s.loginAttemptService != nil {
-				s.loginAttemptService.CheckAndRecordAttempt(req.Account, clientIP, false)
-			}
-			return nil, kperrors.New(kperrors.ErrParam, errors.New("验证码不能为空"))
-		}
-		loginSuccess = true
+	// 签发凭证后修改了密码或变更了状态
+	if claims.Version != user.TokenVersion {
+		return nil, nil, kperrors.New(kperrors.ErrAuthSession, nil).WithMessage("登录状态已失效，请重新登录")
 	}
 
-	// 记录登录成功
-	if s.loginAttemptService != nil && loginSuccess {
-		s.loginAttemptService.CheckAndRecordAttempt(req.Account, clientIP, true)
+	return claims, user, nil
+}
+
+// completeLogin 完成登录，需要修改密码时只签发修改密码凭证，修改密码后需重新登录
+func (s *UserServiceImpl) completeLogin(user *model.User, clientIP string, userAgent string, rememberMe bool) (*dto.UserLoginResp, error) {
+	if !passwordChangeRequired(user) {
+		return s.issueLoginTokens(user, clientIP, userAgent, rememberMe)
+	}
+
+	token, _, err := jwt.GeneratePasswordToken(user.ID, user.Username, user.TokenVersion)
+	if err != nil {
+		return nil, kperrors.New(kperrors.ErrSystem, err)
+	}
+
+	return &dto.UserLoginResp{
+		PasswordChangeRequired: true,
+		PasswordChangeToken:    token,
+	}, nil
+}
+
+// issueLoginTokens 签发token对，创建登录会话并更新登录信息
+func (s *UserServiceImpl) issueLoginTokens(user *model.User, clientIP string, userAgent string, rememberMe bool) (*dto.UserLoginResp, error) {
+	// 查询用户角色
+	roleIDs, err := repository.GetUserRepository().FindRoleIDs(user.ID)
+	if err != nil {
+		return nil, err
 	}
 
 	// 生成Token对（支持记住我功能）
-	tokenPair, err := jwt.GenerateTokenPair(user.ID, user.Username, user.RoleID, user.AppKey, user.AppSecret, req.RememberMe)
+	tokenPair, err := jwt.GenerateTokenPair(user.ID, user.Username, roleIDs, user.TokenVersion, user.AppKey, user.AppSecret, rememberMe)
 	if err != nil {
 		return nil, kperrors.New(kperrors.ErrSystem, err)
 	}
+	if err := s.recordRefreshToken(user.ID, tokenPair); err != nil {
+		return nil, err
+	}
+	if err := s.sessionService.CreateSession(user.ID, user.Username, tokenPair, clientIP, userAgent); err != nil {
+		return nil, err
+	}
 
 	// 更新登录信息
-	now := time.Now()
-	user.LoginTime = &now
-	user.LoginIP = clientIP
-	repository.GetUserRepository().Update(user)
+	repository.GetUserRepository().UpdateLoginInfo(user.ID, clientIP, time.Now())
 
 	// 返回结果
 	return &dto.UserLoginResp{
@@ -119,8 +339,36 @@ func (s *UserServiceImpl) Login(req *dto.UserLoginReq, clientIP string) (*dto.Us
 	}, nil
 }
 
+// verifyLoginCaptcha 按需校验登录图片验证码
+func (s *UserServiceImpl) verifyLoginCaptcha(req *dto.UserLoginReq, clientIP string) error {
+	if s.captchaService == nil {
+		return nil
+	}
+
+	required, err := s.captchaService.IsRequired(req.Account, clientIP)
+	if err != nil {
+		return kperrors.New(kperrors.ErrSystem, err)
+	}
+	if !required {
+		return nil
+	}
+
+	if req.CaptchaID == "" || req.Captcha == "" {
+		return kperrors.New(kperrors.ErrAuthCaptchaEmpty, nil)
+	}
+
+	ok, err := s.captchaService.Verify(req.CaptchaID, req.Captcha)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return kperrors.New(kperrors.ErrAuthCaptcha, nil)
+	}
+	return nil
+}
+
 // RefreshToken 刷新token
-func (s *UserServiceImpl) RefreshToken(req *dto.RefreshTokenReq) (*dto.UserLoginResp, error) {
+func (s *UserServiceImpl) RefreshToken(req *dto.RefreshTokenReq, clientIP string) (*dto.UserLoginResp, error) {
 	// 解析refresh token获取用户信息
 	claims, err := jwt.ParseToken(req.RefreshToken)
 	if err != nil {
@@ -132,6 +380,11 @@ func (s *UserServiceImpl) RefreshToken(req *dto.RefreshTokenReq) (*dto.UserLogin
 		return nil, kperrors.New(kperrors.ErrInvalidToken, nil)
 	}
 
+	// refresh token只能使用一次
+	if err := s.rotateRefreshToken(claims); err != nil {
+		return nil, err
+	}
+
 	// 检查用户是否存在且状态正常
 	user, err := repository.GetUserRepository().FindByID(claims.UserID)
 	if err != nil {
@@ -149,11 +402,33 @@ func (s *UserServiceImpl) RefreshToken(req *dto.RefreshTokenReq) (*dto.UserLogin
 		return nil, kperrors.New(kperrors.ErrUserLocked, nil)
 	}
 
-	// 生成新的token对
-	tokenPair, err := jwt.GenerateTokenPair(user.ID, user.Username, user.RoleID, user.AppKey, user.AppSecret, claims.RememberMe)
+	// 签发后修改了密码、重置了密码、被禁用或变更了角色
+	if claims.Version != user.TokenVersion {
+		return nil, kperrors.New(kperrors.ErrAuthSession, nil).WithMessage("登录状态已失效，请重新登录")
+	}
+
+	// 密码已过期时不再续期，需重新登录并修改密码
+	if passwordChangeRequired(user) {
+		return nil, kperrors.New(kperrors.ErrAuthSession, nil).WithMessage("密码已过期，请重新登录并修改密码")
+	}
+
+	// 查询用户最新角色
+	roleIDs, err := repository.GetUserRepository().FindRoleIDs(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 在同一token族中生成新的token对
+	tokenPair, err := jwt.GenerateTokenPairInFamily(claims.FamilyID, user.ID, user.Username, roleIDs, user.TokenVersion, user.AppKey, user.AppSecret, claims.RememberMe)
 	if err != nil {
 		return nil, kperrors.New(kperrors.ErrSystem, err)
 	}
+	if err := s.recordRefreshToken(user.ID, tokenPair); err != nil {
+		return nil, err
+	}
+	if err := s.sessionService.RefreshSession(tokenPair, clientIP); err != nil {
+		return nil, err
+	}
 
 	// 返回结果
 	return &dto.UserLoginResp{
@@ -164,6 +439,61 @@ func (s *UserServiceImpl) RefreshToken(req *dto.RefreshTokenReq) (*dto.UserLogin
 	}, nil
 }
 
+// rotateRefreshToken 将refresh token标记为已轮换
+// 已轮换的refresh token再次使用说明token可能已泄露，撤销整个token族
+func (s *UserServiceImpl) rotateRefreshToken(claims *jwt.CustomClaims) error {
+	// 升级前签发的refresh token没有jti和族ID，需重新登录
+	if claims.ID == "" || claims.FamilyID == "" {
+		return kperrors.New(kperrors.ErrInvalidToken, nil)
+	}
+
+	if s.tokenBlacklistService != nil {
+		revoked, err := s.tokenBlacklistService.IsFamilyBlacklisted(claims.FamilyID)
+		if err != nil {
+			return kperrors.New(kperrors.ErrSystem, err)
+		}
+		if revoked {
+			return kperrors.New(kperrors.ErrInvalidToken, nil)
+		}
+	}
+
+	refreshTokenRepo := repository.GetRefreshTokenRepository()
+	rotated, err := refreshTokenRepo.MarkUsed(claims.ID)
+	if err != nil {
+		return err
+	}
+	if rotated {
+		return nil
+	}
+
+	record, err := refreshTokenRepo.FindByJTI(claims.ID)
+	if err != nil {
+		if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+			return kperrors.New(kperrors.ErrInvalidToken, err)
+		}
+		return err
+	}
+	if record.RevokedAt != nil {
+		return kperrors.New(kperrors.ErrInvalidToken, nil)
+	}
+
+	// 重复使用已轮换的refresh token，撤销整个token族及其登录会话
+	if err := s.sessionService.RevokeSession(claims.FamilyID, claims.UserID, claims.Username, "refresh token重复使用"); err != nil {
+		return err
+	}
+	return kperrors.New(kperrors.ErrAuthTokenReuse, nil).WithMessage("refresh token已被使用，该登录的全部token已失效，请重新登录")
+}
+
+// recordRefreshToken 记录签发的refresh token，用于轮换时检测重复使用
+func (s *UserServiceImpl) recordRefreshToken(userID uint, tokenPair *jwt.TokenPair) error {
+	return repository.GetRefreshTokenRepository().Create(&model.RefreshToken{
+		JTI:       tokenPair.RefreshTokenID,
+		FamilyID:  tokenPair.FamilyID,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(time.Duration(tokenPair.RefreshExpiresIn) * time.Second),
+	})
+}
+
 // GetUserInfo 获取用户信息
 func (s *UserServiceImpl) GetUserInfo(userID uint) (*dto.UserInfoResp, error) {
 	// 查询用户
@@ -173,17 +503,24 @@ func (s *UserServiceImpl) GetUserInfo(userID uint) (*dto.UserInfoResp, error) {
 	}
 
 	// 查询角色、部门、岗位信息
-	role, _ := repository.GetRoleRepository().FindByID(user.RoleID)
+	roles, _ := repository.GetRoleRepository().FindByUserID(user.ID)
 	dept, _ := repository.GetDeptRepository().FindByID(user.DeptID)
 	post, _ := repository.GetPostRepository().FindByID(user.PostID)
 
-	roleName := ""
+	roleNames := make([]string, 0, len(roles))
 	deptName := ""
 	postName := ""
 
-	if role != nil {
-		roleName = role.Name
+	for _, role := range roles {
+		roleNames = append(roleNames, role.Name)
 	}
+
+	// 查询权限标识，用于前端控制按钮显示
+	permissions, err := repository.GetMenuRepository().FindUserPermissions(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	if dept != nil {
 		deptName = dept.Name
 	}
@@ -192,15 +529,16 @@ func (s *UserServiceImpl) GetUserInfo(userID uint) (*dto.UserInfoResp, error) {
 	}
 
 	return &dto.UserInfoResp{
-		ID:       user.ID,
-		Username: user.Username,
-		Nickname: user.Nickname,
-		RealName: user.RealName,
-		Avatar:   user.Avatar,
-		Gender:   user.Gender,
-		RoleName: roleName,
-		DeptName: deptName,
-		PostName: postName,
+		ID:          user.ID,
+		Username:    user.Username,
+		Nickname:    user.Nickname,
+		RealName:    user.RealName,
+		Avatar:      user.Avatar,
+		Gender:      user.Gender,
+		RoleNames:   roleNames,
+		Permissions: permissions,
+		DeptName:    deptName,
+		PostName:    postName,
 	}, nil
 }
 
@@ -228,12 +566,33 @@ func (s *UserServiceImpl) GetUserList(req *dto.UserPageReq) (*dto.PageResp, erro
 }
 
 // GetUserByID 根据ID获取用户
-func (s *UserServiceImpl) GetUserByID(id uint) (*model.User, error) {
-	return repository.GetUserRepository().FindByID(id)
+func (s *UserServiceImpl) GetUserByID(operatorID uint, id uint) (*model.User, error) {
+	user, err := repository.GetUserRepository().FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查用户是否在操作者的数据权限范围内
+	if err := checkUserDataScope(operatorID, user); err != nil {
+		return nil, err
+	}
+
+	// 查询用户角色
+	user.Roles, err = repository.GetRoleRepository().FindByUserID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
 }
 
 // CreateUser 创建用户
-func (s *UserServiceImpl) CreateUser(req *dto.UserCreateReq) (uint, error) {
+func (s *UserServiceImpl) CreateUser(operatorID uint, req *dto.UserCreateReq) (uint, error) {
+	// 用户所属部门需在操作者的数据权限范围内
+	if err := checkUserDataScope(operatorID, &model.User{DeptID: req.DeptID}); err != nil {
+		return 0, err
+	}
+
 	// 检查用户名是否存在
 	existUser, err := repository.GetUserRepository().FindByUsername(req.Username)
 	if err == nil && existUser != nil {
@@ -246,6 +605,16 @@ func (s *UserServiceImpl) CreateUser(req *dto.UserCreateReq) (uint, error) {
 		}
 	}
 
+	// 检查角色是否存在
+	if err := checkRoleIDs(req.RoleIDs); err != nil {
+		return 0, err
+	}
+
+	// 检查密码是否符合密码策略
+	if err := password.Validate(req.Password, req.Username); err != nil {
+		return 0, err
+	}
+
 	// 生成密码哈希
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -255,41 +624,91 @@ func (s *UserServiceImpl) CreateUser(req *dto.UserCreateReq) (uint, error) {
 	// 生成AppKey和AppSecret
 	appKey, appSecret := jwt.GenerateAppKeyAndSecret(req.Username)
 
-	// 创建用户
+	// 创建用户，密码由管理员设置，用户首次登录时必须修改
+	now := time.Now()
 	user := model.User{
-		Username:  req.Username,
-		Password:  string(hashedPassword),
-		Nickname:  req.Nickname,
-		RealName:  req.RealName,
-		Avatar:    req.Avatar,
-		Gender:    req.Gender,
-		Email:     req.Email,
-		Mobile:    req.Mobile,
-		DeptID:    req.DeptID,
-		PostID:    req.PostID,
-		RoleID:    req.RoleID,
-		Status:    req.Status,
-		AppKey:    appKey,
-		AppSecret: appSecret,
-		Remark:    req.Remark,
-	}
-
-	err = repository.GetUserRepository().Create(&user)
+		Username:           req.Username,
+		Password:           string(hashedPassword),
+		Nickname:           req.Nickname,
+		RealName:           req.RealName,
+		Avatar:             req.Avatar,
+		Gender:             req.Gender,
+		Email:              req.Email,
+		Mobile:             req.Mobile,
+		DeptID:             req.DeptID,
+		PostID:             req.PostID,
+		Status:             req.Status,
+		AppKey:             appKey,
+		AppSecret:          appSecret,
+		MustChangePassword: true,
+		PasswordChangedAt:  &now,
+		Remark:             req.Remark,
+	}
+
+	err = repository.GetUserRepository().CreateWithRoles(&user, req.RoleIDs)
 	if err != nil {
 		return 0, err
 	}
 
+	if err := recordPasswordHistory(user.ID, user.Password); err != nil {
+		return 0, err
+	}
+
 	return user.ID, nil
 }
 
 // UpdateUser 更新用户
-func (s *UserServiceImpl) UpdateUser(req *dto.UserUpdateReq) error {
+func (s *UserServiceImpl) UpdateUser(operatorID uint, req *dto.UserUpdateReq) error {
 	// 检查用户是否存在
 	user, err := repository.GetUserRepository().FindByID(req.ID)
 	if err != nil {
 		return err
 	}
 
+	// 检查用户是否可由操作者管理，变更后的部门需在操作者的数据权限范围内
+	if err := checkUserManageable(operatorID, user); err != nil {
+		return err
+	}
+	if req.DeptID != user.DeptID {
+		if err := checkUserDataScope(operatorID, &model.User{DeptID: req.DeptID}); err != nil {
+			return err
+		}
+	}
+
+	// 检查角色是否存在
+	if err := checkRoleIDs(req.RoleIDs); err != nil {
+		return err
+	}
+
+	// 内置用户和当前登录用户不允许禁用
+	if req.Status != 1 {
+		if err := checkUserRemovable(operatorID, user); err != nil {
+			return err
+		}
+	}
+
+	// 内置用户必须保留其内置角色
+	if user.IsBuiltin {
+		if err := checkBuiltinRolesKept(user.ID, req.RoleIDs); err != nil {
+			return err
+		}
+	}
+
+	// 检查是否会导致操作者失去权限管理能力
+	err = checkSelfLockout(operatorID, &permissionChange{
+		userRoleIDs: map[uint][]uint{user.ID: req.RoleIDs},
+	})
+	if err != nil {
+		return err
+	}
+
+	// 角色变更或被禁用后需使已签发的token失效
+	oldRoleIDs, err := repository.GetUserRepository().FindRoleIDs(user.ID)
+	if err != nil {
+		return err
+	}
+	revokeTokens := !sameIDs(oldRoleIDs, req.RoleIDs) || (user.Status == 1 && req.Status != 1)
+
 	// 更新用户信息
 	user.Nickname = req.Nickname
 	user.RealName = req.RealName
@@ -299,37 +718,194 @@ func (s *UserServiceImpl) UpdateUser(req *dto.UserUpdateReq) error {
 	user.Mobile = req.Mobile
 	user.DeptID = req.DeptID
 	user.PostID = req.PostID
-	user.RoleID = req.RoleID
 	user.Status = req.Status
 	user.Remark = req.Remark
 
-	return repository.GetUserRepository().Update(user)
+	if err := repository.GetUserRepository().UpdateWithRoles(user, req.RoleIDs, userProfileColumns...); err != nil {
+		return err
+	}
+
+	if revokeTokens {
+		return s.sessionService.RevokeUserTokens(user.ID, "用户角色或状态变更")
+	}
+	return nil
 }
 
 // DeleteUser 删除用户
-func (s *UserServiceImpl) DeleteUser(id uint) error {
-	return repository.GetUserRepository().Delete(id)
+func (s *UserServiceImpl) DeleteUser(operatorID uint, id uint) error {
+	return s.BatchDeleteUser(operatorID, []uint{id})
 }
 
 // BatchDeleteUser 批量删除用户
-func (s *UserServiceImpl) BatchDeleteUser(ids []uint) error {
+func (s *UserServiceImpl) BatchDeleteUser(operatorID uint, ids []uint) error {
+	// 内置用户和当前登录用户不允许删除
+	for _, id := range ids {
+		user, err := repository.GetUserRepository().FindByID(id)
+		if err != nil {
+			return err
+		}
+		if err := checkUserRemovable(operatorID, user); err != nil {
+			return err
+		}
+		if err := checkUserDataScope(operatorID, user); err != nil {
+			return err
+		}
+	}
+
 	return repository.GetUserRepository().BatchDelete(ids)
 }
 
 // ChangeUserStatus 修改用户状态
-func (s *UserServiceImpl) ChangeUserStatus(req *dto.StatusReq) error {
-	return repository.GetUserRepository().UpdateStatus(req.ID, int(req.Status))
+func (s *UserServiceImpl) ChangeUserStatus(operatorID uint, req *dto.StatusReq) error {
+	// 检查用户是否存在
+	user, err := repository.GetUserRepository().FindByID(req.ID)
+	if err != nil {
+		return err
+	}
+
+	// 检查用户是否在操作者的数据权限范围内
+	if err := checkUserDataScope(operatorID, user); err != nil {
+		return err
+	}
+
+	// 内置用户和当前登录用户不允许禁用
+	if req.Status != 1 {
+		if err := checkUserRemovable(operatorID, user); err != nil {
+			return err
+		}
+	}
+
+	if err := repository.GetUserRepository().UpdateStatus(req.ID, int(req.Status)); err != nil {
+		return err
+	}
+
+	// 禁用后已签发的token立即失效
+	if req.Status != 1 {
+		return s.sessionService.RevokeUserTokens(req.ID, "用户被禁用")
+	}
+	return nil
 }
 
-// ResetUserPassword 重置用户密码
-func (s *UserServiceImpl) ResetUserPassword(id uint) error {
-	// 生成新密码哈希(默认密码为123456)
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
+// checkUserRemovable 检查用户是否允许删除或禁用
+func checkUserRemovable(operatorID uint, user *model.User) error {
+	if user.IsBuiltin {
+		return kperrors.New(kperrors.ErrUserBuiltin, nil)
+	}
+	if operatorID != 0 && user.ID == operatorID {
+		return kperrors.New(kperrors.ErrUserSelfOperate, nil)
+	}
+	return nil
+}
+
+// CheckUserManageable 检查操作者是否可以管理指定用户
+func (s *UserServiceImpl) CheckUserManageable(operatorID uint, userID uint) error {
+	user, err := repository.GetUserRepository().FindByID(userID)
 	if err != nil {
-		return kperrors.New(kperrors.ErrSystem, err)
+		return err
 	}
+	return checkUserManageable(operatorID, user)
+}
 
-	return repository.GetUserRepository().ResetPassword(id, string(hashedPassword))
+// checkUserManageable 检查操作者是否可以管理用户的账号及凭据
+// 内置用户仅允许本人或超级管理员操作，避免其他管理员通过重置凭据接管内置用户
+func checkUserManageable(operatorID uint, user *model.User) error {
+	if user.IsBuiltin && operatorID != 0 && operatorID != user.ID {
+		superAdmin, err := isSuperAdmin(operatorID)
+		if err != nil {
+			return err
+		}
+		if !superAdmin {
+			return kperrors.New(kperrors.ErrUserBuiltin, nil).WithMessage("仅超级管理员可以操作内置用户")
+		}
+	}
+	return checkUserDataScope(operatorID, user)
+}
+
+// isSuperAdmin 检查用户的有效角色中是否包含超级管理员角色
+func isSuperAdmin(userID uint) (bool, error) {
+	superAdmin := config.GetCasbinConfig().SuperAdmin
+	if superAdmin == "" {
+		return false, nil
+	}
+
+	roles, err := repository.GetRoleRepository().FindEffectiveByUserID(userID)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if role.Code == superAdmin {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkUserDataScope 检查用户是否在操作者的数据权限范围内，操作者为0时表示系统操作不做限制
+func checkUserDataScope(operatorID uint, user *model.User) error {
+	if operatorID == 0 {
+		return nil
+	}
+
+	scope, err := (&RoleServiceImpl{}).GetDataScope(operatorID)
+	if err != nil {
+		return err
+	}
+	if scope.All || (scope.Self && user.ID == operatorID) || slices.Contains(scope.DeptIDs, user.DeptID) {
+		return nil
+	}
+	return kperrors.New(kperrors.ErrPermData, nil)
+}
+
+// checkBuiltinRolesKept 检查内置用户变更后的角色是否保留了原有的内置角色
+func checkBuiltinRolesKept(userID uint, roleIDs []uint) error {
+	roles, err := repository.GetRoleRepository().FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	kept := make(map[uint]bool, len(roleIDs))
+	for _, id := range roleIDs {
+		kept[id] = true
+	}
+	for _, role := range roles {
+		if role.IsBuiltin && !kept[role.ID] {
+			return kperrors.New(kperrors.ErrUserBuiltin, nil)
+		}
+	}
+	return nil
+}
+
+// ResetUserPassword 重置用户密码为随机生成的一次性密码，用户使用该密码登录后必须修改密码
+func (s *UserServiceImpl) ResetUserPassword(operatorID uint, id uint) (string, error) {
+	// 检查用户是否存在
+	user, err := repository.GetUserRepository().FindByID(id)
+	if err != nil {
+		return "", err
+	}
+
+	// 检查用户是否可由操作者管理
+	if err := checkUserManageable(operatorID, user); err != nil {
+		return "", err
+	}
+
+	otp, err := password.GetPolicy().Generate()
+	if err != nil {
+		return "", kperrors.New(kperrors.ErrSystem, err)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(otp), bcrypt.DefaultCost)
+	if err != nil {
+		return "", kperrors.New(kperrors.ErrSystem, err)
+	}
+
+	if err := repository.GetUserRepository().ResetPassword(id, string(hashedPassword)); err != nil {
+		return "", err
+	}
+
+	if err := s.sessionService.RevokeUserTokens(id, "管理员重置密码"); err != nil {
+		return "", err
+	}
+	return otp, nil
 }
 
 // ChangePassword 修改密码
@@ -345,11 +921,64 @@ func (s *UserServiceImpl) ChangePassword(userID uint, req *dto.UserChangePasswor
 		return kperrors.New(kperrors.ErrUserOldPassword, err)
 	}
 
+	// 检查新密码是否符合密码策略且未被使用过
+	if err := checkNewPassword(user, req.NewPassword); err != nil {
+		return err
+	}
+
 	// 生成新密码哈希
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
 		return kperrors.New(kperrors.ErrSystem, err)
 	}
 
-	return repository.GetUserRepository().UpdatePassword(userID, string(hashedPassword))
+	if err := repository.GetUserRepository().UpdatePassword(userID, string(hashedPassword)); err != nil {
+		return err
+	}
+	if err := recordPasswordHistory(userID, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	// 修改密码后全部登录需重新登录
+	return s.sessionService.RevokeUserTokens(userID, "用户修改密码")
+}
+
+// sameIDs 判断两个ID列表包含的ID是否相同，忽略顺序和重复
+func sameIDs(a, b []uint) bool {
+	set := make(map[uint]bool, len(a))
+	for _, id := range a {
+		set[id] = true
+	}
+	other := make(map[uint]bool, len(b))
+	for _, id := range b {
+		if !set[id] {
+			return false
+		}
+		other[id] = true
+	}
+	return len(set) == len(other)
+}
+
+// checkRoleIDs 检查角色ID列表中的角色是否都存在
+func checkRoleIDs(roleIDs []uint) error {
+	if len(roleIDs) == 0 {
+		return nil
+	}
+
+	roles, err := repository.GetRoleRepository().FindByIDs(roleIDs)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[uint]struct{}, len(roles))
+	for _, role := range roles {
+		found[role.ID] = struct{}{}
+	}
+	for _, roleID := range roleIDs {
+		if _, ok := found[roleID]; !ok {
+			return kperrors.New(kperrors.ErrRoleNotFound, nil)
+		}
+	}
+
+	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
 	"github.com/cuiyuanxin/kunpeng/internal/repository"
 	"github.com/cuiyuanxin/kunpeng/internal/service/impl"
+	"github.com/cuiyuanxin/kunpeng/pkg/revocation"
 )
 
 var (
@@ -20,6 +21,14 @@ var (
 	operationLogService   service.OperationLogService
 	loginAttemptService   service.LoginAttemptService
 	tokenBlacklistService service.TokenBlacklistService
+	permissionService     service.PermissionService
+	captchaService        service.CaptchaService
+	smsService            service.SMSService
+	twoFactorService      service.TwoFactorService
+	sessionService        service.SessionService
+	passwordResetService  service.PasswordResetService
+	oidcService           service.OIDCService
+	apiSignatureService   service.APISignatureService
 	once                  sync.Once
 )
 
@@ -89,15 +98,84 @@ func GetTokenBlacklistService() service.TokenBlacklistService {
 	return tokenBlacklistService
 }
 
+// GetCaptchaService 获取图片验证码服务
+func GetCaptchaService() service.CaptchaService {
+	once.Do(initService)
+	return captchaService
+}
+
+// GetSMSService 获取短信验证码服务
+func GetSMSService() service.SMSService {
+	once.Do(initService)
+	return smsService
+}
+
+// GetTwoFactorService 获取双因素认证服务
+func GetTwoFactorService() service.TwoFactorService {
+	once.Do(initService)
+	return twoFactorService
+}
+
+// GetSessionService 获取用户登录会话服务
+func GetSessionService() service.SessionService {
+	once.Do(initService)
+	return sessionService
+}
+
+// GetPasswordResetService 获取找回密码服务
+func GetPasswordResetService() service.PasswordResetService {
+	once.Do(initService)
+	return passwordResetService
+}
+
+// GetOIDCService 获取单点登录服务
+func GetOIDCService() service.OIDCService {
+	once.Do(initService)
+	return oidcService
+}
+
+// GetAPISignatureService 获取签名认证服务
+func GetAPISignatureService() service.APISignatureService {
+	once.Do(initService)
+	return apiSignatureService
+}
+
+// GetPermissionService 获取权限服务
+func GetPermissionService() service.PermissionService {
+	once.Do(initService)
+	return permissionService
+}
+
 // initService 初始化服务
 func initService() {
 	// 初始化登录尝试服务
 	loginAttemptService = impl.NewLoginAttemptService(repository.GetLoginAttemptRepository())
 	// 初始化token黑名单服务
-	tokenBlacklistService = impl.NewTokenBlacklistService(repository.GetTokenBlacklistRepository())
+	tokenBlacklistService = impl.NewTokenBlacklistService(repository.GetTokenBlacklistRepository(), revocation.GetStore())
 
-	// 初始化用户服务（需要依赖登录尝试服务）
-	userService = impl.NewUserService(loginAttemptService)
+	// 初始化图片验证码服务（需要依赖登录尝试服务）
+	captchaService = impl.NewCaptchaService(loginAttemptService)
+
+	// 初始化短信验证码服务
+	smsService = impl.NewSMSService(repository.GetSMSCodeRepository(), repository.GetUserRepository())
+
+	// 初始化双因素认证服务
+	twoFactorService = impl.NewTwoFactorService(repository.GetTwoFactorRepository(), repository.GetUserRepository())
+
+	// 初始化用户登录会话服务（需要依赖token黑名单服务）
+	sessionService = impl.NewSessionService(repository.GetUserSessionRepository(), repository.GetRefreshTokenRepository(), repository.GetUserRepository(), tokenBlacklistService)
+
+	// 初始化单点登录服务
+	oidcService = impl.NewOIDCService(repository.GetOIDCStateRepository(), repository.GetUserIdentityRepository(), repository.GetUserRepository())
+
+	// 初始化用户服务（需要依赖登录尝试、图片验证码、短信验证码、双因素认证、token黑名单、用户登录会话和单点登录服务）
+	userService = impl.NewUserService(loginAttemptService, captchaService, smsService, twoFactorService, tokenBlacklistService, sessionService, oidcService)
+
+	// 初始化找回密码服务（需要依赖用户登录会话和登录尝试服务）
+	passwordResetService = impl.NewPasswordResetService(repository.GetPasswordResetRepository(), repository.GetUserRepository(), sessionService, loginAttemptService)
+
+	// 初始化签名认证服务（需要依赖双因素认证服务和登录尝试服务）
+	apiSignatureService = impl.NewAPISignatureService(repository.GetUserRepository(), repository.GetAPINonceRepository(), twoFactorService, loginAttemptService)
 
 	// 初始化其他服务
 	roleService = &impl.RoleServiceImpl{}
@@ -108,4 +186,7 @@ func initService() {
 	dictService = &impl.DictServiceImpl{}
 	loginLogService = &impl.LoginLogServiceImpl{}
 	operationLogService = &impl.OperationLogServiceImpl{}
+
+	// 初始化权限服务（需要依赖角色服务）
+	permissionService = impl.NewPermissionService(roleService)
 }
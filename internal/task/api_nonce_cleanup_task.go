@@ -0,0 +1,28 @@
+package task
+
+import (
+	"context"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
+	serviceImpl "github.com/cuiyuanxin/kunpeng/internal/service"
+)
+
+// APINonceCleanupTask 签名请求随机串清理任务
+type APINonceCleanupTask struct {
+	apiSignatureService service.APISignatureService
+}
+
+// NewAPINonceCleanupTask 创建签名请求随机串清理任务
+func NewAPINonceCleanupTask() *APINonceCleanupTask {
+	return &APINonceCleanupTask{
+		apiSignatureService: serviceImpl.GetAPISignatureService(),
+	}
+}
+
+// Run 清理过期的随机串记录
+func (t *APINonceCleanupTask) Run(_ context.Context) (string, error) {
+	if err := t.apiSignatureService.CleanupExpiredNonces(); err != nil {
+		return "", err
+	}
+	return "已清理过期的签名请求随机串", nil
+}
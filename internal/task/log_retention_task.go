@@ -0,0 +1,53 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
+	"github.com/cuiyuanxin/kunpeng/internal/repository"
+	serviceImpl "github.com/cuiyuanxin/kunpeng/internal/service"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+)
+
+// LogRetentionTask 日志保留期清理任务
+type LogRetentionTask struct {
+	loginLogService     service.LoginLogService
+	operationLogService service.OperationLogService
+}
+
+// NewLogRetentionTask 创建日志保留期清理任务
+func NewLogRetentionTask() *LogRetentionTask {
+	return &LogRetentionTask{
+		loginLogService:     serviceImpl.GetLoginLogService(),
+		operationLogService: serviceImpl.GetOperationLogService(),
+	}
+}
+
+// Run 清理超过保留天数的登录日志、操作日志及任务执行记录
+func (t *LogRetentionTask) Run(_ context.Context) (string, error) {
+	cfg := config.GetSchedulerConfig()
+
+	var results []string
+	if cfg.LogRetentionDays > 0 {
+		if err := t.loginLogService.CleanOldLogs(cfg.LogRetentionDays); err != nil {
+			return "", err
+		}
+		if err := t.operationLogService.CleanOldLogs(cfg.LogRetentionDays); err != nil {
+			return "", err
+		}
+		results = append(results, fmt.Sprintf("已清理%d天前的登录日志及操作日志", cfg.LogRetentionDays))
+	}
+	if cfg.HistoryRetentionDays > 0 {
+		if err := repository.GetJobLogRepository().CleanOldLogs(cfg.HistoryRetentionDays); err != nil {
+			return "", err
+		}
+		results = append(results, fmt.Sprintf("已清理%d天前的任务执行记录", cfg.HistoryRetentionDays))
+	}
+
+	if len(results) == 0 {
+		return "未配置日志保留天数，无需清理", nil
+	}
+	return strings.Join(results, "；"), nil
+}
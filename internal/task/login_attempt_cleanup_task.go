@@ -0,0 +1,28 @@
+package task
+
+import (
+	"context"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
+	serviceImpl "github.com/cuiyuanxin/kunpeng/internal/service"
+)
+
+// LoginAttemptCleanupTask 登录尝试记录清理任务
+type LoginAttemptCleanupTask struct {
+	loginAttemptService service.LoginAttemptService
+}
+
+// NewLoginAttemptCleanupTask 创建登录尝试记录清理任务
+func NewLoginAttemptCleanupTask() *LoginAttemptCleanupTask {
+	return &LoginAttemptCleanupTask{
+		loginAttemptService: serviceImpl.GetLoginAttemptService(),
+	}
+}
+
+// Run 清理过期的登录尝试记录
+func (t *LoginAttemptCleanupTask) Run(_ context.Context) (string, error) {
+	if err := t.loginAttemptService.CleanupExpiredRecords(); err != nil {
+		return "", err
+	}
+	return "已清理过期的登录尝试记录", nil
+}
@@ -0,0 +1,28 @@
+package task
+
+import (
+	"context"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
+	serviceImpl "github.com/cuiyuanxin/kunpeng/internal/service"
+)
+
+// OIDCStateCleanupTask 单点登录授权请求清理任务
+type OIDCStateCleanupTask struct {
+	oidcService service.OIDCService
+}
+
+// NewOIDCStateCleanupTask 创建单点登录授权请求清理任务
+func NewOIDCStateCleanupTask() *OIDCStateCleanupTask {
+	return &OIDCStateCleanupTask{
+		oidcService: serviceImpl.GetOIDCService(),
+	}
+}
+
+// Run 清理过期的单点登录授权请求记录
+func (t *OIDCStateCleanupTask) Run(_ context.Context) (string, error) {
+	if err := t.oidcService.CleanupExpiredStates(); err != nil {
+		return "", err
+	}
+	return "已清理过期的单点登录授权请求记录", nil
+}
@@ -0,0 +1,28 @@
+package task
+
+import (
+	"context"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
+	serviceImpl "github.com/cuiyuanxin/kunpeng/internal/service"
+)
+
+// PasswordResetCleanupTask 重置密码记录清理任务
+type PasswordResetCleanupTask struct {
+	passwordResetService service.PasswordResetService
+}
+
+// NewPasswordResetCleanupTask 创建重置密码记录清理任务
+func NewPasswordResetCleanupTask() *PasswordResetCleanupTask {
+	return &PasswordResetCleanupTask{
+		passwordResetService: serviceImpl.GetPasswordResetService(),
+	}
+}
+
+// Run 清理过期的重置密码记录
+func (t *PasswordResetCleanupTask) Run(_ context.Context) (string, error) {
+	if err := t.passwordResetService.CleanupExpiredTokens(); err != nil {
+		return "", err
+	}
+	return "已清理过期的重置密码记录", nil
+}
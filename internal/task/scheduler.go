@@ -0,0 +1,362 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/internal/model"
+	"github.com/cuiyuanxin/kunpeng/internal/model/dto"
+	"github.com/cuiyuanxin/kunpeng/internal/repository"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// maxMessageLength 执行结果的最大长度，与 kp_job_log.message 一致
+const maxMessageLength = 500
+
+// JobFunc 任务执行函数，返回执行结果描述，服务关闭时ctx被取消
+type JobFunc func(ctx context.Context) (string, error)
+
+// entry 已注册的任务
+type entry struct {
+	name        string
+	description string
+	spec        string
+	schedule    schedule
+	fn          JobFunc
+	paused      bool
+	running     bool
+	next        time.Time
+}
+
+// Scheduler 定时任务调度器
+// 任务由代码注册，暂停状态保存在 kp_job 表中由多实例共享，每次执行记录写入 kp_job_log 表
+// 多实例部署时各实例均会按计划执行任务，任务需保证重复执行无副作用
+type Scheduler struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	order   []string
+	started bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+var scheduler = NewScheduler()
+
+// GetScheduler 获取定时任务调度器
+func GetScheduler() *Scheduler {
+	return scheduler
+}
+
+// NewScheduler 创建定时任务调度器
+func NewScheduler() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		entries: make(map[string]*entry),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Register 注册任务，spec为默认的cron表达式，可通过 scheduler.jobs 配置按任务名称覆盖
+func (s *Scheduler) Register(name, description, spec string, fn JobFunc) error {
+	if override := config.GetSchedulerConfig().Jobs[name]; override != "" {
+		spec = override
+	}
+	sched, err := parseCron(spec)
+	if err != nil {
+		return fmt.Errorf("任务 %s 的cron表达式无效: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[name]; ok {
+		return fmt.Errorf("任务 %s 重复注册", name)
+	}
+	s.entries[name] = &entry{
+		name:        name,
+		description: description,
+		spec:        spec,
+		schedule:    sched,
+		fn:          fn,
+	}
+	s.order = append(s.order, name)
+	return nil
+}
+
+// Start 加载任务的暂停状态并开始按计划执行
+func (s *Scheduler) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return nil
+	}
+
+	jobs, err := repository.GetJobRepository().FindAll()
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if e, ok := s.entries[job.Name]; ok {
+			e.paused = job.Status == model.JobStatusPaused
+		}
+	}
+
+	s.started = true
+	for _, name := range s.order {
+		s.wg.Add(1)
+		go s.loop(s.entries[name])
+	}
+
+	logger.GetLogger().Info("定时任务调度器已启动", zap.Int("jobs", len(s.order)))
+	return nil
+}
+
+// Stop 停止调度器，等待正在执行的任务结束
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return
+	}
+	s.cancel()
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// List 获取全部任务及最近一次执行情况
+func (s *Scheduler) List() ([]*dto.JobResp, error) {
+	jobs, err := repository.GetJobRepository().FindAll()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[string]int8, len(jobs))
+	for _, job := range jobs {
+		statuses[job.Name] = job.Status
+	}
+
+	s.mu.Lock()
+	result := make([]*dto.JobResp, 0, len(s.order))
+	for _, name := range s.order {
+		e := s.entries[name]
+		resp := &dto.JobResp{
+			Name:        e.name,
+			Description: e.description,
+			Spec:        e.spec,
+			Status:      model.JobStatusNormal,
+			Running:     e.running,
+		}
+		if status, ok := statuses[name]; ok {
+			resp.Status = status
+		}
+		if resp.Status == model.JobStatusNormal && s.started && !e.next.IsZero() {
+			next := e.next
+			resp.NextRunAt = &next
+		}
+		result = append(result, resp)
+	}
+	s.mu.Unlock()
+
+	for _, resp := range result {
+		latest, err := repository.GetJobLogRepository().FindLatest(resp.Name)
+		if err != nil {
+			if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		resp.LastRunAt = &latest.StartedAt
+		resp.LastStatus = &latest.Status
+		resp.LastMessage = latest.Message
+	}
+
+	return result, nil
+}
+
+// Trigger 立即异步执行一次任务，已暂停的任务也可手动执行
+func (s *Scheduler) Trigger(name, operator string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[name]
+	if !ok {
+		return kperrors.New(kperrors.ErrJobNotFound, nil)
+	}
+	if !s.started || s.ctx.Err() != nil {
+		return kperrors.New(kperrors.ErrJobNotStarted, nil)
+	}
+	if e.running {
+		return kperrors.New(kperrors.ErrJobRunning, nil)
+	}
+	e.running = true
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.execute(e, model.JobTriggerManual, operator)
+	}()
+	return nil
+}
+
+// Pause 暂停任务，对全部实例生效
+func (s *Scheduler) Pause(name string) error {
+	return s.setStatus(name, model.JobStatusPaused)
+}
+
+// Resume 恢复任务，对全部实例生效
+func (s *Scheduler) Resume(name string) error {
+	return s.setStatus(name, model.JobStatusNormal)
+}
+
+// GetLogList 获取任务执行记录列表
+func (s *Scheduler) GetLogList(req *dto.JobLogListReq) (*dto.PageResp, error) {
+	logs, total, err := repository.GetJobLogRepository().FindList(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 计算总页数
+	totalPages := int(math.Ceil(float64(total) / float64(req.PageSize)))
+
+	return &dto.PageResp{
+		List:       dto.ToJobLogRespList(logs),
+		Total:      total,
+		PageNum:    req.PageNum,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// setStatus 保存任务状态
+func (s *Scheduler) setStatus(name string, status int8) error {
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	s.mu.Unlock()
+	if !ok {
+		return kperrors.New(kperrors.ErrJobNotFound, nil)
+	}
+
+	if err := repository.GetJobRepository().SaveStatus(name, status); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	e.paused = status == model.JobStatusPaused
+	s.mu.Unlock()
+	return nil
+}
+
+// loop 按计划执行任务，直到调度器停止
+func (s *Scheduler) loop(e *entry) {
+	defer s.wg.Done()
+
+	for {
+		next := e.schedule.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+		s.mu.Lock()
+		e.next = next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if s.isPaused(e) {
+			continue
+		}
+
+		s.mu.Lock()
+		if e.running {
+			s.mu.Unlock()
+			logger.GetLogger().Warn("定时任务上一次执行尚未结束，跳过本次执行", zap.String("job", e.name))
+			continue
+		}
+		e.running = true
+		s.mu.Unlock()
+
+		s.execute(e, model.JobTriggerSchedule, "")
+	}
+}
+
+// isPaused 判断任务是否已暂停，优先读取数据库中的状态以同步其他实例的变更
+func (s *Scheduler) isPaused(e *entry) bool {
+	job, err := repository.GetJobRepository().FindByName(e.name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		e.paused = job.Status == model.JobStatusPaused
+	} else if kperrors.IsCode(err, kperrors.ErrDBNotFound) {
+		e.paused = false
+	}
+	return e.paused
+}
+
+// execute 执行任务并记录执行结果，调用前需将任务标记为执行中
+func (s *Scheduler) execute(e *entry, trigger, operator string) {
+	defer func() {
+		s.mu.Lock()
+		e.running = false
+		s.mu.Unlock()
+	}()
+
+	log := &model.JobLog{
+		JobName:   e.name,
+		Trigger:   trigger,
+		Operator:  operator,
+		Status:    model.JobLogStatusRunning,
+		StartedAt: time.Now(),
+	}
+	if err := repository.GetJobLogRepository().Create(log); err != nil {
+		logger.GetLogger().Error("记录定时任务执行记录失败", zap.String("job", e.name), zap.Error(err))
+	}
+
+	message, err := s.call(e)
+
+	finishedAt := time.Now()
+	log.FinishedAt = &finishedAt
+	log.Duration = finishedAt.Sub(log.StartedAt).Milliseconds()
+	if err != nil {
+		log.Status = model.JobLogStatusFailed
+		message = err.Error()
+		logger.GetLogger().Error("定时任务执行失败", zap.String("job", e.name), zap.String("trigger", trigger), zap.Error(err))
+	} else {
+		log.Status = model.JobLogStatusSuccess
+		logger.GetLogger().Info("定时任务执行完成", zap.String("job", e.name), zap.String("trigger", trigger), zap.String("result", message))
+	}
+	if runes := []rune(message); len(runes) > maxMessageLength {
+		message = string(runes[:maxMessageLength])
+	}
+	log.Message = message
+
+	if log.ID != 0 {
+		if err := repository.GetJobLogRepository().Update(log); err != nil {
+			logger.GetLogger().Error("记录定时任务执行结果失败", zap.String("job", e.name), zap.Error(err))
+		}
+	}
+}
+
+// call 调用任务执行函数，任务panic时视为执行失败
+func (s *Scheduler) call(e *entry) (message string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("任务执行异常: %v", r)
+		}
+	}()
+	return e.fn(s.ctx)
+}
@@ -0,0 +1,28 @@
+package task
+
+import (
+	"context"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
+	serviceImpl "github.com/cuiyuanxin/kunpeng/internal/service"
+)
+
+// SessionCleanupTask 登录会话清理任务
+type SessionCleanupTask struct {
+	sessionService service.SessionService
+}
+
+// NewSessionCleanupTask 创建登录会话清理任务
+func NewSessionCleanupTask() *SessionCleanupTask {
+	return &SessionCleanupTask{
+		sessionService: serviceImpl.GetSessionService(),
+	}
+}
+
+// Run 清理过期的登录会话及refresh token记录
+func (t *SessionCleanupTask) Run(_ context.Context) (string, error) {
+	if err := t.sessionService.CleanupExpiredSessions(); err != nil {
+		return "", err
+	}
+	return "已清理过期的登录会话及refresh token记录", nil
+}
@@ -0,0 +1,28 @@
+package task
+
+import (
+	"context"
+
+	"github.com/cuiyuanxin/kunpeng/internal/interfaces/service"
+	serviceImpl "github.com/cuiyuanxin/kunpeng/internal/service"
+)
+
+// SMSCodeCleanupTask 短信验证码清理任务
+type SMSCodeCleanupTask struct {
+	smsService service.SMSService
+}
+
+// NewSMSCodeCleanupTask 创建短信验证码清理任务
+func NewSMSCodeCleanupTask() *SMSCodeCleanupTask {
+	return &SMSCodeCleanupTask{
+		smsService: serviceImpl.GetSMSService(),
+	}
+}
+
+// Run 清理过期的短信验证码记录
+func (t *SMSCodeCleanupTask) Run(_ context.Context) (string, error) {
+	if err := t.smsService.CleanupExpiredCodes(); err != nil {
+		return "", err
+	}
+	return "已清理过期的短信验证码记录", nil
+}
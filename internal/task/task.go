@@ -0,0 +1,76 @@
+package task
+
+import (
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+)
+
+// 内置任务名称
+const (
+	JobTokenCleanup         = "token_cleanup"
+	JobLoginAttemptCleanup  = "login_attempt_cleanup"
+	JobLogRetention         = "log_retention"
+	JobAPINonceCleanup      = "api_nonce_cleanup"
+	JobSessionCleanup       = "session_cleanup"
+	JobSMSCodeCleanup       = "sms_code_cleanup"
+	JobPasswordResetCleanup = "password_reset_cleanup"
+	JobOIDCStateCleanup     = "oidc_state_cleanup"
+)
+
+// Start 注册内置任务并启动调度器，scheduler.enable 为false时不启动
+// 需在数据库及token撤销存储初始化之后调用
+func Start() error {
+	if !config.GetSchedulerConfig().Enable {
+		return nil
+	}
+
+	if err := registerJobs(scheduler); err != nil {
+		return err
+	}
+	return scheduler.Start()
+}
+
+// Stop 停止调度器，等待正在执行的任务结束
+func Stop() {
+	scheduler.Stop()
+}
+
+// registerJobs 注册内置任务
+func registerJobs(s *Scheduler) error {
+	// 每天凌晨2点清理过期的token黑名单记录
+	if err := s.Register(JobTokenCleanup, "清理过期的token黑名单记录", "0 2 * * *", NewTokenCleanupTask().Run); err != nil {
+		return err
+	}
+
+	// 每小时清理过期的登录尝试记录
+	if err := s.Register(JobLoginAttemptCleanup, "清理过期的登录尝试记录", "0 * * * *", NewLoginAttemptCleanupTask().Run); err != nil {
+		return err
+	}
+
+	// 每天凌晨3点半清理超过保留天数的日志及任务执行记录
+	if err := s.Register(JobLogRetention, "清理超过保留天数的登录日志、操作日志及任务执行记录", "30 3 * * *", NewLogRetentionTask().Run); err != nil {
+		return err
+	}
+
+	// 每小时清理过期的签名请求随机串
+	if err := s.Register(JobAPINonceCleanup, "清理过期的签名请求随机串", "15 * * * *", NewAPINonceCleanupTask().Run); err != nil {
+		return err
+	}
+
+	// 每天凌晨4点清理过期的登录会话及refresh token记录
+	if err := s.Register(JobSessionCleanup, "清理过期的登录会话及refresh token记录", "0 4 * * *", NewSessionCleanupTask().Run); err != nil {
+		return err
+	}
+
+	// 每天凌晨4点10分清理过期的短信验证码记录
+	if err := s.Register(JobSMSCodeCleanup, "清理过期的短信验证码记录", "10 4 * * *", NewSMSCodeCleanupTask().Run); err != nil {
+		return err
+	}
+
+	// 每天凌晨4点20分清理过期的重置密码记录
+	if err := s.Register(JobPasswordResetCleanup, "清理过期的重置密码记录", "20 4 * * *", NewPasswordResetCleanupTask().Run); err != nil {
+		return err
+	}
+
+	// 每小时清理过期的单点登录授权请求记录
+	return s.Register(JobOIDCStateCleanup, "清理过期的单点登录授权请求记录", "45 * * * *", NewOIDCStateCleanupTask().Run)
+}
@@ -0,0 +1,260 @@
+package captcha
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math/big"
+	mrand "math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// 验证码类型
+const (
+	TypeDigit = "digit" // 数字验证码
+	TypeMath  = "math"  // 算术验证码
+)
+
+// 默认配置
+const (
+	defaultLength = 4
+	defaultWidth  = 120
+	defaultHeight = 40
+	defaultTTL    = 5 * time.Minute
+)
+
+var (
+	store   Store = NewMemoryStore(DefaultMaxEntries)
+	storeMu sync.RWMutex
+)
+
+// SetStore 替换验证码存储，需在服务启动时调用
+func SetStore(s Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+// getStore 获取验证码存储
+func getStore() Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return store
+}
+
+// Generate 生成图片验证码，返回验证码ID和Base64编码的PNG图片
+func Generate() (string, string, error) {
+	cfg := config.GetCaptchaConfig()
+
+	var text, answer string
+	var err error
+	switch cfg.Type {
+	case TypeMath:
+		text, answer, err = randomMath()
+	default:
+		length := cfg.Length
+		if length <= 0 {
+			length = defaultLength
+		}
+		text, err = randomDigits(length)
+		answer = text
+	}
+	if err != nil {
+		return "", "", kperrors.New(kperrors.ErrSystem, err)
+	}
+
+	width, height := cfg.Width, cfg.Height
+	if width <= 0 {
+		width = defaultWidth
+	}
+	if height <= 0 {
+		height = defaultHeight
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, render(text, width, height)); err != nil {
+		return "", "", kperrors.New(kperrors.ErrSystem, err)
+	}
+
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	id := uuid.NewString()
+	if err := getStore().Set(id, answer, ttl); err != nil {
+		return "", "", kperrors.New(kperrors.ErrSystem, err)
+	}
+
+	return id, "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// Verify 校验验证码，无论校验是否通过验证码都会失效
+func Verify(id string, answer string) (bool, error) {
+	if id == "" || answer == "" {
+		return false, nil
+	}
+
+	expected, ok, err := getStore().Get(id, true)
+	if err != nil {
+		return false, kperrors.New(kperrors.ErrSystem, err)
+	}
+	if !ok {
+		return false, nil
+	}
+
+	return strings.TrimSpace(answer) == expected, nil
+}
+
+// randomDigits 生成指定长度的随机数字
+func randomDigits(length int) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(n.String())
+	}
+	return sb.String(), nil
+}
+
+// randomMath 生成随机算术题，返回题目和答案
+func randomMath() (string, string, error) {
+	nums := make([]int, 3)
+	for i := range nums {
+		n, err := rand.Int(rand.Reader, big.NewInt(9))
+		if err != nil {
+			return "", "", err
+		}
+		nums[i] = int(n.Int64()) + 1
+	}
+
+	a, b := nums[0], nums[1]
+	switch nums[2] % 3 {
+	case 0:
+		return fmt.Sprintf("%d+%d=?", a, b), strconv.Itoa(a + b), nil
+	case 1:
+		if a < b {
+			a, b = b, a
+		}
+		return fmt.Sprintf("%d-%d=?", a, b), strconv.Itoa(a - b), nil
+	default:
+		return fmt.Sprintf("%dx%d=?", a, b), strconv.Itoa(a * b), nil
+	}
+}
+
+// render 将文本绘制为带干扰的图片
+func render(text string, width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	// 背景
+	bg := color.RGBA{uint8(230 + mrand.IntN(25)), uint8(230 + mrand.IntN(25)), uint8(230 + mrand.IntN(25)), 255}
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, bg)
+		}
+	}
+
+	// 干扰点
+	for i := 0; i < width*height/20; i++ {
+		img.Set(mrand.IntN(width), mrand.IntN(height), randomColor(120, 220))
+	}
+
+	// 按可用空间计算字模缩放比例
+	runes := []rune(text)
+	cell := width / (len(runes) + 1)
+	scale := min(cell/(glyphWidth+1), height*3/4/glyphHeight)
+	if scale < 1 {
+		scale = 1
+	}
+
+	offsetX := (width - cell*len(runes)) / 2
+	for i, r := range runes {
+		glyph, ok := glyphs[r]
+		if !ok {
+			continue
+		}
+
+		c := randomColor(20, 120)
+		x0 := offsetX + i*cell + (cell-glyphWidth*scale)/2
+		y0 := (height-glyphHeight*scale)/2 + mrand.IntN(scale*2+1) - scale
+		for row := 0; row < glyphHeight; row++ {
+			for col := 0; col < glyphWidth; col++ {
+				if glyph[row]&(1<<(glyphWidth-1-col)) == 0 {
+					continue
+				}
+				for dx := 0; dx < scale; dx++ {
+					for dy := 0; dy < scale; dy++ {
+						img.Set(x0+col*scale+dx, y0+row*scale+dy, c)
+					}
+				}
+			}
+		}
+	}
+
+	// 干扰线
+	for i := 0; i < 3; i++ {
+		drawLine(img, mrand.IntN(width/4), mrand.IntN(height), width-1-mrand.IntN(width/4), mrand.IntN(height), randomColor(60, 160))
+	}
+
+	return img
+}
+
+// randomColor 生成各分量在[low, high)范围内的随机颜色
+func randomColor(low, high int) color.RGBA {
+	return color.RGBA{
+		R: uint8(low + mrand.IntN(high-low)),
+		G: uint8(low + mrand.IntN(high-low)),
+		B: uint8(low + mrand.IntN(high-low)),
+		A: 255,
+	}
+}
+
+// drawLine 使用Bresenham算法绘制直线
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	e := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// abs 求绝对值
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,101 @@
+package captcha
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Store 验证码存储接口，可替换为Redis等共享存储以支持多实例部署
+type Store interface {
+	// Set 保存验证码答案，过期后自动失效
+	Set(id string, answer string, ttl time.Duration) error
+
+	// Get 获取验证码答案，clear为true时同时删除，不存在或已过期时返回false
+	Get(id string, clear bool) (string, bool, error)
+}
+
+// memoryItem 内存存储条目
+type memoryItem struct {
+	id       string
+	answer   string
+	expireAt time.Time
+}
+
+// DefaultMaxEntries 内存存储默认最多保存的验证码数量
+const DefaultMaxEntries = 100000
+
+// MemoryStore 内存验证码存储，仅适用于单实例部署
+// 获取验证码的接口无需登录，存储数量达到上限后淘汰最早保存的验证码，避免大量请求耗尽内存
+type MemoryStore struct {
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	order      *list.List // 按保存时间排序的验证码，最早保存的在前
+	maxEntries int
+}
+
+// NewMemoryStore 创建内存验证码存储，maxEntries为最多保存的验证码数量，小于等于0时使用默认值
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &MemoryStore{
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+		maxEntries: maxEntries,
+	}
+}
+
+// Set 保存验证码答案
+func (s *MemoryStore) Set(id string, answer string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if elem, ok := s.items[id]; ok {
+		s.remove(elem)
+	}
+	s.items[id] = s.order.PushBack(&memoryItem{
+		id:       id,
+		answer:   answer,
+		expireAt: now.Add(ttl),
+	})
+
+	// 清理最早保存且已过期的验证码，数量仍超过上限时淘汰最早保存的验证码
+	for elem := s.order.Front(); elem != nil && now.After(elem.Value.(*memoryItem).expireAt); elem = s.order.Front() {
+		s.remove(elem)
+	}
+	for s.order.Len() > s.maxEntries {
+		s.remove(s.order.Front())
+	}
+
+	return nil
+}
+
+// Get 获取验证码答案
+func (s *MemoryStore) Get(id string, clear bool) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.items[id]
+	if !ok {
+		return "", false, nil
+	}
+
+	item := elem.Value.(*memoryItem)
+	expired := time.Now().After(item.expireAt)
+	if clear || expired {
+		s.remove(elem)
+	}
+	if expired {
+		return "", false, nil
+	}
+
+	return item.answer, true, nil
+}
+
+// remove 删除验证码
+func (s *MemoryStore) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.items, elem.Value.(*memoryItem).id)
+}
@@ -0,0 +1,57 @@
+package captcha
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreGet(t *testing.T) {
+	s := NewMemoryStore(10)
+	if err := s.Set("a", "1234", time.Minute); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+
+	if answer, ok, _ := s.Get("a", false); !ok || answer != "1234" {
+		t.Fatalf("Get = %q, %v", answer, ok)
+	}
+	if answer, ok, _ := s.Get("a", true); !ok || answer != "1234" {
+		t.Fatalf("Get clear = %q, %v", answer, ok)
+	}
+	if _, ok, _ := s.Get("a", false); ok {
+		t.Fatalf("清除后仍可获取验证码")
+	}
+}
+
+func TestMemoryStoreExpired(t *testing.T) {
+	s := NewMemoryStore(10)
+	s.Set("a", "1234", -time.Second)
+	if _, ok, _ := s.Get("a", false); ok {
+		t.Fatalf("过期验证码仍可获取")
+	}
+
+	s.Set("b", "1234", -time.Second)
+	s.Set("c", "5678", time.Minute)
+	if s.order.Len() != 1 || len(s.items) != 1 {
+		t.Errorf("过期验证码未清理，剩余%d个", s.order.Len())
+	}
+}
+
+func TestMemoryStoreEvictsOldest(t *testing.T) {
+	s := NewMemoryStore(3)
+	for i := 0; i < 5; i++ {
+		s.Set(fmt.Sprint(i), fmt.Sprint(i), time.Minute)
+	}
+	// 重新保存的验证码按最新保存计算
+	s.Set("2", "2", time.Minute)
+	s.Set("5", "5", time.Minute)
+
+	if s.order.Len() != 3 || len(s.items) != 3 {
+		t.Fatalf("保存数量 = %d, want 3", s.order.Len())
+	}
+	for id, want := range map[string]bool{"0": false, "1": false, "3": false, "4": true, "2": true, "5": true} {
+		if _, ok, _ := s.Get(id, false); ok != want {
+			t.Errorf("Get(%q) ok = %v, want %v", id, ok, want)
+		}
+	}
+}
@@ -1,19 +1,24 @@
 package casbin
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/util"
 	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
 	"github.com/cuiyuanxin/kunpeng/pkg/database"
 	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
 	"github.com/cuiyuanxin/kunpeng/pkg/logger"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
+// enforcer 使用带读写锁的执行器，重新加载策略时阻塞权限检查，避免并发读写及加载期间的误判
 var (
-	enforcer *casbin.Enforcer
+	enforcer *casbin.SyncedEnforcer
 	once     sync.Once
 )
 
@@ -61,7 +66,7 @@ func Init() error {
 		}
 
 		// 创建执行器
-		enforcer, err = casbin.NewEnforcer(m, adapter)
+		enforcer, err = casbin.NewSyncedEnforcer(m, adapter)
 		if err != nil {
 			err = kperrors.New(kperrors.ErrSystem, err)
 			return
@@ -73,6 +78,24 @@ func Init() error {
 			return
 		}
 
+		// 监听其他实例的策略变更
+		w, watcherErr := newWatcher(db, config.GetCasbinConfig().Watcher)
+		if watcherErr != nil {
+			err = watcherErr
+			return
+		}
+		if w != nil {
+			if err = enforcer.SetWatcher(w); err != nil {
+				err = kperrors.New(kperrors.ErrSystem, err)
+				return
+			}
+			if err = w.SetUpdateCallback(reloadCallback); err != nil {
+				err = kperrors.New(kperrors.ErrSystem, err)
+				return
+			}
+			watcher = w
+		}
+
 		logger.GetLogger().Info("Casbin初始化成功")
 	})
 
@@ -80,7 +103,7 @@ func Init() error {
 }
 
 // GetEnforcer 获取Casbin执行器
-func GetEnforcer() *casbin.Enforcer {
+func GetEnforcer() *casbin.SyncedEnforcer {
 	return enforcer
 }
 
@@ -305,6 +328,47 @@ func GetImplicitPermissionsForUser(user string) ([][]string, error) {
 	return permissions, nil
 }
 
+// EnforceEx 检查权限并返回命中的策略
+func EnforceEx(sub string, obj string, act string) (bool, []string, error) {
+	if enforcer == nil {
+		return false, nil, kperrors.New(kperrors.ErrSystem, nil).WithMessage("Casbin未初始化")
+	}
+
+	result, explain, err := enforcer.EnforceEx(sub, obj, act)
+	if err != nil {
+		logger.GetLogger().Error("Casbin权限检查失败",
+			zap.Error(err),
+			zap.String("subject", sub),
+			zap.String("object", obj),
+			zap.String("action", act))
+		return false, nil, kperrors.New(kperrors.ErrPermDenied, err)
+	}
+
+	return result, explain, nil
+}
+
+// GetImplicitRolesForUser 获取用户的所有隐式角色（包括角色继承的上级角色）
+func GetImplicitRolesForUser(user string) ([]string, error) {
+	if enforcer == nil {
+		return nil, kperrors.New(kperrors.ErrSystem, nil).WithMessage("Casbin未初始化")
+	}
+
+	roles, err := enforcer.GetImplicitRolesForUser(user)
+	if err != nil {
+		logger.GetLogger().Error("获取用户隐式角色失败",
+			zap.Error(err),
+			zap.String("user", user))
+		return nil, kperrors.New(kperrors.ErrSystem, err)
+	}
+
+	return roles, nil
+}
+
+// KeyMatch2 判断请求路径是否匹配策略路径，与模型中的 keyMatch2 一致
+func KeyMatch2(path string, pattern string) bool {
+	return util.KeyMatch2(path, pattern)
+}
+
 // LoadPolicy 重新加载策略
 func LoadPolicy() error {
 	if enforcer == nil {
@@ -317,5 +381,128 @@ func LoadPolicy() error {
 		return kperrors.New(kperrors.ErrSystem, err)
 	}
 
+	// 通知其他实例重新加载
+	notifyWatcher()
+
+	return nil
+}
+
+// ReplacePoliciesTx 在事务中重写指定主体的p策略，rules中每项为 [obj, act]
+// 事务提交后需调用 LoadPolicy 刷新内存中的策略
+func ReplacePoliciesTx(tx *gorm.DB, sub string, rules [][]string) error {
+	if err := RemovePoliciesTx(tx, sub); err != nil {
+		return err
+	}
+
+	// 去重，避免违反casbin_rule的唯一索引
+	seen := make(map[string]struct{}, len(rules))
+	lines := make([]gormadapter.CasbinRule, 0, len(rules))
+	for _, rule := range rules {
+		if len(rule) < 2 {
+			continue
+		}
+		key := rule[0] + " " + rule[1]
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		lines = append(lines, gormadapter.CasbinRule{Ptype: "p", V0: sub, V1: rule[0], V2: rule[1]})
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	if err := tx.Create(&lines).Error; err != nil {
+		logger.GetLogger().Error("写入策略失败", zap.Error(err), zap.String("subject", sub))
+		return kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return nil
+}
+
+// RemovePoliciesTx 在事务中删除指定主体的所有p策略
+func RemovePoliciesTx(tx *gorm.DB, sub string) error {
+	if err := tx.Where("ptype = ? AND v0 = ?", "p", sub).Delete(&gormadapter.CasbinRule{}).Error; err != nil {
+		logger.GetLogger().Error("删除策略失败", zap.Error(err), zap.String("subject", sub))
+		return kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return nil
+}
+
+// RemoveAllPoliciesTx 在事务中删除所有p策略
+func RemoveAllPoliciesTx(tx *gorm.DB) error {
+	if err := tx.Where("ptype = ?", "p").Delete(&gormadapter.CasbinRule{}).Error; err != nil {
+		logger.GetLogger().Error("清空策略失败", zap.Error(err))
+		return kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return nil
+}
+
+// UserSubject 获取用户在Casbin中的主体标识，用于角色分组
+func UserSubject(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// ReplaceGroupingsTx 在事务中重写主体（用户或子角色）的角色分组（g策略）
+func ReplaceGroupingsTx(tx *gorm.DB, user string, roles []string) error {
+	if err := RemoveGroupingsTx(tx, user); err != nil {
+		return err
+	}
+
+	seen := make(map[string]struct{}, len(roles))
+	lines := make([]gormadapter.CasbinRule, 0, len(roles))
+	for _, role := range roles {
+		if _, ok := seen[role]; ok {
+			continue
+		}
+		seen[role] = struct{}{}
+		lines = append(lines, gormadapter.CasbinRule{Ptype: "g", V0: user, V1: role})
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	if err := tx.Create(&lines).Error; err != nil {
+		logger.GetLogger().Error("写入角色分组失败", zap.Error(err), zap.String("user", user))
+		return kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return nil
+}
+
+// RemoveGroupingsTx 在事务中删除主体（用户或子角色）的所有角色分组
+func RemoveGroupingsTx(tx *gorm.DB, user string) error {
+	if err := tx.Where("ptype = ? AND v0 = ?", "g", user).Delete(&gormadapter.CasbinRule{}).Error; err != nil {
+		logger.GetLogger().Error("删除角色分组失败", zap.Error(err), zap.String("user", user))
+		return kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return nil
+}
+
+// RemoveRoleGroupingsTx 在事务中删除指定角色的所有分组
+func RemoveRoleGroupingsTx(tx *gorm.DB, role string) error {
+	if err := tx.Where("ptype = ? AND v1 = ?", "g", role).Delete(&gormadapter.CasbinRule{}).Error; err != nil {
+		logger.GetLogger().Error("删除角色分组失败", zap.Error(err), zap.String("role", role))
+		return kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return nil
+}
+
+// RenameRoleGroupingsTx 在事务中将分组中的角色编码由oldRole改为newRole
+func RenameRoleGroupingsTx(tx *gorm.DB, oldRole, newRole string) error {
+	err := tx.Model(&gormadapter.CasbinRule{}).
+		Where("ptype = ? AND v1 = ?", "g", oldRole).
+		Update("v1", newRole).Error
+	if err != nil {
+		logger.GetLogger().Error("更新角色分组失败", zap.Error(err), zap.String("role", oldRole))
+		return kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return nil
+}
+
+// RemoveAllGroupingsTx 在事务中删除所有角色分组
+func RemoveAllGroupingsTx(tx *gorm.DB) error {
+	if err := tx.Where("ptype = ?", "g").Delete(&gormadapter.CasbinRule{}).Error; err != nil {
+		logger.GetLogger().Error("清空角色分组失败", zap.Error(err))
+		return kperrors.New(kperrors.ErrDatabase, err)
+	}
 	return nil
 }
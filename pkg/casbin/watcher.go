@@ -0,0 +1,313 @@
+package casbin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 策略变更监听方式
+const (
+	WatcherTypeNone   = "none"   // 不监听，仅适用于单实例部署
+	WatcherTypeDB     = "db"     // 轮询数据库中的策略版本号
+	WatcherTypePubSub = "pubsub" // 通过发布订阅通知
+)
+
+// 默认配置
+const (
+	defaultWatcherInterval = 5 * time.Second
+	defaultWatcherChannel  = "kunpeng:casbin:policy"
+)
+
+var (
+	watcher persist.Watcher
+	pubSub  PubSub
+)
+
+// PubSub 发布订阅接口，用于在多个实例之间广播策略变更
+// 可基于Redis、NATS、Kafka等实现，需在 Init 之前通过 SetPubSub 注册
+type PubSub interface {
+	// Publish 向频道发布消息
+	Publish(ctx context.Context, channel string, message string) error
+
+	// Subscribe 订阅频道，收到消息时调用handler，直到ctx取消
+	Subscribe(ctx context.Context, channel string, handler func(message string)) error
+
+	// Close 关闭连接
+	Close() error
+}
+
+// SetPubSub 注册发布订阅实现，watcher.type 为 pubsub 时使用
+func SetPubSub(ps PubSub) {
+	pubSub = ps
+}
+
+// CasbinVersion 策略版本模型，每次策略变更时递增版本号
+type CasbinVersion struct {
+	ID        uint      `gorm:"primarykey"`
+	Version   int64     `gorm:"not null;default:0"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName 表名
+func (CasbinVersion) TableName() string {
+	return "kp_casbin_version"
+}
+
+// newWatcher 根据配置创建策略变更监听器，未配置时返回nil
+func newWatcher(db *gorm.DB, cfg config.CasbinWatcherConfig) (persist.Watcher, error) {
+	switch cfg.Type {
+	case "", WatcherTypeNone:
+		return nil, nil
+	case WatcherTypeDB:
+		return NewDBWatcher(db, cfg.Interval)
+	case WatcherTypePubSub:
+		if pubSub == nil {
+			return nil, kperrors.New(kperrors.ErrSystem, nil).WithMessage("未注册发布订阅实现")
+		}
+		return NewPubSubWatcher(pubSub, cfg.Channel)
+	default:
+		return nil, kperrors.New(kperrors.ErrSystem, nil).WithMessage(fmt.Sprintf("不支持的策略监听方式: %s", cfg.Type))
+	}
+}
+
+// notifyWatcher 通知其他实例策略已变更
+func notifyWatcher() {
+	if watcher == nil {
+		return
+	}
+	if err := watcher.Update(); err != nil {
+		logger.GetLogger().Error("通知策略变更失败", zap.Error(err))
+	}
+}
+
+// reloadCallback 收到其他实例的策略变更通知后重新加载策略
+func reloadCallback(string) {
+	if enforcer == nil {
+		return
+	}
+	if err := enforcer.LoadPolicy(); err != nil {
+		logger.GetLogger().Error("同步加载策略失败", zap.Error(err))
+		return
+	}
+	logger.GetLogger().Info("已同步其他实例的策略变更")
+}
+
+// Close 停止策略变更监听
+func Close() {
+	if watcher != nil {
+		watcher.Close()
+	}
+}
+
+// DBWatcher 基于数据库版本号的策略变更监听器
+// 策略变更时递增 kp_casbin_version 中的版本号，各实例定时轮询，版本号变化时重新加载策略
+type DBWatcher struct {
+	db       *gorm.DB
+	interval time.Duration
+	callback func(string)
+
+	mu      sync.Mutex
+	version int64
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewDBWatcher 创建数据库版本号监听器并开始轮询
+func NewDBWatcher(db *gorm.DB, interval time.Duration) (*DBWatcher, error) {
+	if interval <= 0 {
+		interval = defaultWatcherInterval
+	}
+
+	// 确保版本记录存在
+	row := CasbinVersion{ID: 1}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&row).Error; err != nil {
+		return nil, kperrors.New(kperrors.ErrDatabase, err)
+	}
+
+	w := &DBWatcher{
+		db:       db,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+
+	version, err := w.currentVersion()
+	if err != nil {
+		return nil, err
+	}
+	w.version = version
+
+	w.wg.Add(1)
+	go w.poll()
+
+	return w, nil
+}
+
+// SetUpdateCallback 设置策略变更回调
+func (w *DBWatcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callback = callback
+	return nil
+}
+
+// Update 递增版本号，通知其他实例策略已变更
+func (w *DBWatcher) Update() error {
+	err := w.db.Model(&CasbinVersion{}).Where("id = ?", 1).
+		UpdateColumn("version", gorm.Expr("version + 1")).Error
+	if err != nil {
+		return kperrors.New(kperrors.ErrDatabase, err)
+	}
+
+	version, err := w.currentVersion()
+	if err != nil {
+		return err
+	}
+
+	// 仅当期间没有其他实例变更时才跳过本实例的重新加载
+	w.mu.Lock()
+	if version == w.version+1 {
+		w.version = version
+	}
+	w.mu.Unlock()
+
+	return nil
+}
+
+// Close 停止轮询
+func (w *DBWatcher) Close() {
+	select {
+	case <-w.stop:
+		return
+	default:
+		close(w.stop)
+	}
+	w.wg.Wait()
+}
+
+// poll 定时轮询版本号
+func (w *DBWatcher) poll() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			version, err := w.currentVersion()
+			if err != nil {
+				logger.GetLogger().Error("查询策略版本失败", zap.Error(err))
+				continue
+			}
+
+			w.mu.Lock()
+			changed := version != w.version
+			w.version = version
+			callback := w.callback
+			w.mu.Unlock()
+
+			if changed && callback != nil {
+				callback(fmt.Sprintf("%d", version))
+			}
+		}
+	}
+}
+
+// currentVersion 查询当前版本号
+func (w *DBWatcher) currentVersion() (int64, error) {
+	var row CasbinVersion
+	if err := w.db.First(&row, 1).Error; err != nil {
+		return 0, kperrors.New(kperrors.ErrDatabase, err)
+	}
+	return row.Version, nil
+}
+
+// PubSubWatcher 基于发布订阅的策略变更监听器
+// 策略变更时向频道发布本实例ID，其他实例收到消息后重新加载策略
+type PubSubWatcher struct {
+	pubSub     PubSub
+	channel    string
+	instanceID string
+
+	mu       sync.Mutex
+	callback func(string)
+
+	cancel context.CancelFunc
+}
+
+// NewPubSubWatcher 创建发布订阅监听器并开始订阅
+func NewPubSubWatcher(ps PubSub, channel string) (*PubSubWatcher, error) {
+	if channel == "" {
+		channel = defaultWatcherChannel
+	}
+
+	hostname, _ := os.Hostname()
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &PubSubWatcher{
+		pubSub:     ps,
+		channel:    channel,
+		instanceID: fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()),
+		cancel:     cancel,
+	}
+
+	if err := ps.Subscribe(ctx, channel, w.onMessage); err != nil {
+		cancel()
+		return nil, kperrors.New(kperrors.ErrSystem, err)
+	}
+
+	return w, nil
+}
+
+// SetUpdateCallback 设置策略变更回调
+func (w *PubSubWatcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callback = callback
+	return nil
+}
+
+// Update 发布策略变更消息
+func (w *PubSubWatcher) Update() error {
+	if err := w.pubSub.Publish(context.Background(), w.channel, w.instanceID); err != nil {
+		return kperrors.New(kperrors.ErrSystem, err)
+	}
+	return nil
+}
+
+// Close 取消订阅并关闭连接
+func (w *PubSubWatcher) Close() {
+	w.cancel()
+	if err := w.pubSub.Close(); err != nil {
+		logger.GetLogger().Error("关闭发布订阅连接失败", zap.Error(err))
+	}
+}
+
+// onMessage 处理策略变更消息，忽略本实例发布的消息
+func (w *PubSubWatcher) onMessage(message string) {
+	if message == w.instanceID {
+		return
+	}
+
+	w.mu.Lock()
+	callback := w.callback
+	w.mu.Unlock()
+
+	if callback != nil {
+		callback(message)
+	}
+}
@@ -93,6 +93,66 @@ func GetCasbinConfig() CasbinConfig {
 	return config.Casbin
 }
 
+// GetCaptchaConfig 获取图片验证码配置
+func GetCaptchaConfig() CaptchaConfig {
+	return config.Captcha
+}
+
+// GetSMSConfig 获取短信验证码配置
+func GetSMSConfig() SMSConfig {
+	return config.SMS
+}
+
+// GetTwoFactorConfig 获取双因素认证配置
+func GetTwoFactorConfig() TwoFactorConfig {
+	return config.TwoFactor
+}
+
+// GetTokenRevocationConfig 获取token撤销存储配置
+func GetTokenRevocationConfig() TokenRevocationConfig {
+	return config.TokenRevocation
+}
+
+// GetSchedulerConfig 获取定时任务配置
+func GetSchedulerConfig() SchedulerConfig {
+	return config.Scheduler
+}
+
+// GetLoginLockConfig 获取登录失败锁定配置
+func GetLoginLockConfig() LoginLockConfig {
+	return config.LoginLock
+}
+
+// GetPasswordPolicyConfig 获取密码策略配置
+func GetPasswordPolicyConfig() PasswordPolicyConfig {
+	return config.PasswordPolicy
+}
+
+// GetMailConfig 获取邮件发送配置
+func GetMailConfig() MailConfig {
+	return config.Mail
+}
+
+// GetPasswordResetConfig 获取找回密码配置
+func GetPasswordResetConfig() PasswordResetConfig {
+	return config.PasswordReset
+}
+
+// GetOIDCConfig 获取单点登录配置
+func GetOIDCConfig() OIDCConfig {
+	return config.OIDC
+}
+
+// GetLDAPConfig 获取LDAP认证配置
+func GetLDAPConfig() LDAPConfig {
+	return config.LDAP
+}
+
+// GetAPISignatureConfig 获取签名认证配置
+func GetAPISignatureConfig() APISignatureConfig {
+	return config.APISignature
+}
+
 // IsProduction 是否为生产环境
 func IsProduction() bool {
 	return config.App.Mode == "production"
@@ -4,12 +4,24 @@ import "time"
 
 // Config 应用配置结构
 type Config struct {
-	App      AppConfig      `mapstructure:"app"`
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Log      LogConfig      `mapstructure:"log"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	Casbin   CasbinConfig   `mapstructure:"casbin"`
+	App             AppConfig             `mapstructure:"app"`
+	Server          ServerConfig          `mapstructure:"server"`
+	Database        DatabaseConfig        `mapstructure:"database"`
+	Log             LogConfig             `mapstructure:"log"`
+	JWT             JWTConfig             `mapstructure:"jwt"`
+	Casbin          CasbinConfig          `mapstructure:"casbin"`
+	Captcha         CaptchaConfig         `mapstructure:"captcha"`
+	SMS             SMSConfig             `mapstructure:"sms"`
+	TwoFactor       TwoFactorConfig       `mapstructure:"two_factor"`
+	TokenRevocation TokenRevocationConfig `mapstructure:"token_revocation"`
+	Scheduler       SchedulerConfig       `mapstructure:"scheduler"`
+	LoginLock       LoginLockConfig       `mapstructure:"login_lock"`
+	PasswordPolicy  PasswordPolicyConfig  `mapstructure:"password_policy"`
+	Mail            MailConfig            `mapstructure:"mail"`
+	PasswordReset   PasswordResetConfig   `mapstructure:"password_reset"`
+	OIDC            OIDCConfig            `mapstructure:"oidc"`
+	LDAP            LDAPConfig            `mapstructure:"ldap"`
+	APISignature    APISignatureConfig    `mapstructure:"api_signature"`
 }
 
 // AppConfig 应用基础配置
@@ -19,14 +31,16 @@ type AppConfig struct {
 	Version     string `mapstructure:"version"`
 	Language    string `mapstructure:"language"`
 	TraceEnable bool   `mapstructure:"trace_enable"`
+	APISync     bool   `mapstructure:"api_sync"` // 启动时是否自动同步路由到API表
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Host         string        `mapstructure:"host"`
-	Port         int           `mapstructure:"port"`
-	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
-	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	Host           string        `mapstructure:"host"`
+	Port           int           `mapstructure:"port"`
+	ReadTimeout    time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout   time.Duration `mapstructure:"write_timeout"`
+	TrustedProxies []string      `mapstructure:"trusted_proxies"` // 可信代理的IP或网段，仅信任其转发的客户端IP请求头，为空时使用连接的对端地址
 }
 
 // DatabaseConfig 数据库配置
@@ -62,14 +76,203 @@ type LogConfig struct {
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	Secret               string        `mapstructure:"secret"`
-	Issuer               string        `mapstructure:"issuer"`
-	ExpireTime           time.Duration `mapstructure:"expire_time"`             // 普通登录token有效期
-	RememberMeExpireTime time.Duration `mapstructure:"remember_me_expire_time"` // 记住我token有效期
+	Secret               string         `mapstructure:"secret"`
+	Issuer               string         `mapstructure:"issuer"`
+	ExpireTime           time.Duration  `mapstructure:"expire_time"`             // 普通登录token有效期
+	RememberMeExpireTime time.Duration  `mapstructure:"remember_me_expire_time"` // 记住我token有效期
+	VersionCacheTTL      time.Duration  `mapstructure:"version_cache_ttl"`       // 用户token版本号缓存时间，多实例部署时其他实例最长在该时间后感知token失效
+	Algorithm            string         `mapstructure:"algorithm"`               // 签名算法 HS256, RS256, ES256, EdDSA，默认HS256
+	ActiveKID            string         `mapstructure:"active_kid"`              // 用于签名的密钥ID，非对称算法时必填
+	Keys                 []JWTKeyConfig `mapstructure:"keys"`                    // 非对称算法的密钥列表，除签名密钥外的密钥仅用于验证轮换前签发的token
+}
+
+// JWTKeyConfig JWT非对称密钥配置
+type JWTKeyConfig struct {
+	KID            string `mapstructure:"kid"`              // 密钥ID
+	PrivateKeyFile string `mapstructure:"private_key_file"` // PEM格式私钥文件，签名密钥必填
+	PublicKeyFile  string `mapstructure:"public_key_file"`  // PEM格式公钥文件，未配置时从私钥导出
 }
 
 // CasbinConfig Casbin配置
 type CasbinConfig struct {
-	ModelPath string `mapstructure:"model_path"`
-	Enable    bool   `mapstructure:"enable"`
+	ModelPath  string              `mapstructure:"model_path"`
+	Enable     bool                `mapstructure:"enable"`
+	SuperAdmin string              `mapstructure:"super_admin"` // 超级管理员角色编码，拥有该角色时跳过权限校验
+	Watcher    CasbinWatcherConfig `mapstructure:"watcher"`     // 多实例策略变更同步配置
+}
+
+// CasbinWatcherConfig Casbin策略变更监听配置
+type CasbinWatcherConfig struct {
+	Type     string        `mapstructure:"type"`     // 监听方式 none, db, pubsub
+	Interval time.Duration `mapstructure:"interval"` // db方式轮询策略版本号的间隔
+	Channel  string        `mapstructure:"channel"`  // pubsub方式的频道
+}
+
+// CaptchaConfig 图片验证码配置
+type CaptchaConfig struct {
+	Enable        bool          `mapstructure:"enable"`         // 是否启用登录图片验证码
+	Type          string        `mapstructure:"type"`           // 验证码类型 digit, math
+	Length        int           `mapstructure:"length"`         // 数字验证码长度
+	Width         int           `mapstructure:"width"`          // 图片宽度
+	Height        int           `mapstructure:"height"`         // 图片高度
+	TTL           time.Duration `mapstructure:"ttl"`            // 验证码有效期
+	FailThreshold int           `mapstructure:"fail_threshold"` // 登录失败达到该次数后要求验证码，0表示始终要求
+}
+
+// SMSConfig 短信验证码配置
+type SMSConfig struct {
+	Driver            string        `mapstructure:"driver"`              // 短信发送驱动，log仅输出到日志
+	TTL               time.Duration `mapstructure:"ttl"`                 // 验证码有效期
+	Cooldown          time.Duration `mapstructure:"cooldown"`            // 同一手机号重新发送的间隔
+	Window            time.Duration `mapstructure:"window"`              // 发送次数限制的统计周期
+	MobileLimit       int           `mapstructure:"mobile_limit"`        // 统计周期内每个手机号的发送上限，0表示不限制
+	IPLimit           int           `mapstructure:"ip_limit"`            // 统计周期内每个IP的发送上限，0表示不限制
+	MaxVerifyAttempts int           `mapstructure:"max_verify_attempts"` // 单个验证码允许的校验失败次数，超过后失效
+}
+
+// TwoFactorConfig 双因素认证配置
+type TwoFactorConfig struct {
+	Issuer        string        `mapstructure:"issuer"`         // 验证器应用中显示的发行方，为空时使用应用名称
+	Skew          int           `mapstructure:"skew"`           // 允许的时钟偏差（时间步数）
+	ChallengeTTL  time.Duration `mapstructure:"challenge_ttl"`  // 登录第二步凭证的有效期
+	RecoveryCodes int           `mapstructure:"recovery_codes"` // 生成的恢复码数量
+}
+
+// TokenRevocationConfig token撤销存储配置
+type TokenRevocationConfig struct {
+	Store           string        `mapstructure:"store"`            // 撤销存储类型 db, redis
+	CacheSize       int           `mapstructure:"cache_size"`       // LRU缓存条目数
+	SyncInterval    time.Duration `mapstructure:"sync_interval"`    // 从数据库增量同步撤销记录的间隔，也是LRU缓存的有效期
+	RebuildInterval time.Duration `mapstructure:"rebuild_interval"` // 重建布隆过滤器的间隔
+	BloomCapacity   int           `mapstructure:"bloom_capacity"`   // 布隆过滤器的预期容量
+	BloomFPRate     float64       `mapstructure:"bloom_fp_rate"`    // 布隆过滤器的误判率
+	RedisPrefix     string        `mapstructure:"redis_prefix"`     // Redis key前缀
+	RedisAddr       string        `mapstructure:"redis_addr"`       // Redis地址，host:port
+	RedisPassword   string        `mapstructure:"redis_password"`   // Redis密码
+	RedisDB         int           `mapstructure:"redis_db"`         // Redis数据库编号
+}
+
+// SchedulerConfig 定时任务配置
+type SchedulerConfig struct {
+	Enable               bool              `mapstructure:"enable"`                 // 是否启用定时任务
+	Jobs                 map[string]string `mapstructure:"jobs"`                   // 按任务名称覆盖默认的cron表达式
+	LogRetentionDays     int               `mapstructure:"log_retention_days"`     // 登录日志及操作日志的保留天数，0表示不清理
+	HistoryRetentionDays int               `mapstructure:"history_retention_days"` // 任务执行记录的保留天数，0表示不清理
+}
+
+// LoginLockConfig 登录失败锁定配置，未配置的项使用默认值
+type LoginLockConfig struct {
+	AccountThreshold  int           `mapstructure:"account_threshold"`  // 同一账号连续登录失败达到该次数后锁定账号
+	IPThreshold       int           `mapstructure:"ip_threshold"`       // 同一IP连续登录失败（不区分账号）达到该次数后锁定IP
+	LockDuration      time.Duration `mapstructure:"lock_duration"`      // 首次锁定时长
+	BackoffMultiplier float64       `mapstructure:"backoff_multiplier"` // 每次再锁定时长的倍数，1表示不递增
+	MaxLockDuration   time.Duration `mapstructure:"max_lock_duration"`  // 锁定时长上限
+	ResetAfter        time.Duration `mapstructure:"reset_after"`        // 超过该时间没有登录失败时清零失败次数及锁定次数
+}
+
+// PasswordPolicyConfig 密码策略配置，未配置的项使用默认值
+type PasswordPolicyConfig struct {
+	MinLength       int           `mapstructure:"min_length"`       // 最小长度
+	MaxLength       int           `mapstructure:"max_length"`       // 最大长度
+	CharClasses     []string      `mapstructure:"char_classes"`     // 必须包含的字符类型：lower、upper、digit、special
+	BannedPasswords []string      `mapstructure:"banned_passwords"` // 禁止使用的密码，不区分大小写，内置常见弱密码之外的补充
+	HistoryCount    int           `mapstructure:"history_count"`    // 不能与最近几次使用过的密码相同，0表示不限制
+	MaxAge          time.Duration `mapstructure:"max_age"`          // 密码有效期，过期后登录时要求修改密码，0表示永不过期
+	ResetLength     int           `mapstructure:"reset_length"`     // 管理员重置密码时生成的一次性密码长度
+}
+
+// MailConfig 邮件发送配置
+type MailConfig struct {
+	Driver     string        `mapstructure:"driver"`     // 邮件发送驱动：smtp、file、log
+	Host       string        `mapstructure:"host"`       // SMTP服务器地址
+	Port       int           `mapstructure:"port"`       // SMTP服务器端口
+	Username   string        `mapstructure:"username"`   // SMTP用户名，为空时不认证
+	Password   string        `mapstructure:"password"`   // SMTP密码或授权码
+	Encryption string        `mapstructure:"encryption"` // 连接加密方式：starttls、ssl、none
+	From       string        `mapstructure:"from"`       // 发件人地址
+	FromName   string        `mapstructure:"from_name"`  // 发件人名称
+	Timeout    time.Duration `mapstructure:"timeout"`    // SMTP连接及发送超时时间
+	Dir        string        `mapstructure:"dir"`        // file驱动保存邮件的目录
+}
+
+// PasswordResetConfig 找回密码配置
+type PasswordResetConfig struct {
+	TokenTTL  time.Duration `mapstructure:"token_ttl"`  // 重置链接有效期
+	Cooldown  time.Duration `mapstructure:"cooldown"`   // 同一账号重新发送的间隔
+	Window    time.Duration `mapstructure:"window"`     // 发送次数限制的统计周期
+	IPLimit   int           `mapstructure:"ip_limit"`   // 统计周期内每个IP的发送上限，0表示不限制
+	UserLimit int           `mapstructure:"user_limit"` // 统计周期内每个用户的发送上限，未配置时为5
+	ResetURL  string        `mapstructure:"reset_url"`  // 前端重置密码页面地址，{token}会被替换为重置凭证
+}
+
+// OIDCConfig 单点登录配置
+type OIDCConfig struct {
+	Enable         bool             `mapstructure:"enable"`           // 是否启用单点登录
+	Issuer         string           `mapstructure:"issuer"`           // 身份提供方地址，通过 {issuer}/.well-known/openid-configuration 获取端点
+	ClientID       string           `mapstructure:"client_id"`        // 客户端ID
+	ClientSecret   string           `mapstructure:"client_secret"`    // 客户端密钥，公共客户端为空
+	RedirectURL    string           `mapstructure:"redirect_url"`     // 回调地址，需与身份提供方登记的一致
+	Scopes         []string         `mapstructure:"scopes"`           // 申请的scope，始终包含openid
+	Timeout        time.Duration    `mapstructure:"timeout"`          // 请求身份提供方的超时时间
+	StateTTL       time.Duration    `mapstructure:"state_ttl"`        // 授权请求的有效期
+	ClockSkew      time.Duration    `mapstructure:"clock_skew"`       // 校验ID token时允许的时钟偏差
+	Claims         OIDCClaimsConfig `mapstructure:"claims"`           // ID token声明与用户字段的映射
+	AutoProvision  bool             `mapstructure:"auto_provision"`   // 身份未关联账号时是否自动创建用户
+	DefaultDeptID  uint             `mapstructure:"default_dept_id"`  // 自动创建用户的部门
+	DefaultRoleIDs []uint           `mapstructure:"default_role_ids"` // 自动创建用户的角色
+	LinkByEmail    bool             `mapstructure:"link_by_email"`    // 身份未关联账号时是否按已验证的邮箱关联已有用户，不关联内置用户及超级管理员
+}
+
+// OIDCClaimsConfig ID token声明映射配置，配置项为声明名称
+type OIDCClaimsConfig struct {
+	Username string `mapstructure:"username"` // 用户名，自动创建用户时使用
+	Email    string `mapstructure:"email"`    // 邮箱
+	Nickname string `mapstructure:"nickname"` // 昵称
+}
+
+// LDAPConfig LDAP认证配置
+type LDAPConfig struct {
+	Enable             bool                 `mapstructure:"enable"`               // 是否启用LDAP登录
+	URL                string               `mapstructure:"url"`                  // 服务器地址，ldap://host:389 或 ldaps://host:636
+	StartTLS           bool                 `mapstructure:"start_tls"`            // ldap://连接是否升级为TLS
+	InsecureSkipVerify bool                 `mapstructure:"insecure_skip_verify"` // 是否跳过服务器证书校验，仅用于测试环境
+	Timeout            time.Duration        `mapstructure:"timeout"`              // 连接及单次操作的超时时间
+	BindDN             string               `mapstructure:"bind_dn"`              // 查找用户使用的服务账号，为空时匿名查找
+	BindPassword       string               `mapstructure:"bind_password"`        // 服务账号密码
+	BaseDN             string               `mapstructure:"base_dn"`              // 查找用户的根DN
+	UserFilter         string               `mapstructure:"user_filter"`          // 查找用户的过滤器，{username}会被替换为转义后的登录账号
+	GroupBaseDN        string               `mapstructure:"group_base_dn"`        // 查找用户组的根DN，为空时使用base_dn
+	GroupFilter        string               `mapstructure:"group_filter"`         // 查找用户所属组的过滤器，{dn}会被替换为用户DN，为空时读取用户的memberOf属性
+	Attributes         LDAPAttributesConfig `mapstructure:"attributes"`           // 用户属性映射
+	GroupMappings      []LDAPGroupMapping   `mapstructure:"group_mappings"`       // 用户组与角色及部门的映射
+	AutoProvision      bool                 `mapstructure:"auto_provision"`       // 目录用户首次登录时是否自动创建用户
+	DefaultDeptID      uint                 `mapstructure:"default_dept_id"`      // 未匹配到部门映射时使用的部门
+	DefaultRoleIDs     []uint               `mapstructure:"default_role_ids"`     // 未匹配到角色映射时使用的角色
+	SyncRoles          bool                 `mapstructure:"sync_roles"`           // 登录时是否按用户组重新设置已有用户的角色及部门
+	LocalUsers         []string             `mapstructure:"local_users"`          // 使用本地密码认证的应急账号，目录不可用时仍可登录
+	LinkUsers          []string             `mapstructure:"link_users"`           // 允许首次LDAP登录时关联的已有本地账号，其他非LDAP创建的账号不能通过LDAP登录
+}
+
+// LDAPAttributesConfig LDAP用户属性映射配置，配置项为属性名称
+type LDAPAttributesConfig struct {
+	Nickname string `mapstructure:"nickname"`  // 昵称
+	RealName string `mapstructure:"real_name"` // 真实姓名
+	Email    string `mapstructure:"email"`     // 邮箱
+	Mobile   string `mapstructure:"mobile"`    // 手机号
+	MemberOf string `mapstructure:"member_of"` // 用户所属组，未配置group_filter时使用
+}
+
+// LDAPGroupMapping LDAP用户组映射配置
+type LDAPGroupMapping struct {
+	Group   string `mapstructure:"group"`    // 用户组DN，不区分大小写
+	RoleIDs []uint `mapstructure:"role_ids"` // 组成员拥有的角色
+	DeptID  uint   `mapstructure:"dept_id"`  // 组成员所属部门，匹配多个时使用第一个
+}
+
+// APISignatureConfig AppKey/AppSecret签名认证配置
+type APISignatureConfig struct {
+	Enable        bool          `mapstructure:"enable"`         // 是否允许使用签名请求代替Bearer token访问接口
+	ClockSkew     time.Duration `mapstructure:"clock_skew"`     // 请求时间戳与服务器时间允许的偏差，超出时拒绝请求
+	MaxBodySize   int64         `mapstructure:"max_body_size"`  // 参与签名的请求体最大字节数
+	RotationGrace time.Duration `mapstructure:"rotation_grace"` // 轮换AppSecret后原AppSecret继续有效的时间，0表示立即失效
 }
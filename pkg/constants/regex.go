@@ -2,7 +2,6 @@ package constants
 
 import (
 	"github.com/cuiyuanxin/kunpeng/pkg/i18n"
-	"regexp"
 )
 
 // 正则表达式常量
@@ -10,9 +9,6 @@ const (
 	// 用户名正则：5-20位的字母、数字或下划线
 	UsernameRegex = `^[a-zA-Z0-9_]{5,20}$`
 
-	// 密码正则：基本字符集和长度验证（6-25位，只包含字母、数字和指定特殊字符）
-	PasswordRegex = `^[a-zA-Z\d~!@#$%^&_-]{6,25}$`
-
 	// 手机号正则：中国大陆手机号格式，1开头，第二位为3-9，总共11位数字
 	MobileRegex = `^1[3-9]\d{9}$`
 
@@ -42,25 +38,3 @@ func GetErrorMessageI18nWithLanguage(msgType, language string) string {
 	i18n.SetLanguage(language)
 	return i18n.T("regex." + msgType)
 }
-
-// ValidatePassword 验证密码复杂性（Go 兼容版本）
-// 要求：包含大小写字母、数字和特殊字符，长度6-25位
-func ValidatePassword(password string) bool {
-	if len(password) < 6 || len(password) > 25 {
-		return false
-	}
-
-	// 基本字符集验证
-	basicRegex := regexp.MustCompile(PasswordRegex)
-	if !basicRegex.MatchString(password) {
-		return false
-	}
-
-	// 手动检查复杂性要求
-	hasLower := regexp.MustCompile(`[a-z]`).MatchString(password)
-	hasUpper := regexp.MustCompile(`[A-Z]`).MatchString(password)
-	hasDigit := regexp.MustCompile(`\d`).MatchString(password)
-	hasSpecial := regexp.MustCompile(`[~!@#$%^&_-]`).MatchString(password)
-
-	return hasLower && hasUpper && hasDigit && hasSpecial
-}
@@ -70,6 +70,11 @@ func GetDB() *gorm.DB {
 	return db
 }
 
+// SetDB 使用已创建的数据库连接，用于测试等不通过配置连接数据库的场景，需在初始化仓储之前调用
+func SetDB(conn *gorm.DB) {
+	db = conn
+}
+
 // Close 关闭数据库连接
 func Close() error {
 	sqlDB, err := db.DB()
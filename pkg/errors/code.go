@@ -88,6 +88,7 @@ const (
 	ErrAuthToken        = 10312 // 令牌错误
 	ErrAuthSession      = 10313 // 会话错误
 	ErrAuthSSO          = 10314 // 单点登录错误
+	ErrAuthTokenReuse   = 10315 // 令牌重复使用
 
 	// 权限错误 (10400-10499)
 	ErrPermDenied      = 10400 // 权限不足
@@ -108,6 +109,7 @@ const (
 	ErrPermUpdate      = 10415 // 更新不允许
 	ErrPermCreate      = 10416 // 创建不允许
 	ErrPermRead        = 10417 // 读取不允许
+	ErrPermSelfLockout = 10418 // 操作将导致失去权限管理能力
 
 	// 文件错误 (10500-10599)
 	ErrFileUpload      = 10500 // 文件上传失败
@@ -131,23 +133,29 @@ const (
 // 业务级错误码 (20000-29999)
 const (
 	// 用户模块错误 (20100-20199)
-	ErrUserNotFound      = 20100 // 用户不存在
-	ErrUserDisabled      = 20101 // 用户已禁用
-	ErrUserLocked        = 20102 // 用户已锁定
-	ErrUserExpired       = 20103 // 用户已过期
-	ErrUserPassword      = 20104 // 用户密码错误
-	ErrUserOldPassword   = 20105 // 用户旧密码错误
-	ErrUserExists        = 20106 // 用户已存在
-	ErrUserNameExists    = 20107 // 用户名已存在
-	ErrUserEmailExists   = 20108 // 用户邮箱已存在
-	ErrUserPhoneExists   = 20109 // 用户手机号已存在
-	ErrUserNameInvalid   = 20110 // 用户名无效
-	ErrUserEmailInvalid  = 20111 // 用户邮箱无效
-	ErrUserPhoneInvalid  = 20112 // 用户手机号无效
-	ErrUserRoleInvalid   = 20113 // 用户角色无效
-	ErrUserDeptInvalid   = 20114 // 用户部门无效
-	ErrUserPostInvalid   = 20115 // 用户岗位无效
-	ErrUserStatusInvalid = 20116 // 用户状态无效
+	ErrUserNotFound       = 20100 // 用户不存在
+	ErrUserDisabled       = 20101 // 用户已禁用
+	ErrUserLocked         = 20102 // 用户已锁定
+	ErrUserExpired        = 20103 // 用户已过期
+	ErrUserPassword       = 20104 // 用户密码错误
+	ErrUserOldPassword    = 20105 // 用户旧密码错误
+	ErrUserExists         = 20106 // 用户已存在
+	ErrUserNameExists     = 20107 // 用户名已存在
+	ErrUserEmailExists    = 20108 // 用户邮箱已存在
+	ErrUserPhoneExists    = 20109 // 用户手机号已存在
+	ErrUserNameInvalid    = 20110 // 用户名无效
+	ErrUserEmailInvalid   = 20111 // 用户邮箱无效
+	ErrUserPhoneInvalid   = 20112 // 用户手机号无效
+	ErrUserRoleInvalid    = 20113 // 用户角色无效
+	ErrUserDeptInvalid    = 20114 // 用户部门无效
+	ErrUserPostInvalid    = 20115 // 用户岗位无效
+	ErrUserStatusInvalid  = 20116 // 用户状态无效
+	ErrUserBuiltin        = 20117 // 内置用户不允许删除或禁用
+	ErrUserSelfOperate    = 20118 // 不能删除或禁用当前登录用户
+	ErrUserPasswordPolicy = 20119 // 密码不符合安全策略
+	ErrUserPasswordReused = 20120 // 不能使用最近使用过的密码
+	ErrUserResetToken     = 20121 // 重置密码链接无效或已过期
+	ErrUserResetFreq      = 20122 // 重置密码邮件发送过于频繁
 
 	// 角色模块错误 (20200-20299)
 	ErrRoleNotFound      = 20200 // 角色不存在
@@ -166,6 +174,8 @@ const (
 	ErrRoleHasPosts      = 20213 // 角色下有岗位
 	ErrRoleHasApis       = 20214 // 角色下有API
 	ErrRoleHasButtons    = 20215 // 角色下有按钮
+	ErrRoleParentInvalid = 20216 // 上级角色无效
+	ErrRoleBuiltin       = 20217 // 内置角色不允许删除、禁用或修改编码
 
 	// 菜单模块错误 (20300-20399)
 	ErrMenuNotFound         = 20300 // 菜单不存在
@@ -205,6 +215,13 @@ const (
 	ErrOperationLogBatchDelete = 20504 // 批量删除操作日志失败
 	ErrOperationLogCleanOld    = 20505 // 清理旧操作日志失败
 	ErrOperationLogInvalidID   = 20506 // 操作日志ID无效
+
+	// 定时任务模块错误码 (20600-20699)
+	ErrJobNotFound     = 20600 // 定时任务不存在
+	ErrJobRunning      = 20601 // 定时任务正在执行
+	ErrJobNotStarted   = 20602 // 定时任务调度器未运行
+	ErrJobLogGetList   = 20603 // 获取定时任务执行记录失败
+	ErrJobStatusChange = 20604 // 修改定时任务状态失败
 )
 
 // 错误码映射表 - 使用 i18n 获取国际化错误消息
@@ -275,6 +292,7 @@ func getCodeMessageMap() map[int]string {
 		ErrAuthToken:        getI18nMessage(fmt.Sprintf("error.%d", ErrAuthToken), "令牌错误"),
 		ErrAuthSession:      getI18nMessage(fmt.Sprintf("error.%d", ErrAuthSession), "会话错误"),
 		ErrAuthSSO:          getI18nMessage(fmt.Sprintf("error.%d", ErrAuthSSO), "单点登录错误"),
+		ErrAuthTokenReuse:   getI18nMessage(fmt.Sprintf("error.%d", ErrAuthTokenReuse), "令牌重复使用"),
 
 		// 权限错误
 		ErrPermDenied:      getI18nMessage(fmt.Sprintf("error.%d", ErrPermDenied), "权限不足"),
@@ -295,6 +313,7 @@ func getCodeMessageMap() map[int]string {
 		ErrPermUpdate:      getI18nMessage(fmt.Sprintf("error.%d", ErrPermUpdate), "更新不允许"),
 		ErrPermCreate:      getI18nMessage(fmt.Sprintf("error.%d", ErrPermCreate), "创建不允许"),
 		ErrPermRead:        getI18nMessage(fmt.Sprintf("error.%d", ErrPermRead), "读取不允许"),
+		ErrPermSelfLockout: getI18nMessage(fmt.Sprintf("error.%d", ErrPermSelfLockout), "操作将导致当前用户失去权限管理能力"),
 
 		// 文件错误
 		ErrFileUpload:      getI18nMessage(fmt.Sprintf("error.%d", ErrFileUpload), "文件上传失败"),
@@ -315,23 +334,29 @@ func getCodeMessageMap() map[int]string {
 		ErrFileNameInvalid: getI18nMessage(fmt.Sprintf("error.%d", ErrFileNameInvalid), "文件名无效"),
 
 		// 用户模块错误
-		ErrUserNotFound:      getI18nMessage(fmt.Sprintf("error.%d", ErrUserNotFound), "用户不存在"),
-		ErrUserDisabled:      getI18nMessage(fmt.Sprintf("error.%d", ErrUserDisabled), "用户已禁用"),
-		ErrUserLocked:        getI18nMessage(fmt.Sprintf("error.%d", ErrUserLocked), "用户已锁定"),
-		ErrUserExpired:       getI18nMessage(fmt.Sprintf("error.%d", ErrUserExpired), "用户已过期"),
-		ErrUserPassword:      getI18nMessage(fmt.Sprintf("error.%d", ErrUserPassword), "用户密码错误"),
-		ErrUserOldPassword:   getI18nMessage(fmt.Sprintf("error.%d", ErrUserOldPassword), "用户旧密码错误"),
-		ErrUserExists:        getI18nMessage(fmt.Sprintf("error.%d", ErrUserExists), "用户已存在"),
-		ErrUserNameExists:    getI18nMessage(fmt.Sprintf("error.%d", ErrUserNameExists), "用户名已存在"),
-		ErrUserEmailExists:   getI18nMessage(fmt.Sprintf("error.%d", ErrUserEmailExists), "用户邮箱已存在"),
-		ErrUserPhoneExists:   getI18nMessage(fmt.Sprintf("error.%d", ErrUserPhoneExists), "用户手机号已存在"),
-		ErrUserNameInvalid:   getI18nMessage(fmt.Sprintf("error.%d", ErrUserNameInvalid), "用户名无效"),
-		ErrUserEmailInvalid:  getI18nMessage(fmt.Sprintf("error.%d", ErrUserEmailInvalid), "用户邮箱无效"),
-		ErrUserPhoneInvalid:  getI18nMessage(fmt.Sprintf("error.%d", ErrUserPhoneInvalid), "用户手机号无效"),
-		ErrUserRoleInvalid:   getI18nMessage(fmt.Sprintf("error.%d", ErrUserRoleInvalid), "用户角色无效"),
-		ErrUserDeptInvalid:   getI18nMessage(fmt.Sprintf("error.%d", ErrUserDeptInvalid), "用户部门无效"),
-		ErrUserPostInvalid:   getI18nMessage(fmt.Sprintf("error.%d", ErrUserPostInvalid), "用户岗位无效"),
-		ErrUserStatusInvalid: getI18nMessage(fmt.Sprintf("error.%d", ErrUserStatusInvalid), "用户状态无效"),
+		ErrUserNotFound:       getI18nMessage(fmt.Sprintf("error.%d", ErrUserNotFound), "用户不存在"),
+		ErrUserDisabled:       getI18nMessage(fmt.Sprintf("error.%d", ErrUserDisabled), "用户已禁用"),
+		ErrUserLocked:         getI18nMessage(fmt.Sprintf("error.%d", ErrUserLocked), "用户已锁定"),
+		ErrUserExpired:        getI18nMessage(fmt.Sprintf("error.%d", ErrUserExpired), "用户已过期"),
+		ErrUserPassword:       getI18nMessage(fmt.Sprintf("error.%d", ErrUserPassword), "用户密码错误"),
+		ErrUserOldPassword:    getI18nMessage(fmt.Sprintf("error.%d", ErrUserOldPassword), "用户旧密码错误"),
+		ErrUserExists:         getI18nMessage(fmt.Sprintf("error.%d", ErrUserExists), "用户已存在"),
+		ErrUserNameExists:     getI18nMessage(fmt.Sprintf("error.%d", ErrUserNameExists), "用户名已存在"),
+		ErrUserEmailExists:    getI18nMessage(fmt.Sprintf("error.%d", ErrUserEmailExists), "用户邮箱已存在"),
+		ErrUserPhoneExists:    getI18nMessage(fmt.Sprintf("error.%d", ErrUserPhoneExists), "用户手机号已存在"),
+		ErrUserNameInvalid:    getI18nMessage(fmt.Sprintf("error.%d", ErrUserNameInvalid), "用户名无效"),
+		ErrUserEmailInvalid:   getI18nMessage(fmt.Sprintf("error.%d", ErrUserEmailInvalid), "用户邮箱无效"),
+		ErrUserPhoneInvalid:   getI18nMessage(fmt.Sprintf("error.%d", ErrUserPhoneInvalid), "用户手机号无效"),
+		ErrUserRoleInvalid:    getI18nMessage(fmt.Sprintf("error.%d", ErrUserRoleInvalid), "用户角色无效"),
+		ErrUserDeptInvalid:    getI18nMessage(fmt.Sprintf("error.%d", ErrUserDeptInvalid), "用户部门无效"),
+		ErrUserPostInvalid:    getI18nMessage(fmt.Sprintf("error.%d", ErrUserPostInvalid), "用户岗位无效"),
+		ErrUserStatusInvalid:  getI18nMessage(fmt.Sprintf("error.%d", ErrUserStatusInvalid), "用户状态无效"),
+		ErrUserBuiltin:        getI18nMessage(fmt.Sprintf("error.%d", ErrUserBuiltin), "内置用户不允许删除或禁用"),
+		ErrUserSelfOperate:    getI18nMessage(fmt.Sprintf("error.%d", ErrUserSelfOperate), "不能删除或禁用当前登录用户"),
+		ErrUserPasswordPolicy: getI18nMessage(fmt.Sprintf("error.%d", ErrUserPasswordPolicy), "密码不符合安全策略"),
+		ErrUserPasswordReused: getI18nMessage(fmt.Sprintf("error.%d", ErrUserPasswordReused), "不能使用最近使用过的密码"),
+		ErrUserResetToken:     getI18nMessage(fmt.Sprintf("error.%d", ErrUserResetToken), "重置密码链接无效或已过期"),
+		ErrUserResetFreq:      getI18nMessage(fmt.Sprintf("error.%d", ErrUserResetFreq), "重置密码邮件发送过于频繁"),
 
 		// 角色模块错误
 		ErrRoleNotFound:      getI18nMessage(fmt.Sprintf("error.%d", ErrRoleNotFound), "角色不存在"),
@@ -350,6 +375,8 @@ func getCodeMessageMap() map[int]string {
 		ErrRoleHasPosts:      getI18nMessage(fmt.Sprintf("error.%d", ErrRoleHasPosts), "角色下有岗位"),
 		ErrRoleHasApis:       getI18nMessage(fmt.Sprintf("error.%d", ErrRoleHasApis), "角色下有API"),
 		ErrRoleHasButtons:    getI18nMessage(fmt.Sprintf("error.%d", ErrRoleHasButtons), "角色下有按钮"),
+		ErrRoleParentInvalid: getI18nMessage(fmt.Sprintf("error.%d", ErrRoleParentInvalid), "上级角色无效"),
+		ErrRoleBuiltin:       getI18nMessage(fmt.Sprintf("error.%d", ErrRoleBuiltin), "内置角色不允许删除、禁用或修改编码"),
 
 		// 菜单模块错误
 		ErrMenuNotFound:         getI18nMessage(fmt.Sprintf("error.%d", ErrMenuNotFound), "菜单不存在"),
@@ -389,6 +416,13 @@ func getCodeMessageMap() map[int]string {
 		ErrOperationLogBatchDelete: getI18nMessage(fmt.Sprintf("error.%d", ErrOperationLogBatchDelete), "批量删除操作日志失败"),
 		ErrOperationLogCleanOld:    getI18nMessage(fmt.Sprintf("error.%d", ErrOperationLogCleanOld), "清理旧操作日志失败"),
 		ErrOperationLogInvalidID:   getI18nMessage(fmt.Sprintf("error.%d", ErrOperationLogInvalidID), "操作日志ID无效"),
+
+		// 定时任务模块错误
+		ErrJobNotFound:     getI18nMessage(fmt.Sprintf("error.%d", ErrJobNotFound), "定时任务不存在"),
+		ErrJobRunning:      getI18nMessage(fmt.Sprintf("error.%d", ErrJobRunning), "定时任务正在执行"),
+		ErrJobNotStarted:   getI18nMessage(fmt.Sprintf("error.%d", ErrJobNotStarted), "定时任务调度器未运行"),
+		ErrJobLogGetList:   getI18nMessage(fmt.Sprintf("error.%d", ErrJobLogGetList), "获取定时任务执行记录失败"),
+		ErrJobStatusChange: getI18nMessage(fmt.Sprintf("error.%d", ErrJobStatusChange), "修改定时任务状态失败"),
 	}
 }
 
@@ -115,9 +115,9 @@ func (e *Error) WithData(data any) *Error {
 // StatusCode 获取对应的HTTP状态码
 func (e *Error) StatusCode() int {
 	switch {
-	case e.Code == ErrUnauthorized || e.Code == ErrInvalidToken || e.Code == ErrTokenExpired:
+	case e.Code == ErrUnauthorized || e.Code == ErrInvalidToken || e.Code == ErrTokenExpired || e.Code == ErrAuthSession:
 		return http.StatusUnauthorized
-	case e.Code == ErrForbidden || e.Code == ErrPermDenied:
+	case e.Code == ErrForbidden || e.Code == ErrPermDenied || e.Code == ErrPermRoleDisable:
 		return http.StatusForbidden
 	case e.Code == ErrNotFound:
 		return http.StatusNotFound
@@ -98,6 +98,24 @@ func T(messageID string, templateData ...map[string]interface{}) string {
 	return result
 }
 
+// TWithLanguage 使用指定语言翻译消息，不影响当前语言，用于生成邮件等面向特定用户的内容
+func TWithLanguage(lang string, messageID string, templateData ...map[string]interface{}) string {
+	Init()
+
+	cfg := &i18n.LocalizeConfig{
+		MessageID: messageID,
+	}
+	if len(templateData) > 0 {
+		cfg.TemplateData = templateData[0]
+	}
+
+	result, err := i18n.NewLocalizer(bundle, GetLanguageFromAcceptLanguage(lang)).Localize(cfg)
+	if err != nil {
+		return messageID
+	}
+	return result
+}
+
 // TWithField 翻译带字段名的消息
 func TWithField(messageID string, fieldName string) string {
 	return T(messageID, map[string]interface{}{
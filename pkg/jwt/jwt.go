@@ -14,24 +14,37 @@ import (
 	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // TokenType token类型
 type TokenType string
 
 const (
-	AccessTokenType  TokenType = "access"
-	RefreshTokenType TokenType = "refresh"
+	AccessTokenType    TokenType = "access"
+	RefreshTokenType   TokenType = "refresh"
+	TwoFactorTokenType TokenType = "2fa"   // 登录第二步凭证，仅可用于完成双因素认证
+	PasswordTokenType  TokenType = "pwd"   // 修改密码凭证，仅可用于调用修改密码接口
+	ResetTokenType     TokenType = "reset" // 找回密码凭证，通过邮件发送，仅可用于重置密码
+)
+
+const (
+	// defaultTwoFactorTokenTTL 登录第二步凭证默认有效期
+	defaultTwoFactorTokenTTL = 5 * time.Minute
+	// passwordTokenTTL 修改密码凭证有效期
+	passwordTokenTTL = 10 * time.Minute
 )
 
 // CustomClaims 自定义JWT声明
 type CustomClaims struct {
 	UserID     uint      `json:"user_id"`
 	Username   string    `json:"username"`
-	RoleID     uint      `json:"role_id"`
+	RoleIDs    []uint    `json:"role_ids"`
 	AppKey     string    `json:"app_key"`
 	RememberMe bool      `json:"remember_me"`
 	TokenType  TokenType `json:"token_type"`
+	FamilyID   string    `json:"fid,omitempty"` // token族ID，同一次登录及其后续刷新签发的token属于同一族
+	Version    uint      `json:"ver,omitempty"` // 签发时用户的token版本号，与用户当前版本号不一致时token失效
 	jwt.RegisteredClaims
 }
 
@@ -41,18 +54,25 @@ type TokenPair struct {
 	RefreshToken     string `json:"refresh_token"`
 	ExpiresIn        int64  `json:"expires_in"`         // access token过期时间（秒）
 	RefreshExpiresIn int64  `json:"refresh_expires_in"` // refresh token过期时间（秒）
+	RefreshTokenID   string `json:"-"`                  // refresh token的jti
+	FamilyID         string `json:"-"`                  // token族ID
 }
 
-// GenerateTokenPair 生成JWT令牌对（access token和refresh token）
-func GenerateTokenPair(userID uint, username string, roleID uint, appKey, appSecret string, rememberMe bool) (*TokenPair, error) {
+// GenerateTokenPair 生成JWT令牌对（access token和refresh token），并开启新的token族
+func GenerateTokenPair(userID uint, username string, roleIDs []uint, version uint, appKey, appSecret string, rememberMe bool) (*TokenPair, error) {
+	return GenerateTokenPairInFamily(uuid.NewString(), userID, username, roleIDs, version, appKey, appSecret, rememberMe)
+}
+
+// GenerateTokenPairInFamily 在指定token族中生成JWT令牌对，用于刷新token时轮换
+func GenerateTokenPairInFamily(familyID string, userID uint, username string, roleIDs []uint, version uint, appKey, appSecret string, rememberMe bool) (*TokenPair, error) {
 	// 生成access token
-	accessToken, accessExpiresIn, err := generateSingleToken(userID, username, roleID, appKey, appSecret, rememberMe, AccessTokenType)
+	accessToken, _, accessExpiresIn, err := generateSingleToken(familyID, userID, username, roleIDs, version, appKey, appSecret, rememberMe, AccessTokenType)
 	if err != nil {
 		return nil, err
 	}
 
 	// 生成refresh token
-	refreshToken, refreshExpiresIn, err := generateSingleToken(userID, username, roleID, appKey, appSecret, rememberMe, RefreshTokenType)
+	refreshToken, refreshTokenID, refreshExpiresIn, err := generateSingleToken(familyID, userID, username, roleIDs, version, appKey, appSecret, rememberMe, RefreshTokenType)
 	if err != nil {
 		return nil, err
 	}
@@ -62,11 +82,22 @@ func GenerateTokenPair(userID uint, username string, roleID uint, appKey, appSec
 		RefreshToken:     refreshToken,
 		ExpiresIn:        accessExpiresIn,
 		RefreshExpiresIn: refreshExpiresIn,
+		RefreshTokenID:   refreshTokenID,
+		FamilyID:         familyID,
 	}, nil
 }
 
-// generateSingleToken 生成单个JWT令牌
-func generateSingleToken(userID uint, username string, roleID uint, appKey, appSecret string, rememberMe bool, tokenType TokenType) (string, int64, error) {
+// RefreshTokenTTL 获取refresh token的有效期，token族中任意token都不会晚于签发时间加该有效期过期
+func RefreshTokenTTL(rememberMe bool) time.Duration {
+	jwtConfig := config.GetJWTConfig()
+	if rememberMe {
+		return jwtConfig.RememberMeExpireTime * 2 // refresh token过期时间是记住我的2倍
+	}
+	return jwtConfig.ExpireTime * 7 // refresh token过期时间是普通token的7倍
+}
+
+// generateSingleToken 生成单个JWT令牌，返回token字符串、jti和过期时间（秒）
+func generateSingleToken(familyID string, userID uint, username string, roleIDs []uint, version uint, appKey, appSecret string, rememberMe bool, tokenType TokenType) (string, string, int64, error) {
 	jwtConfig := config.GetJWTConfig()
 
 	// 二次加密
@@ -76,11 +107,7 @@ func generateSingleToken(userID uint, username string, roleID uint, appKey, appS
 	var expireTime time.Duration
 	if tokenType == RefreshTokenType {
 		// refresh token有更长的过期时间
-		if rememberMe {
-			expireTime = jwtConfig.RememberMeExpireTime * 2 // refresh token过期时间是记住我的2倍
-		} else {
-			expireTime = jwtConfig.ExpireTime * 7 // refresh token过期时间是普通token的7倍
-		}
+		expireTime = RefreshTokenTTL(rememberMe)
 	} else {
 		// access token使用正常过期时间
 		if rememberMe {
@@ -91,14 +118,18 @@ func generateSingleToken(userID uint, username string, roleID uint, appKey, appS
 	}
 
 	// 创建声明
+	tokenID := uuid.NewString()
 	claims := CustomClaims{
 		UserID:     userID,
 		Username:   username,
-		RoleID:     roleID,
+		RoleIDs:    roleIDs,
 		RememberMe: rememberMe,
 		AppKey:     encryptedKey,
 		TokenType:  tokenType,
+		FamilyID:   familyID,
+		Version:    version,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expireTime)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -107,31 +138,73 @@ func generateSingleToken(userID uint, username string, roleID uint, appKey, appS
 		},
 	}
 
-	// 创建令牌
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// 创建并签名令牌
+	tokenString, err := signToken(claims)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	// 返回token字符串、jti和过期时间（秒）
+	return tokenString, tokenID, int64(expireTime.Seconds()), nil
+}
 
-	// 签名令牌
-	tokenString, err := token.SignedString([]byte(jwtConfig.Secret))
+// GenerateTwoFactorToken 生成登录第二步凭证，密码或验证码校验通过后签发，完成双因素认证后才能换取token对
+func GenerateTwoFactorToken(userID uint, username string, version uint, rememberMe bool) (string, int64, error) {
+	expireTime := config.GetTwoFactorConfig().ChallengeTTL
+	if expireTime <= 0 {
+		expireTime = defaultTwoFactorTokenTTL
+	}
+	token, _, expiresIn, err := generateRestrictedToken(TwoFactorTokenType, userID, username, version, rememberMe, expireTime)
+	return token, expiresIn, err
+}
+
+// GeneratePasswordToken 生成修改密码凭证，用户必须修改密码时代替token对签发
+// 修改密码后token版本号递增，凭证随之失效
+func GeneratePasswordToken(userID uint, username string, version uint) (string, int64, error) {
+	token, _, expiresIn, err := generateRestrictedToken(PasswordTokenType, userID, username, version, false, passwordTokenTTL)
+	return token, expiresIn, err
+}
+
+// GenerateResetToken 生成找回密码凭证，返回token字符串和jti
+// 重置密码后token版本号递增，凭证随之失效
+func GenerateResetToken(userID uint, username string, version uint, ttl time.Duration) (string, string, error) {
+	token, tokenID, _, err := generateRestrictedToken(ResetTokenType, userID, username, version, false, ttl)
+	return token, tokenID, err
+}
+
+// generateRestrictedToken 生成仅用于特定用途的短期凭证，不属于任何token族，返回token字符串、jti和过期时间（秒）
+func generateRestrictedToken(tokenType TokenType, userID uint, username string, version uint, rememberMe bool, expireTime time.Duration) (string, string, int64, error) {
+	jwtConfig := config.GetJWTConfig()
+
+	tokenID := uuid.NewString()
+	claims := CustomClaims{
+		UserID:     userID,
+		Username:   username,
+		RememberMe: rememberMe,
+		TokenType:  tokenType,
+		Version:    version,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expireTime)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    jwtConfig.Issuer,
+			Subject:   username,
+		},
+	}
+
+	tokenString, err := signToken(claims)
 	if err != nil {
-		return "", 0, err
+		return "", "", 0, err
 	}
 
-	// 返回token字符串和过期时间（秒）
-	return tokenString, int64(expireTime.Seconds()), nil
+	return tokenString, tokenID, int64(expireTime.Seconds()), nil
 }
 
 // ParseToken 解析JWT令牌
 func ParseToken(tokenString string) (*CustomClaims, error) {
-	jwtConfig := config.GetJWTConfig()
-
-	// 解析令牌
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// 验证签名方法
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(jwtConfig.Secret), nil
-	})
+	// 解析令牌，按配置的签名算法及header中的kid选择验证密钥
+	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, verificationKey)
 
 	if err != nil {
 		// 检查是否是过期错误
@@ -167,8 +240,12 @@ func RefreshTokenPair(refreshTokenString string, appSecret string) (*TokenPair,
 		return nil, kperrors.New(kperrors.ErrInvalidToken, nil)
 	}
 
-	// 生成新的token对
-	return GenerateTokenPair(claims.UserID, claims.Username, claims.RoleID, claims.Username, appSecret, claims.RememberMe)
+	// 在同一token族中生成新的token对，升级前签发的token没有族ID时开启新的token族
+	familyID := claims.FamilyID
+	if familyID == "" {
+		familyID = uuid.NewString()
+	}
+	return GenerateTokenPairInFamily(familyID, claims.UserID, claims.Username, claims.RoleIDs, claims.Version, claims.Username, appSecret, claims.RememberMe)
 }
 
 // encryptWithAppSecret 使用AppSecret进行二次加密
@@ -240,14 +317,14 @@ func GetUsername(c *gin.Context) string {
 	return ""
 }
 
-// GetRoleID 从上下文中获取角色ID
-func GetRoleID(c *gin.Context) uint {
-	if roleID, exists := c.Get("role_id"); exists {
-		if id, ok := roleID.(uint); ok {
-			return id
+// GetRoleIDs 从上下文中获取角色ID列表
+func GetRoleIDs(c *gin.Context) []uint {
+	if roleIDs, exists := c.Get("role_ids"); exists {
+		if ids, ok := roleIDs.([]uint); ok {
+			return ids
 		}
 	}
-	return 0
+	return nil
 }
 
 // GetAppKey 从上下文中获取AppKey
@@ -260,6 +337,16 @@ func GetAppKey(c *gin.Context) string {
 	return ""
 }
 
+// GetSessionID 从上下文中获取登录会话ID
+func GetSessionID(c *gin.Context) string {
+	if sessionID, exists := c.Get("session_id"); exists {
+		if id, ok := sessionID.(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
 // ExtractTokenFromHeader 从HTTP请求头中提取JWT token
 // 支持 "Authorization: Bearer <token>" 格式
 // 返回提取的token字符串，如果格式不正确或不存在则返回空字符串
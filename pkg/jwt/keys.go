@@ -0,0 +1,322 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 签名算法
+const (
+	AlgHS256 = "HS256" // HMAC-SHA256，使用 jwt.secret 签名和验证
+	AlgRS256 = "RS256" // RSA-SHA256
+	AlgES256 = "ES256" // ECDSA P-256
+	AlgEdDSA = "EdDSA" // Ed25519
+)
+
+// signingKey 非对称密钥
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.PrivateKey
+	public  crypto.PublicKey
+}
+
+// keySet 已加载的非对称密钥，active为签名密钥
+type keySet struct {
+	active *signingKey
+	keys   map[string]*signingKey
+	order  []string
+}
+
+var (
+	keys   *keySet
+	keysMu sync.RWMutex
+)
+
+// Init 按配置加载签名密钥，使用非对称算法时需在签发token前调用
+// 密钥文件变更后需重启服务生效
+func Init() error {
+	set, err := loadKeySet(config.GetJWTConfig())
+	if err != nil {
+		return err
+	}
+
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	keys = set
+	return nil
+}
+
+// getKeySet 获取已加载的非对称密钥，使用HS256时返回nil
+func getKeySet() *keySet {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	return keys
+}
+
+// loadKeySet 加载并校验配置的密钥
+func loadKeySet(cfg config.JWTConfig) (*keySet, error) {
+	alg := cfg.Algorithm
+	if alg == "" || alg == AlgHS256 {
+		return nil, nil
+	}
+	if methodForAlg(alg) == nil {
+		return nil, fmt.Errorf("不支持的JWT签名算法: %s", alg)
+	}
+	if len(cfg.Keys) == 0 {
+		return nil, fmt.Errorf("签名算法 %s 需要配置 jwt.keys", alg)
+	}
+
+	set := &keySet{keys: make(map[string]*signingKey, len(cfg.Keys))}
+	for _, keyCfg := range cfg.Keys {
+		if keyCfg.KID == "" {
+			return nil, fmt.Errorf("jwt.keys 中存在未配置kid的密钥")
+		}
+		if _, ok := set.keys[keyCfg.KID]; ok {
+			return nil, fmt.Errorf("jwt.keys 中的kid重复: %s", keyCfg.KID)
+		}
+
+		key, err := loadKey(keyCfg)
+		if err != nil {
+			return nil, fmt.Errorf("加载JWT密钥 %s 失败: %w", keyCfg.KID, err)
+		}
+		set.keys[key.kid] = key
+		set.order = append(set.order, key.kid)
+	}
+
+	active, ok := set.keys[cfg.ActiveKID]
+	if !ok {
+		return nil, fmt.Errorf("jwt.active_kid 未配置或不存在: %s", cfg.ActiveKID)
+	}
+	if active.private == nil {
+		return nil, fmt.Errorf("签名密钥 %s 未配置私钥", active.kid)
+	}
+	if active.method.Alg() != alg {
+		return nil, fmt.Errorf("签名密钥 %s 的类型与签名算法 %s 不匹配", active.kid, alg)
+	}
+	set.active = active
+
+	return set, nil
+}
+
+// loadKey 从PEM文件加载密钥，根据密钥类型确定签名算法
+func loadKey(cfg config.JWTKeyConfig) (*signingKey, error) {
+	key := &signingKey{kid: cfg.KID}
+
+	if cfg.PrivateKeyFile != "" {
+		block, err := readPEM(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		private, err := parsePrivateKey(block)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("不支持的私钥类型 %T", private)
+		}
+		key.private = private
+		key.public = signer.Public()
+	}
+
+	if cfg.PublicKeyFile != "" {
+		block, err := readPEM(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		public, err := parsePublicKey(block)
+		if err != nil {
+			return nil, err
+		}
+		key.public = public
+	}
+
+	if key.public == nil {
+		return nil, fmt.Errorf("未配置私钥或公钥文件")
+	}
+
+	key.method = methodForKey(key.public)
+	if key.method == nil {
+		return nil, fmt.Errorf("不支持的密钥类型 %T，仅支持RSA、ECDSA P-256和Ed25519", key.public)
+	}
+	return key, nil
+}
+
+// readPEM 读取PEM文件中的第一个块
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s 不是有效的PEM文件", path)
+	}
+	return block, nil
+}
+
+// parsePrivateKey 解析PKCS#8、PKCS#1或SEC 1格式的私钥
+func parsePrivateKey(block *pem.Block) (crypto.PrivateKey, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("不支持的私钥PEM类型: %s", block.Type)
+	}
+}
+
+// parsePublicKey 解析PKIX或PKCS#1格式的公钥，也支持从证书中提取公钥
+func parsePublicKey(block *pem.Block) (crypto.PublicKey, error) {
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("不支持的公钥PEM类型: %s", block.Type)
+	}
+}
+
+// methodForAlg 根据算法名称获取非对称签名方法
+func methodForAlg(alg string) jwt.SigningMethod {
+	switch alg {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgES256:
+		return jwt.SigningMethodES256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return nil
+	}
+}
+
+// methodForKey 根据公钥类型确定签名方法
+func methodForKey(public crypto.PublicKey) jwt.SigningMethod {
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		if k.Curve == elliptic.P256() {
+			return jwt.SigningMethodES256
+		}
+		return nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA
+	default:
+		return nil
+	}
+}
+
+// signToken 使用当前签名密钥签名，非对称算法时在header中写入kid
+func signToken(claims jwt.Claims) (string, error) {
+	set := getKeySet()
+	if set == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.GetJWTConfig().Secret))
+	}
+
+	token := jwt.NewWithClaims(set.active.method, claims)
+	token.Header["kid"] = set.active.kid
+	return token.SignedString(set.active.private)
+}
+
+// verificationKey 根据token header选择验证密钥，签名算法必须与密钥类型一致
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	set := getKeySet()
+	if set == nil {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(config.GetJWTConfig().Secret), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := set.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid: %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.public, nil
+}
+
+// JWK JSON Web Key，仅包含公钥参数
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// GetJWKS 获取全部验证公钥，供其他服务验证token，使用HS256时返回空列表
+func GetJWKS() *JWKS {
+	jwks := &JWKS{Keys: []JWK{}}
+
+	set := getKeySet()
+	if set == nil {
+		return jwks
+	}
+
+	for _, kid := range set.order {
+		key := set.keys[kid]
+		jwk := JWK{Kid: key.kid, Use: "sig", Alg: key.method.Alg()}
+		switch k := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = encodeBase64URL(k.N.Bytes())
+			jwk.E = encodeBase64URL(big.NewInt(int64(k.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (k.Curve.Params().BitSize + 7) / 8
+			jwk.Kty = "EC"
+			jwk.Crv = k.Curve.Params().Name
+			jwk.X = encodeBase64URL(k.X.FillBytes(make([]byte, size)))
+			jwk.Y = encodeBase64URL(k.Y.FillBytes(make([]byte, size)))
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = encodeBase64URL(k)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+// encodeBase64URL 无填充的Base64URL编码
+func encodeBase64URL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,168 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+// 默认配置
+const (
+	defaultUserFilter = "(uid={username})"
+	defaultMemberOf   = "memberOf"
+	defaultTimeout    = 10 * time.Second
+)
+
+// ErrInvalidCredentials 用户不存在、匹配到多个用户或密码错误
+var ErrInvalidCredentials = errors.New("ldap: 用户名或密码错误")
+
+// User 通过认证的目录用户
+type User struct {
+	*goldap.Entry
+	Groups []string // 所属用户组的DN
+}
+
+// GetAttributeValues 获取属性的全部值，属性名称不区分大小写
+func (u *User) GetAttributeValues(name string) []string {
+	return u.Entry.GetEqualFoldAttributeValues(name)
+}
+
+// GetAttributeValue 获取属性的第一个值，属性名称不区分大小写，属性不存在时返回空字符串
+func (u *User) GetAttributeValue(name string) string {
+	return u.Entry.GetEqualFoldAttributeValue(name)
+}
+
+// Authenticate 使用服务账号按 user_filter 查找用户，再以用户DN绑定校验密码
+// attributes 为需要读取的用户属性，返回用户条目及所属用户组
+func Authenticate(username, password string, attributes ...string) (*User, error) {
+	return authenticate(config.GetLDAPConfig(), username, password, attributes...)
+}
+
+// authenticate 按指定配置认证目录用户
+func authenticate(cfg config.LDAPConfig, username, password string, attributes ...string) (*User, error) {
+	if password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := dial(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := bindService(conn, cfg); err != nil {
+		return nil, err
+	}
+
+	userFilter := cfg.UserFilter
+	if userFilter == "" {
+		userFilter = defaultUserFilter
+	}
+	memberOf := cfg.Attributes.MemberOf
+	if memberOf == "" {
+		memberOf = defaultMemberOf
+	}
+	if cfg.GroupFilter == "" {
+		attributes = append(attributes, memberOf)
+	}
+
+	// 最多查找两条，匹配到多个用户时拒绝登录
+	result, err := conn.Search(goldap.NewSearchRequest(
+		cfg.BaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 2, 0, false,
+		strings.ReplaceAll(userFilter, "{username}", goldap.EscapeFilter(username)),
+		attributes, nil,
+	))
+	if err != nil && !goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) {
+		return nil, err
+	}
+	if result == nil || len(result.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	user := &User{Entry: entry}
+	if cfg.GroupFilter == "" {
+		user.Groups = user.GetAttributeValues(memberOf)
+		return user, nil
+	}
+
+	// 用户本身可能没有查找用户组的权限，使用服务账号重新绑定
+	if err := bindService(conn, cfg); err != nil {
+		return nil, err
+	}
+	groupBaseDN := cfg.GroupBaseDN
+	if groupBaseDN == "" {
+		groupBaseDN = cfg.BaseDN
+	}
+	groups, err := conn.Search(goldap.NewSearchRequest(
+		groupBaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, 0, false,
+		strings.ReplaceAll(cfg.GroupFilter, "{dn}", goldap.EscapeFilter(entry.DN)),
+		[]string{"1.1"}, nil, // 只返回DN
+	))
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups.Entries {
+		user.Groups = append(user.Groups, group.DN)
+	}
+	return user, nil
+}
+
+// dial 连接LDAP服务器，ldap://连接按配置升级为TLS
+func dial(cfg config.LDAPConfig) (*goldap.Conn, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: 无效的服务器地址 %q: %w", cfg.URL, err)
+	}
+	if u.Scheme != "ldap" && u.Scheme != "ldaps" {
+		return nil, fmt.Errorf("ldap: 不支持的协议 %q", u.Scheme)
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	tlsConfig := &tls.Config{ServerName: u.Hostname(), InsecureSkipVerify: cfg.InsecureSkipVerify}
+
+	conn, err := goldap.DialURL(cfg.URL,
+		goldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		goldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(timeout)
+
+	if u.Scheme == "ldap" && cfg.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// bindService 使用服务账号绑定，未配置服务账号时匿名查找
+func bindService(conn *goldap.Conn, cfg config.LDAPConfig) error {
+	if cfg.BindDN == "" {
+		return nil
+	}
+	if err := conn.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+		return fmt.Errorf("ldap: 服务账号绑定失败: %w", err)
+	}
+	return nil
+}
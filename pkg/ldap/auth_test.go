@@ -0,0 +1,148 @@
+package ldap
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+func testConfig(s *stubServer) config.LDAPConfig {
+	return config.LDAPConfig{
+		Enable:       true,
+		URL:          s.url(),
+		Timeout:      2 * time.Second,
+		BindDN:       testServiceDN,
+		BindPassword: testServicePassword,
+		BaseDN:       testPeopleDN,
+	}
+}
+
+func TestAuthenticateMemberOf(t *testing.T) {
+	s := newStubServer(t)
+
+	user, err := authenticate(testConfig(s), "zhangsan", testUserPassword, "displayName", "mail")
+	if err != nil {
+		t.Fatalf("authenticate error: %v", err)
+	}
+	if user.DN != testUserDN {
+		t.Errorf("DN = %q", user.DN)
+	}
+	if got := user.GetAttributeValue("displayName"); got != "小张" {
+		t.Errorf("displayName = %q", got)
+	}
+	if got := user.GetAttributeValue("mail"); got != "zhangsan@example.com" {
+		t.Errorf("mail = %q", got)
+	}
+	if !slices.Equal(user.Groups, []string{testDevGroupDN}) {
+		t.Errorf("Groups = %v", user.Groups)
+	}
+	if got, want := s.bindDNs(), []string{testServiceDN, testUserDN}; !slices.Equal(got, want) {
+		t.Errorf("绑定顺序 = %v, want %v", got, want)
+	}
+}
+
+func TestAuthenticateGroupFilter(t *testing.T) {
+	s := newStubServer(t)
+	cfg := testConfig(s)
+	cfg.GroupBaseDN = testGroupsDN
+	cfg.GroupFilter = "(&(objectClass=groupOfNames)(member={dn}))"
+
+	user, err := authenticate(cfg, "zhangsan", testUserPassword)
+	if err != nil {
+		t.Fatalf("authenticate error: %v", err)
+	}
+	if !slices.Equal(user.Groups, []string{testDevGroupDN}) {
+		t.Errorf("Groups = %v", user.Groups)
+	}
+	// 查找用户组前使用服务账号重新绑定
+	if got, want := s.bindDNs(), []string{testServiceDN, testUserDN, testServiceDN}; !slices.Equal(got, want) {
+		t.Errorf("绑定顺序 = %v, want %v", got, want)
+	}
+}
+
+func TestAuthenticateInvalidCredentials(t *testing.T) {
+	s := newStubServer(t)
+	cfg := testConfig(s)
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{"wrong password", "zhangsan", "wrong"},
+		{"empty password", "zhangsan", ""},
+		{"unknown user", "wangwu", testUserPassword},
+		{"multiple users", "dup", testUserPassword},
+		{"wildcard", "*", testUserPassword},
+		{"filter injection", "zhangsan)(uid=*", testUserPassword},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := authenticate(cfg, tt.username, tt.password); !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("authenticate(%q) error = %v, want ErrInvalidCredentials", tt.username, err)
+			}
+		})
+	}
+}
+
+func TestAuthenticateServiceBindFailure(t *testing.T) {
+	s := newStubServer(t)
+	cfg := testConfig(s)
+	cfg.BindPassword = "wrong"
+
+	// 服务账号配置错误不是用户凭据错误，不能计入用户的登录失败次数
+	_, err := authenticate(cfg, "zhangsan", testUserPassword)
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("authenticate error = %v, want service bind error", err)
+	}
+	if !goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+		t.Errorf("error = %v, want wrapped result code %d", err, goldap.LDAPResultInvalidCredentials)
+	}
+}
+
+func TestAuthenticateUnavailable(t *testing.T) {
+	for _, url := range []string{"ldap://127.0.0.1:1", "http://127.0.0.1:389", "ldapi:///var/run/slapd.sock", "ldap://[::1"} {
+		cfg := config.LDAPConfig{URL: url, Timeout: time.Second}
+		if _, err := authenticate(cfg, "zhangsan", testUserPassword); err == nil || errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("authenticate(%q) error = %v, want connection error", url, err)
+		}
+	}
+}
+
+func TestAuthenticateMissingBaseDN(t *testing.T) {
+	s := newStubServer(t)
+	cfg := testConfig(s)
+	cfg.BaseDN = "ou=missing,dc=example,dc=com"
+
+	// 根DN配置错误不是用户凭据错误
+	_, err := authenticate(cfg, "zhangsan", testUserPassword)
+	if !goldap.IsErrorWithCode(err, goldap.LDAPResultNoSuchObject) {
+		t.Errorf("authenticate error = %v, want result code %d", err, goldap.LDAPResultNoSuchObject)
+	}
+}
+
+func TestUserAttributesIgnoreCase(t *testing.T) {
+	s := newStubServer(t)
+
+	// 服务器返回的属性名称大小写可能与配置不同
+	user, err := authenticate(testConfig(s), "zhangsan", testUserPassword, "CN", "Mail")
+	if err != nil {
+		t.Fatalf("authenticate error: %v", err)
+	}
+	if got := user.GetAttributeValue("cn"); got != "张三" {
+		t.Errorf("cn = %q", got)
+	}
+	if got := user.GetAttributeValue("MAIL"); got != "zhangsan@example.com" {
+		t.Errorf("mail = %q", got)
+	}
+	if got := user.GetAttributeValue("telephoneNumber"); got != "" {
+		t.Errorf("不存在的属性 = %q", got)
+	}
+	if got := user.GetAttributeValues("uid"); len(got) != 0 {
+		t.Errorf("返回了未请求的属性 uid = %v", got)
+	}
+}
@@ -0,0 +1,263 @@
+package ldap
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+// 测试目录中的账号
+const (
+	testServiceDN       = "cn=readonly,dc=example,dc=com"
+	testServicePassword = "readonly-secret"
+	testPeopleDN        = "ou=people,dc=example,dc=com"
+	testGroupsDN        = "ou=groups,dc=example,dc=com"
+	testUserDN          = "uid=zhangsan,ou=people,dc=example,dc=com"
+	testUserPassword    = "zhangsan-secret"
+	testDevGroupDN      = "cn=dev,ou=groups,dc=example,dc=com"
+	testOpsGroupDN      = "cn=ops,ou=groups,dc=example,dc=com"
+)
+
+// stubEntry 测试目录中的条目，属性名称使用小写
+type stubEntry struct {
+	dn    string
+	attrs map[string][]string
+}
+
+// values 获取属性的值，属性名称不区分大小写
+func (e *stubEntry) values(name string) []string {
+	return e.attrs[strings.ToLower(name)]
+}
+
+// stubServer 进程内的LDAP测试服务器，支持简单绑定、查找及解绑
+type stubServer struct {
+	listener  net.Listener
+	passwords map[string]string // DN对应的密码
+	entries   []*stubEntry
+
+	mu    sync.Mutex
+	binds []string // 收到的绑定请求DN，按顺序记录
+}
+
+func newStubServer(t *testing.T) *stubServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听端口失败: %v", err)
+	}
+	s := &stubServer{
+		listener: listener,
+		passwords: map[string]string{
+			testServiceDN: testServicePassword,
+			testUserDN:    testUserPassword,
+		},
+		entries: []*stubEntry{
+			{dn: testUserDN, attrs: map[string][]string{
+				"objectclass": {"inetOrgPerson"},
+				"uid":         {"zhangsan"},
+				"cn":          {"张三"},
+				"displayname": {"小张"},
+				"mail":        {"zhangsan@example.com"},
+				"memberof":    {testDevGroupDN},
+			}},
+			{dn: "uid=lisi,ou=people,dc=example,dc=com", attrs: map[string][]string{
+				"objectclass": {"inetOrgPerson"},
+				"uid":         {"lisi"},
+			}},
+			{dn: "uid=dup,ou=people,dc=example,dc=com", attrs: map[string][]string{
+				"objectclass": {"inetOrgPerson"},
+				"uid":         {"dup"},
+			}},
+			{dn: "uid=dup,ou=contractors,ou=people,dc=example,dc=com", attrs: map[string][]string{
+				"objectclass": {"inetOrgPerson"},
+				"uid":         {"dup"},
+			}},
+			{dn: testDevGroupDN, attrs: map[string][]string{
+				"objectclass": {"groupOfNames"},
+				"member":      {testUserDN, "uid=lisi,ou=people,dc=example,dc=com"},
+			}},
+			{dn: testOpsGroupDN, attrs: map[string][]string{
+				"objectclass": {"groupOfNames"},
+				"member":      {"uid=lisi,ou=people,dc=example,dc=com"},
+			}},
+		},
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+// url 服务器地址
+func (s *stubServer) url() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+// bindDNs 获取收到的绑定请求DN
+func (s *stubServer) bindDNs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.binds...)
+}
+
+// serve 处理一个连接上的请求，收到解绑请求或连接关闭时结束
+func (s *stubServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		msg, err := ber.ReadPacket(reader)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+		msgID := msg.Children[0].Value.(int64)
+		op := msg.Children[1]
+
+		var responses []*ber.Packet
+		switch op.Tag {
+		case goldap.ApplicationBindRequest:
+			responses = append(responses, s.bind(op))
+		case goldap.ApplicationSearchRequest:
+			responses = s.search(op)
+		default:
+			return
+		}
+
+		for _, resp := range responses {
+			packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, msgID, "MessageID"))
+			packet.AppendChild(resp)
+			if _, err := conn.Write(packet.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// bind 校验绑定请求的DN及密码
+func (s *stubServer) bind(op *ber.Packet) *ber.Packet {
+	dn, password := op.Children[1].Data.String(), op.Children[2].Data.String()
+	s.mu.Lock()
+	s.binds = append(s.binds, dn)
+	s.mu.Unlock()
+
+	if want, ok := s.passwords[dn]; !ok || password == "" || password != want {
+		return ldapResult(goldap.ApplicationBindResponse, goldap.LDAPResultInvalidCredentials, "invalid credentials")
+	}
+	return ldapResult(goldap.ApplicationBindResponse, goldap.LDAPResultSuccess, "")
+}
+
+// search 按根DN、过滤器及返回条目数上限查找条目
+func (s *stubServer) search(op *ber.Packet) []*ber.Packet {
+	baseDN := strings.ToLower(op.Children[0].Data.String())
+	sizeLimit := op.Children[3].Value.(int64)
+	filter := op.Children[6]
+	var attrs []string
+	for _, attr := range op.Children[7].Children {
+		attrs = append(attrs, attr.Data.String())
+	}
+
+	var responses []*ber.Packet
+	found := false
+	for _, entry := range s.entries {
+		if !strings.HasSuffix(strings.ToLower(entry.dn), baseDN) {
+			continue
+		}
+		found = true
+		if !matchFilter(filter, entry) {
+			continue
+		}
+		if sizeLimit > 0 && int64(len(responses)) >= sizeLimit {
+			return append(responses, ldapResult(goldap.ApplicationSearchResultDone, goldap.LDAPResultSizeLimitExceeded, "size limit exceeded"))
+		}
+		responses = append(responses, searchEntry(entry, attrs))
+	}
+	if !found {
+		return []*ber.Packet{ldapResult(goldap.ApplicationSearchResultDone, goldap.LDAPResultNoSuchObject, "no such object")}
+	}
+	return append(responses, ldapResult(goldap.ApplicationSearchResultDone, goldap.LDAPResultSuccess, ""))
+}
+
+// matchFilter 判断条目是否满足过滤器，支持与、或、非、相等及存在条件，不区分大小写
+func matchFilter(filter *ber.Packet, entry *stubEntry) bool {
+	switch filter.Tag {
+	case goldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !matchFilter(child, entry) {
+				return false
+			}
+		}
+		return true
+	case goldap.FilterOr:
+		for _, child := range filter.Children {
+			if matchFilter(child, entry) {
+				return true
+			}
+		}
+		return false
+	case goldap.FilterNot:
+		return !matchFilter(filter.Children[0], entry)
+	case goldap.FilterPresent:
+		return len(entry.values(filter.Data.String())) > 0
+	case goldap.FilterEqualityMatch:
+		for _, value := range entry.values(filter.Children[0].Data.String()) {
+			if strings.EqualFold(value, filter.Children[1].Data.String()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// searchEntry 创建查找结果条目，只返回请求的属性，属性名称使用请求中的大小写
+func searchEntry(entry *stubEntry, attrs []string) *ber.Packet {
+	list := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	names := attrs
+	if len(names) == 0 {
+		for name := range entry.attrs {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		values := entry.values(name)
+		if len(values) == 0 {
+			continue
+		}
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Name"))
+		attr.AppendChild(set)
+		list.AppendChild(attr)
+	}
+
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, goldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "DN"))
+	packet.AppendChild(list)
+	return packet
+}
+
+// ldapResult 创建LDAPResult响应
+func ldapResult(tag ber.Tag, code int64, message string) *ber.Packet {
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "LDAP Result")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "Result Code"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "Diagnostic Message"))
+	return packet
+}
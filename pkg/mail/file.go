@@ -0,0 +1,41 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	"github.com/google/uuid"
+)
+
+// defaultMailDir 文件驱动默认的邮件保存目录
+const defaultMailDir = "storage/mails"
+
+// FileMailer 将邮件保存为.eml文件，可使用邮件客户端直接打开查看
+type FileMailer struct{}
+
+// Send 将邮件写入配置的目录
+func (m *FileMailer) Send(msg *Message) error {
+	cfg := config.GetMailConfig()
+	if cfg.From == "" {
+		cfg.From = "noreply@localhost"
+	}
+
+	data, err := buildMessage(cfg, msg)
+	if err != nil {
+		return err
+	}
+
+	dir := cfg.Dir
+	if dir == "" {
+		dir = defaultMailDir
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102150405"), uuid.NewString()[:8])
+	return os.WriteFile(filepath.Join(dir, name), data, 0o600)
+}
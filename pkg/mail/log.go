@@ -0,0 +1,26 @@
+package mail
+
+import (
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// LogMailer 将邮件内容输出到日志，禁止在生产环境使用
+type LogMailer struct{}
+
+// Send 将邮件内容输出到日志
+func (m *LogMailer) Send(msg *Message) error {
+	// 邮件中的重置链接等敏感信息会写入日志，生产环境禁止使用
+	if config.IsProduction() {
+		return kperrors.New(kperrors.ErrSystem, nil).WithMessage("生产环境不允许使用日志邮件驱动")
+	}
+
+	logger.GetLogger().Info("发送邮件",
+		zap.Strings("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body),
+	)
+	return nil
+}
@@ -0,0 +1,62 @@
+package mail
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+)
+
+// 邮件发送驱动
+const (
+	DriverSMTP = "smtp" // 通过SMTP服务器发送
+	DriverFile = "file" // 写入目录中的.eml文件，适用于开发及测试环境
+	DriverLog  = "log"  // 仅输出到日志，适用于开发环境
+)
+
+// Message 邮件内容
+type Message struct {
+	To      []string // 收件人
+	Subject string   // 主题
+	Body    string   // 纯文本正文
+}
+
+// Mailer 邮件发送接口
+// 自定义实现需在服务启动时通过 Register 注册，并将 mail.driver 配置为注册名称
+type Mailer interface {
+	// Send 发送邮件
+	Send(msg *Message) error
+}
+
+var (
+	mailers = map[string]Mailer{
+		DriverSMTP: &SMTPMailer{},
+		DriverFile: &FileMailer{},
+		DriverLog:  &LogMailer{},
+	}
+	mailersMu sync.RWMutex
+)
+
+// Register 注册邮件发送实现
+func Register(driver string, mailer Mailer) {
+	mailersMu.Lock()
+	defer mailersMu.Unlock()
+	mailers[driver] = mailer
+}
+
+// GetMailer 获取当前配置的邮件发送实现
+func GetMailer() (Mailer, error) {
+	driver := config.GetMailConfig().Driver
+	if driver == "" {
+		driver = DriverLog
+	}
+
+	mailersMu.RLock()
+	defer mailersMu.RUnlock()
+	mailer, ok := mailers[driver]
+	if !ok {
+		return nil, kperrors.New(kperrors.ErrSystem, nil).WithMessage(fmt.Sprintf("不支持的邮件发送驱动: %s", driver))
+	}
+	return mailer, nil
+}
@@ -0,0 +1,174 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	"github.com/google/uuid"
+)
+
+// SMTP连接加密方式
+const (
+	EncryptionSTARTTLS = "starttls" // 明文连接后升级为TLS，服务器不支持时拒绝发送
+	EncryptionSSL      = "ssl"      // 直接建立TLS连接，通常使用465端口
+	EncryptionNone     = "none"     // 不加密，仅适用于本机或内网中继
+)
+
+// defaultSMTPTimeout 默认SMTP连接超时时间
+const defaultSMTPTimeout = 10 * time.Second
+
+// SMTPMailer 通过SMTP服务器发送邮件
+type SMTPMailer struct{}
+
+// Send 发送邮件
+func (m *SMTPMailer) Send(msg *Message) error {
+	cfg := config.GetMailConfig()
+	if cfg.Host == "" || cfg.From == "" {
+		return fmt.Errorf("未配置SMTP服务器或发件人地址")
+	}
+
+	data, err := buildMessage(cfg, msg)
+	if err != nil {
+		return err
+	}
+
+	client, err := dialSMTP(cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if cfg.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("SMTP服务器不支持身份认证")
+		}
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(cfg.From); err != nil {
+		return err
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// dialSMTP 按配置的加密方式连接SMTP服务器
+func dialSMTP(cfg config.MailConfig) (*smtp.Client, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultSMTPTimeout
+	}
+	port := cfg.Port
+	if port <= 0 {
+		port = 587
+		if cfg.Encryption == EncryptionSSL {
+			port = 465
+		}
+	}
+
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+
+	var conn net.Conn
+	var err error
+	dialer := &net.Dialer{Timeout: timeout}
+	if cfg.Encryption == EncryptionSSL {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 整个发送过程共用一个超时时间，避免SMTP服务器无响应时长期占用连接
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if cfg.Encryption == "" || cfg.Encryption == EncryptionSTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("SMTP服务器不支持STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+// buildMessage 生成UTF-8编码的纯文本邮件
+func buildMessage(cfg config.MailConfig, msg *Message) ([]byte, error) {
+	if len(msg.To) == 0 {
+		return nil, fmt.Errorf("未指定收件人")
+	}
+
+	from := (&mail.Address{Name: cfg.FromName, Address: cfg.From}).String()
+	to := make([]string, 0, len(msg.To))
+	for _, addr := range msg.To {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("收件人地址无效: %s", addr)
+		}
+		to = append(to, parsed.String())
+	}
+
+	domain := cfg.From[strings.LastIndex(cfg.From, "@")+1:]
+
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from + "\r\n")
+	buf.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	buf.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", msg.Subject) + "\r\n")
+	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("Message-ID: <" + uuid.NewString() + "@" + domain + ">\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n")
+	buf.WriteString("\r\n")
+
+	// 正文按每行76个字符进行Base64编码
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,401 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID    = "kunpeng-test"
+	testRedirectURL = "http://localhost/callback"
+	testKid         = "test-key"
+	testCode        = "test-code"
+	testNonce       = "test-nonce"
+)
+
+// testIdP 测试用身份提供方，提供发现文档、JWKS及令牌端点
+type testIdP struct {
+	server        *httptest.Server
+	key           *rsa.PrivateKey
+	issuer        string // 发现文档中返回的issuer，为空时使用服务地址
+	codeChallenge string // 授权请求中的code_challenge，令牌端点据此校验code_verifier
+	idToken       string // 令牌端点返回的ID token
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("生成RSA密钥失败: %v", err)
+	}
+	idp := &testIdP{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		issuer := idp.issuer
+		if issuer == "" {
+			issuer = idp.server.URL
+		}
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		pub := idp.key.PublicKey
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": testKid,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+			return
+		}
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		switch {
+		case r.PostForm.Get("grant_type") != "authorization_code",
+			r.PostForm.Get("code") != testCode,
+			r.PostForm.Get("redirect_uri") != testRedirectURL,
+			r.PostForm.Get("client_id") != testClientID,
+			base64.RawURLEncoding.EncodeToString(sum[:]) != idp.codeChallenge:
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"id_token": idp.idToken, "token_type": "Bearer"})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// provider 创建连接测试身份提供方的客户端
+func (idp *testIdP) provider(t *testing.T) *Provider {
+	t.Helper()
+
+	p, err := newProvider(config.OIDCConfig{
+		Issuer:      idp.server.URL,
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+		Scopes:      []string{"profile", "email"},
+	})
+	if err != nil {
+		t.Fatalf("创建身份提供方客户端失败: %v", err)
+	}
+	return p
+}
+
+// claims 默认的有效声明
+func (idp *testIdP) claims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"sub":   "user-1",
+		"aud":   testClientID,
+		"nonce": testNonce,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+	}
+}
+
+// sign 使用身份提供方的私钥签发ID token
+func (idp *testIdP) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKid
+	signed, err := token.SignedString(idp.key)
+	if err != nil {
+		t.Fatalf("签发ID token失败: %v", err)
+	}
+	return signed
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestNewProviderDiscovery(t *testing.T) {
+	idp := newTestIdP(t)
+	p := idp.provider(t)
+
+	if p.Issuer() != idp.server.URL {
+		t.Fatalf("issuer = %q, want %q", p.Issuer(), idp.server.URL)
+	}
+
+	authURL, err := url.Parse(p.AuthCodeURL("state-1", testNonce, "challenge-1"))
+	if err != nil {
+		t.Fatalf("解析授权地址失败: %v", err)
+	}
+	if got := authURL.Scheme + "://" + authURL.Host + authURL.Path; got != idp.server.URL+"/authorize" {
+		t.Errorf("authorization endpoint = %q", got)
+	}
+	query := authURL.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid profile email",
+		"state":                 "state-1",
+		"nonce":                 testNonce,
+		"code_challenge":        "challenge-1",
+		"code_challenge_method": "S256",
+	}
+	for name, value := range want {
+		if query.Get(name) != value {
+			t.Errorf("%s = %q, want %q", name, query.Get(name), value)
+		}
+	}
+}
+
+func TestNewProviderRejectsIssuerMismatch(t *testing.T) {
+	idp := newTestIdP(t)
+	idp.issuer = "https://attacker.example.com"
+
+	_, err := newProvider(config.OIDCConfig{
+		Issuer:      idp.server.URL,
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+	})
+	if err == nil {
+		t.Fatal("发现文档的issuer与配置不一致时应返回错误")
+	}
+}
+
+func TestExchangePKCE(t *testing.T) {
+	idp := newTestIdP(t)
+	p := idp.provider(t)
+
+	verifier, challenge, err := GeneratePKCE()
+	if err != nil {
+		t.Fatalf("生成PKCE失败: %v", err)
+	}
+	idp.codeChallenge = challenge
+	idp.idToken = idp.sign(t, idp.claims())
+
+	idToken, err := p.Exchange(testCode, verifier)
+	if err != nil {
+		t.Fatalf("换取ID token失败: %v", err)
+	}
+	if idToken != idp.idToken {
+		t.Fatalf("id_token = %q, want %q", idToken, idp.idToken)
+	}
+
+	if _, err := p.Exchange(testCode, verifier+"x"); err == nil {
+		t.Fatal("code_verifier不匹配时应返回错误")
+	}
+	if _, err := p.Exchange("other-code", verifier); err == nil {
+		t.Fatal("授权码无效时应返回错误")
+	}
+}
+
+func TestGeneratePKCE(t *testing.T) {
+	verifier, challenge, err := GeneratePKCE()
+	if err != nil {
+		t.Fatalf("生成PKCE失败: %v", err)
+	}
+	// RFC 7636 要求code_verifier长度为43-128个字符
+	if len(verifier) < 43 || len(verifier) > 128 {
+		t.Fatalf("code_verifier长度 = %d", len(verifier))
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	if challenge != base64.RawURLEncoding.EncodeToString(sum[:]) {
+		t.Fatal("code_challenge不是code_verifier的S256摘要")
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	idp := newTestIdP(t)
+	p := idp.provider(t)
+
+	claims, err := p.VerifyIDToken(idp.sign(t, idp.claims()), testNonce)
+	if err != nil {
+		t.Fatalf("验证有效的ID token失败: %v", err)
+	}
+	if claims.Subject() != "user-1" {
+		t.Fatalf("sub = %q, want %q", claims.Subject(), "user-1")
+	}
+}
+
+func TestVerifyIDTokenRejects(t *testing.T) {
+	idp := newTestIdP(t)
+	p := idp.provider(t)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("生成RSA密钥失败: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token func() string
+		nonce string
+	}{
+		{
+			name:  "nonce不匹配",
+			token: func() string { return idp.sign(t, idp.claims()) },
+			nonce: "other-nonce",
+		},
+		{
+			name:  "nonce为空",
+			token: func() string { return idp.sign(t, idp.claims()) },
+			nonce: "",
+		},
+		{
+			name: "iss不匹配",
+			token: func() string {
+				claims := idp.claims()
+				claims["iss"] = "https://attacker.example.com"
+				return idp.sign(t, claims)
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "aud不匹配",
+			token: func() string {
+				claims := idp.claims()
+				claims["aud"] = "other-client"
+				return idp.sign(t, claims)
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "多个aud且azp不匹配",
+			token: func() string {
+				claims := idp.claims()
+				claims["aud"] = []string{testClientID, "other-client"}
+				claims["azp"] = "other-client"
+				return idp.sign(t, claims)
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "已过期",
+			token: func() string {
+				claims := idp.claims()
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+				return idp.sign(t, claims)
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "缺少exp",
+			token: func() string {
+				claims := idp.claims()
+				delete(claims, "exp")
+				return idp.sign(t, claims)
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "缺少sub",
+			token: func() string {
+				claims := idp.claims()
+				delete(claims, "sub")
+				return idp.sign(t, claims)
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "alg为none",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodNone, idp.claims())
+				token.Header["kid"] = testKid
+				signed, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+				if err != nil {
+					t.Fatalf("签发token失败: %v", err)
+				}
+				return signed
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "alg为HS256",
+			token: func() string {
+				// 以公开的RSA公钥作为HMAC密钥伪造签名
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, idp.claims())
+				token.Header["kid"] = testKid
+				signed, err := token.SignedString(idp.key.PublicKey.N.Bytes())
+				if err != nil {
+					t.Fatalf("签发token失败: %v", err)
+				}
+				return signed
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "签名密钥不匹配",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.claims())
+				token.Header["kid"] = testKid
+				signed, err := token.SignedString(otherKey)
+				if err != nil {
+					t.Fatalf("签发token失败: %v", err)
+				}
+				return signed
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "未知的kid",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.claims())
+				token.Header["kid"] = "unknown"
+				signed, err := token.SignedString(idp.key)
+				if err != nil {
+					t.Fatalf("签发token失败: %v", err)
+				}
+				return signed
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "签名被篡改",
+			token: func() string {
+				signed := idp.sign(t, idp.claims())
+				parts := strings.Split(signed, ".")
+				payload, _ := json.Marshal(map[string]interface{}{
+					"iss": idp.server.URL, "sub": "admin", "aud": testClientID, "nonce": testNonce,
+					"iat": time.Now().Unix(), "exp": time.Now().Add(time.Minute).Unix(),
+				})
+				parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+				return strings.Join(parts, ".")
+			},
+			nonce: testNonce,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := p.VerifyIDToken(tt.token(), tt.nonce); err == nil {
+				t.Fatal("应拒绝该ID token")
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	kpjwt "github.com/cuiyuanxin/kunpeng/pkg/jwt"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksRefreshInterval 遇到未知kid时重新获取JWKS的最小间隔，避免伪造的token触发频繁请求
+const jwksRefreshInterval = time.Minute
+
+// validMethods ID token允许的签名算法，不接受none和HMAC
+var validMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Claims ID token中的声明
+type Claims map[string]interface{}
+
+// Subject 获取身份提供方的用户唯一标识
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+// String 获取字符串类型的声明，不存在或类型不符时返回空字符串
+func (c Claims) String(name string) string {
+	if name == "" {
+		return ""
+	}
+	s, _ := c[name].(string)
+	return s
+}
+
+// Bool 获取布尔类型的声明，兼容以字符串表示的布尔值
+func (c Claims) Bool(name string) bool {
+	switch v := c[name].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	default:
+		return false
+	}
+}
+
+// VerifyIDToken 验证ID token的签名、issuer、audience、有效期及nonce，返回其中的声明
+func (p *Provider) VerifyIDToken(rawIDToken, nonce string) (Claims, error) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(validMethods),
+		jwt.WithIssuer(p.discovery.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(p.cfg.ClockSkew),
+	)
+
+	claims := jwt.MapClaims{}
+	if _, err := parser.ParseWithClaims(rawIDToken, claims, p.keys.keyFunc); err != nil {
+		return nil, err
+	}
+
+	if nonce == "" || claims["nonce"] != nonce {
+		return nil, fmt.Errorf("ID token的nonce不匹配")
+	}
+	// 存在多个audience时azp必须为当前客户端
+	if aud, _ := claims.GetAudience(); len(aud) > 1 && claims["azp"] != p.cfg.ClientID {
+		return nil, fmt.Errorf("ID token的azp不匹配")
+	}
+	if sub, _ := claims.GetSubject(); sub == "" {
+		return nil, fmt.Errorf("ID token缺少sub")
+	}
+
+	return Claims(claims), nil
+}
+
+// keyCache 身份提供方的签名公钥缓存
+type keyCache struct {
+	provider  *Provider
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// newKeyCache 创建签名公钥缓存，首次验证时获取JWKS
+func newKeyCache(p *Provider) *keyCache {
+	return &keyCache{provider: p}
+}
+
+// keyFunc 根据token header中的kid选择验证公钥，身份提供方轮换密钥后自动重新获取JWKS
+func (c *keyCache) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.lookup(kid); ok {
+		return key, nil
+	}
+	if c.keys != nil && time.Since(c.fetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown kid: %q", kid)
+	}
+
+	if err := c.refresh(); err != nil {
+		return nil, err
+	}
+	if key, ok := c.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown kid: %q", kid)
+}
+
+// lookup 查找公钥，token未指定kid且只有一个公钥时使用该公钥
+func (c *keyCache) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key, true
+		}
+	}
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+// refresh 重新获取JWKS，忽略不支持的密钥及非签名用途的密钥
+func (c *keyCache) refresh() error {
+	var jwks kpjwt.JWKS
+	if err := c.provider.getJSON(c.provider.discovery.JWKSURI, &jwks); err != nil {
+		return err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := parseJWK(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	return nil
+}
+
+// parseJWK 解析RSA、EC及Ed25519公钥
+func parseJWK(jwk kpjwt.JWK) (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBase64URL(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBase64URL(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("无效的RSA公钥")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("不支持的椭圆曲线: %s", jwk.Crv)
+		}
+		x, err := decodeBase64URL(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBase64URL(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("无效的EC公钥")
+		}
+		return key, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("不支持的曲线: %s", jwk.Crv)
+		}
+		x, err := decodeBase64URL(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("无效的Ed25519公钥")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("不支持的密钥类型: %s", jwk.Kty)
+	}
+}
+
+// decodeBase64URL 解码无填充的Base64URL
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,19 @@
+package password
+
+// commonPasswords 内置常见弱密码，均为小写，校验时不区分大小写
+var commonPasswords = []string{
+	"123456", "1234567", "12345678", "123456789", "1234567890",
+	"111111", "000000", "666666", "888888", "654321", "123123", "112233",
+	"password", "password1", "password123", "passw0rd", "p@ssw0rd", "p@ssword",
+	"p@ssw0rd1", "p@ssw0rd123", "p@ssw0rd!", "password!", "password@123",
+	"qwerty", "qwerty123", "qwe123", "qwe123!@#", "qwer1234", "qwer@1234",
+	"1qaz2wsx", "1qaz@wsx", "1qaz!qaz", "1q2w3e4r", "1q2w3e4r5t", "zaq12wsx",
+	"abc123", "abc@123", "abc123!@#", "abcd1234", "abcd@1234", "aa123456",
+	"a123456", "a123456789", "a1b2c3d4", "abc123456", "aa123456!",
+	"admin", "admin123", "admin@123", "admin123!", "admin@1234", "admin!@#",
+	"administrator", "root", "root123", "root@123", "test", "test123", "test@123",
+	"welcome", "welcome1", "welcome123", "welcome@123", "iloveyou", "sunshine",
+	"letmein", "monkey", "dragon", "football", "baseball", "master", "superman",
+	"changeme", "default", "secret", "login", "guest", "user", "user123",
+	"huawei@123", "kunpeng", "kunpeng123", "kunpeng@123",
+}
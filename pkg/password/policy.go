@@ -0,0 +1,227 @@
+package password
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+)
+
+// 字符类型
+const (
+	ClassLower   = "lower"   // 小写字母
+	ClassUpper   = "upper"   // 大写字母
+	ClassDigit   = "digit"   // 数字
+	ClassSpecial = "special" // 特殊字符
+)
+
+// 默认配置，未配置时与此前固定的密码规则一致
+const (
+	defaultMinLength   = 6
+	defaultMaxLength   = 25
+	defaultResetLength = 12
+)
+
+// defaultCharClasses 默认必须包含的字符类型
+var defaultCharClasses = []string{ClassLower, ClassUpper, ClassDigit, ClassSpecial}
+
+// classNames 字符类型名称
+var classNames = map[string]string{
+	ClassLower:   "小写字母",
+	ClassUpper:   "大写字母",
+	ClassDigit:   "数字",
+	ClassSpecial: "特殊字符",
+}
+
+// 生成一次性密码使用的字符，去除了容易混淆的字符
+const (
+	lowerChars   = "abcdefghijkmnpqrstuvwxyz"
+	upperChars   = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	digitChars   = "23456789"
+	specialChars = "~!@#$%^&_-"
+)
+
+// Policy 密码策略
+type Policy struct {
+	MinLength    int
+	MaxLength    int
+	CharClasses  []string
+	Banned       map[string]bool
+	HistoryCount int
+	MaxAge       time.Duration
+	ResetLength  int
+}
+
+// GetPolicy 获取密码策略，未配置的项使用默认值
+func GetPolicy() *Policy {
+	cfg := config.GetPasswordPolicyConfig()
+
+	policy := &Policy{
+		MinLength:    cfg.MinLength,
+		MaxLength:    cfg.MaxLength,
+		HistoryCount: cfg.HistoryCount,
+		MaxAge:       cfg.MaxAge,
+		ResetLength:  cfg.ResetLength,
+		Banned:       make(map[string]bool, len(commonPasswords)+len(cfg.BannedPasswords)),
+	}
+	if policy.MinLength <= 0 {
+		policy.MinLength = defaultMinLength
+	}
+	if policy.MaxLength <= 0 {
+		policy.MaxLength = defaultMaxLength
+	}
+	if policy.MaxLength < policy.MinLength {
+		policy.MaxLength = policy.MinLength
+	}
+	if policy.HistoryCount < 0 {
+		policy.HistoryCount = 0
+	}
+
+	for _, class := range cfg.CharClasses {
+		class = strings.ToLower(strings.TrimSpace(class))
+		if _, ok := classNames[class]; ok {
+			policy.CharClasses = append(policy.CharClasses, class)
+		}
+	}
+	if len(cfg.CharClasses) == 0 {
+		policy.CharClasses = defaultCharClasses
+	}
+
+	// 一次性密码至少要满足长度要求并包含每种字符类型
+	if policy.ResetLength <= 0 {
+		policy.ResetLength = defaultResetLength
+	}
+	policy.ResetLength = max(policy.ResetLength, policy.MinLength, len(policy.CharClasses))
+	policy.ResetLength = min(policy.ResetLength, max(policy.MaxLength, len(policy.CharClasses)))
+
+	for _, p := range commonPasswords {
+		policy.Banned[p] = true
+	}
+	for _, p := range cfg.BannedPasswords {
+		policy.Banned[strings.ToLower(p)] = true
+	}
+
+	return policy
+}
+
+// Validate 按密码策略校验密码，username不为空时同时检查密码是否包含用户名
+func Validate(password string, username string) error {
+	return GetPolicy().Validate(password, username)
+}
+
+// Validate 按密码策略校验密码
+func (p *Policy) Validate(password string, username string) error {
+	length := len([]rune(password))
+	if length < p.MinLength || length > p.MaxLength {
+		return policyError(fmt.Sprintf("密码长度必须为%d-%d位", p.MinLength, p.MaxLength))
+	}
+
+	for _, r := range password {
+		if !unicode.IsPrint(r) || unicode.IsSpace(r) {
+			return policyError("密码不能包含空白或不可见字符")
+		}
+	}
+
+	classes := charClasses(password)
+	var missing []string
+	for _, class := range p.CharClasses {
+		if !classes[class] {
+			missing = append(missing, classNames[class])
+		}
+	}
+	if len(missing) > 0 {
+		return policyError("密码必须包含" + strings.Join(missing, "、"))
+	}
+
+	lower := strings.ToLower(password)
+	if p.Banned[lower] {
+		return policyError("密码过于常见，请更换")
+	}
+	if username != "" && strings.Contains(lower, strings.ToLower(username)) {
+		return policyError("密码不能包含用户名")
+	}
+
+	return nil
+}
+
+// IsExpired 判断密码是否已超过有效期
+func (p *Policy) IsExpired(changedAt time.Time) bool {
+	return p.MaxAge > 0 && time.Since(changedAt) > p.MaxAge
+}
+
+// Generate 生成满足密码策略的随机一次性密码
+func (p *Policy) Generate() (string, error) {
+	pools := map[string]string{
+		ClassLower:   lowerChars,
+		ClassUpper:   upperChars,
+		ClassDigit:   digitChars,
+		ClassSpecial: specialChars,
+	}
+
+	// 每种必须的字符类型至少包含一个，其余从全部字符中随机选取
+	chars := make([]byte, 0, p.ResetLength)
+	for _, class := range p.CharClasses {
+		c, err := randomChar(pools[class])
+		if err != nil {
+			return "", err
+		}
+		chars = append(chars, c)
+	}
+	all := lowerChars + upperChars + digitChars + specialChars
+	for len(chars) < p.ResetLength {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		chars = append(chars, c)
+	}
+
+	// 打乱顺序
+	for i := len(chars) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		j := int(n.Int64())
+		chars[i], chars[j] = chars[j], chars[i]
+	}
+
+	return string(chars), nil
+}
+
+// charClasses 统计密码包含的字符类型
+func charClasses(password string) map[string]bool {
+	classes := make(map[string]bool, len(classNames))
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			classes[ClassLower] = true
+		case unicode.IsUpper(r):
+			classes[ClassUpper] = true
+		case unicode.IsDigit(r):
+			classes[ClassDigit] = true
+		case unicode.IsPrint(r) && !unicode.IsLetter(r) && !unicode.IsSpace(r):
+			classes[ClassSpecial] = true
+		}
+	}
+	return classes
+}
+
+// randomChar 从字符集中随机选取一个字符
+func randomChar(chars string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+	if err != nil {
+		return 0, err
+	}
+	return chars[n.Int64()], nil
+}
+
+// policyError 创建密码策略错误
+func policyError(message string) error {
+	return kperrors.New(kperrors.ErrUserPasswordPolicy, nil).WithMessage(message)
+}
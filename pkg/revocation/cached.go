@@ -0,0 +1,224 @@
+package revocation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	"github.com/cuiyuanxin/kunpeng/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// 默认配置
+const (
+	defaultCacheSize       = 10000
+	defaultSyncInterval    = 5 * time.Second
+	defaultRebuildInterval = time.Hour
+	defaultBloomCapacity   = 100000
+	defaultBloomFPRate     = 0.001
+)
+
+// CachedStore 数据库撤销存储，进程内使用布隆过滤器和LRU缓存加速查询
+// 布隆过滤器判定不存在的key直接返回未撤销，只有可能存在的key才查询数据库，查询结果缓存在LRU中
+// 其他实例新增的撤销记录按同步间隔增量加载，布隆过滤器按重建间隔重建以移除已过期的key
+type CachedStore struct {
+	source          Source
+	cacheSize       int
+	syncInterval    time.Duration
+	rebuildInterval time.Duration
+	bloomCapacity   int
+	bloomFPRate     float64
+
+	mu          sync.Mutex
+	bloom       *bloomFilter
+	cache       *lruCache
+	lastSync    time.Time
+	lastRebuild time.Time
+	rebuilding  bool
+	pending     []string // 重建期间新增的key，重建完成后加入新的布隆过滤器
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewCachedStore 创建数据库撤销存储，加载全部未过期的撤销记录并开始定时同步
+func NewCachedStore(source Source, cfg config.TokenRevocationConfig) (*CachedStore, error) {
+	s := &CachedStore{
+		source:          source,
+		cacheSize:       cfg.CacheSize,
+		syncInterval:    cfg.SyncInterval,
+		rebuildInterval: cfg.RebuildInterval,
+		bloomCapacity:   cfg.BloomCapacity,
+		bloomFPRate:     cfg.BloomFPRate,
+		stop:            make(chan struct{}),
+	}
+	if s.cacheSize <= 0 {
+		s.cacheSize = defaultCacheSize
+	}
+	if s.syncInterval <= 0 {
+		s.syncInterval = defaultSyncInterval
+	}
+	if s.rebuildInterval <= 0 {
+		s.rebuildInterval = defaultRebuildInterval
+	}
+	if s.bloomCapacity <= 0 {
+		s.bloomCapacity = defaultBloomCapacity
+	}
+	if s.bloomFPRate <= 0 || s.bloomFPRate >= 1 {
+		s.bloomFPRate = defaultBloomFPRate
+	}
+
+	// LRU缓存有效期与同步间隔一致，其他实例删除撤销记录后最长在该时间后生效
+	s.cache = newLRUCache(s.cacheSize, s.syncInterval)
+	s.bloom = newBloomFilter(s.bloomCapacity, s.bloomFPRate)
+
+	if err := s.rebuild(); err != nil {
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	go s.loop()
+
+	return s, nil
+}
+
+// Add 记录已撤销的key，撤销记录需已写入数据源
+func (s *CachedStore) Add(key string, _ time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bloom.add(key)
+	s.cache.set(key, true)
+	if s.rebuilding {
+		s.pending = append(s.pending, key)
+	}
+	return nil
+}
+
+// Remove 移除撤销记录，撤销记录需已从数据源删除
+func (s *CachedStore) Remove(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cache.set(key, false)
+	return nil
+}
+
+// Contains 判断key是否已被撤销
+func (s *CachedStore) Contains(key string) (bool, error) {
+	s.mu.Lock()
+	if revoked, ok := s.cache.get(key); ok {
+		s.mu.Unlock()
+		return revoked, nil
+	}
+	if !s.bloom.mayContain(key) {
+		s.mu.Unlock()
+		return false, nil
+	}
+	s.mu.Unlock()
+
+	revoked, err := s.source.ExistsKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	s.cache.set(key, revoked)
+	s.mu.Unlock()
+	return revoked, nil
+}
+
+// Close 停止定时同步
+func (s *CachedStore) Close() error {
+	select {
+	case <-s.stop:
+		return nil
+	default:
+		close(s.stop)
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// loop 定时增量同步及重建布隆过滤器
+func (s *CachedStore) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			var err error
+			if time.Since(s.lastRebuild) >= s.rebuildInterval {
+				err = s.rebuild()
+			} else {
+				err = s.sync()
+			}
+			if err != nil {
+				logger.GetLogger().Error("同步token撤销记录失败", zap.Error(err))
+			}
+		}
+	}
+}
+
+// sync 加载上次同步之后其他实例新增的撤销记录
+func (s *CachedStore) sync() error {
+	start := time.Now()
+
+	// 多查询一个同步间隔，避免各实例时钟偏差导致遗漏
+	keys, err := s.source.FindActiveKeys(s.lastSync.Add(-s.syncInterval))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		s.bloom.add(key)
+		s.cache.remove(key)
+	}
+	s.lastSync = start
+	return nil
+}
+
+// rebuild 按全部未过期的撤销记录重建布隆过滤器，容量不足时按记录数的2倍扩容
+func (s *CachedStore) rebuild() error {
+	start := time.Now()
+
+	s.mu.Lock()
+	s.rebuilding = true
+	s.pending = nil
+	s.mu.Unlock()
+
+	keys, err := s.source.FindActiveKeys(time.Time{})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rebuilding = false
+	if err != nil {
+		s.pending = nil
+		return err
+	}
+
+	capacity := s.bloomCapacity
+	if len(keys)*2 > capacity {
+		capacity = len(keys) * 2
+	}
+	bloom := newBloomFilter(capacity, s.bloomFPRate)
+	for _, key := range keys {
+		bloom.add(key)
+	}
+	for _, key := range s.pending {
+		bloom.add(key)
+	}
+
+	s.bloom = bloom
+	s.pending = nil
+	s.lastSync = start
+	s.lastRebuild = start
+	return nil
+}
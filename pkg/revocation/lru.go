@@ -0,0 +1,76 @@
+package revocation
+
+import (
+	"container/list"
+	"time"
+)
+
+// lruEntry LRU缓存条目
+type lruEntry struct {
+	key      string
+	revoked  bool
+	expireAt time.Time
+}
+
+// lruCache 带有效期的LRU缓存，非并发安全，由调用方加锁
+type lruCache struct {
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+// newLRUCache 创建LRU缓存
+func newLRUCache(size int, ttl time.Duration) *lruCache {
+	return &lruCache{
+		size:  size,
+		ttl:   ttl,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// get 获取缓存的查询结果，不存在或已过期时返回false
+func (c *lruCache) get(key string) (bool, bool) {
+	elem, ok := c.items[key]
+	if !ok {
+		return false, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if time.Now().After(entry.expireAt) {
+		c.ll.Remove(elem)
+		delete(c.items, key)
+		return false, false
+	}
+
+	c.ll.MoveToFront(elem)
+	return entry.revoked, true
+}
+
+// set 缓存查询结果，超出容量时淘汰最久未使用的条目
+func (c *lruCache) set(key string, revoked bool) {
+	expireAt := time.Now().Add(c.ttl)
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.revoked = revoked
+		entry.expireAt = expireAt
+		c.ll.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, revoked: revoked, expireAt: expireAt})
+	for c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// remove 删除缓存
+func (c *lruCache) remove(key string) {
+	if elem, ok := c.items[key]; ok {
+		c.ll.Remove(elem)
+		delete(c.items, key)
+	}
+}
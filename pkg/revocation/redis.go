@@ -0,0 +1,125 @@
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis默认配置
+const (
+	defaultRedisPrefix      = "kunpeng:revoked:"
+	defaultRedisAddr        = "127.0.0.1:6379"
+	defaultRedisDialTimeout = 5 * time.Second
+)
+
+// RedisClient Redis兼容客户端接口，也可对接KeyDB、Dragonfly等兼容Redis协议的存储
+// 默认使用按 token_revocation 配置连接的go-redis客户端，需要其他客户端时在 Init 之前通过 SetRedisClient 注册
+type RedisClient interface {
+	// Set 设置key，ttl后自动过期
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+
+	// Exists 判断key是否存在
+	Exists(ctx context.Context, key string) (bool, error)
+
+	// Del 删除key
+	Del(ctx context.Context, key string) error
+
+	// Close 关闭连接
+	Close() error
+}
+
+// RedisStore Redis兼容的撤销存储，撤销记录按token过期时间自动过期，多实例共享
+type RedisStore struct {
+	client RedisClient
+	prefix string
+}
+
+// NewRedisStore 创建Redis兼容的撤销存储
+func NewRedisStore(client RedisClient, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = defaultRedisPrefix
+	}
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Add 记录已撤销的key，token已过期时无需记录
+func (s *RedisStore) Add(key string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(context.Background(), s.prefix+key, "1", ttl)
+}
+
+// Remove 移除撤销记录
+func (s *RedisStore) Remove(key string) error {
+	return s.client.Del(context.Background(), s.prefix+key)
+}
+
+// Contains 判断key是否已被撤销
+func (s *RedisStore) Contains(key string) (bool, error) {
+	return s.client.Exists(context.Background(), s.prefix+key)
+}
+
+// Close 关闭连接
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// goRedisClient 基于go-redis的Redis客户端
+type goRedisClient struct {
+	client *redis.Client
+}
+
+// newGoRedisClient 按配置连接Redis，连接失败时返回错误
+func newGoRedisClient(cfg config.TokenRevocationConfig) (*goRedisClient, error) {
+	addr := cfg.RedisAddr
+	if addr == "" {
+		addr = defaultRedisAddr
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:        addr,
+		Password:    cfg.RedisPassword,
+		DB:          cfg.RedisDB,
+		DialTimeout: defaultRedisDialTimeout,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRedisDialTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("连接Redis失败: %w", err)
+	}
+	return &goRedisClient{client: client}, nil
+}
+
+// Set 设置key，ttl后自动过期
+func (c *goRedisClient) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Exists 判断key是否存在
+func (c *goRedisClient) Exists(ctx context.Context, key string) (bool, error) {
+	n, err := c.client.Exists(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Del 删除key
+func (c *goRedisClient) Del(ctx context.Context, key string) error {
+	return c.client.Del(ctx, key).Err()
+}
+
+// Close 关闭连接
+func (c *goRedisClient) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,114 @@
+package revocation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+)
+
+// 撤销存储类型
+const (
+	StoreDB    = "db"    // 数据库存储，进程内布隆过滤器和LRU缓存加速查询
+	StoreRedis = "redis" // Redis兼容存储，适用于多实例部署
+)
+
+// key前缀
+const (
+	tokenKeyPrefix  = "tok:"
+	familyKeyPrefix = "fid:"
+)
+
+// Store token撤销存储接口
+type Store interface {
+	// Add 记录已撤销的key，expiresAt之后token自然过期，记录可被清理
+	Add(key string, expiresAt time.Time) error
+
+	// Remove 移除撤销记录，用于管理员删除黑名单记录
+	Remove(key string) error
+
+	// Contains 判断key是否已被撤销
+	Contains(key string) (bool, error)
+
+	// Close 释放资源
+	Close() error
+}
+
+// Source 撤销记录的持久化数据源，数据库存储通过它查询及同步撤销记录
+type Source interface {
+	// ExistsKey 判断未过期的撤销记录是否存在
+	ExistsKey(key string) (bool, error)
+
+	// FindActiveKeys 查询since之后新增或更新且未过期的撤销记录的key，since为零值时查询全部
+	FindActiveKeys(since time.Time) ([]string, error)
+}
+
+var (
+	store       Store
+	storeMu     sync.RWMutex
+	redisClient RedisClient
+)
+
+// SetRedisClient 注册Redis兼容客户端，token_revocation.store 为 redis 时代替默认的go-redis客户端，需在 Init 之前调用
+func SetRedisClient(client RedisClient) {
+	redisClient = client
+}
+
+// Init 根据配置创建撤销存储
+func Init(source Source) error {
+	cfg := config.GetTokenRevocationConfig()
+
+	var s Store
+	var err error
+	switch cfg.Store {
+	case "", StoreDB:
+		s, err = NewCachedStore(source, cfg)
+	case StoreRedis:
+		client := redisClient
+		if client == nil {
+			client, err = newGoRedisClient(cfg)
+			if err != nil {
+				return err
+			}
+		}
+		s = NewRedisStore(client, cfg.RedisPrefix)
+	default:
+		return fmt.Errorf("不支持的token撤销存储: %s", cfg.Store)
+	}
+	if err != nil {
+		return err
+	}
+
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+	return nil
+}
+
+// GetStore 获取撤销存储，未初始化时返回nil
+func GetStore() Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return store
+}
+
+// Close 关闭撤销存储
+func Close() {
+	if s := GetStore(); s != nil {
+		_ = s.Close()
+	}
+}
+
+// TokenKey 单个token的撤销key，使用token的SHA-256哈希，不保存token原文
+func TokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return tokenKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+// FamilyKey token族的撤销key
+func FamilyKey(familyID string) string {
+	return familyKeyPrefix + familyID
+}
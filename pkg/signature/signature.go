@@ -0,0 +1,88 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// 签名请求头
+const (
+	HeaderAppKey    = "X-App-Key"   // 用户的AppKey
+	HeaderTimestamp = "X-Timestamp" // 请求时间，Unix时间戳（秒）
+	HeaderNonce     = "X-Nonce"     // 随机串，同一AppKey在时钟偏差范围内不能重复
+	HeaderSignature = "X-Signature" // 十六进制编码的HMAC-SHA256签名
+)
+
+// Request 参与签名的请求内容
+type Request struct {
+	Method    string // 请求方法，大写
+	Path      string // 请求路径，不含查询参数
+	Query     string // 规范化的查询参数，见 CanonicalQuery
+	AppKey    string
+	Timestamp string
+	Nonce     string
+	BodyHash  string // 请求体的SHA256，十六进制小写，见 HashBody
+}
+
+// HashBody 计算请求体的SHA256，请求体为空时同样参与计算
+func HashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// CanonicalQuery 规范化查询参数，按参数名排序，同名参数按值排序，参数名及值按URL编码
+func CanonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		vals := append([]string(nil), values[key]...)
+		sort.Strings(vals)
+		for _, val := range vals {
+			if sb.Len() > 0 {
+				sb.WriteByte('&')
+			}
+			sb.WriteString(url.QueryEscape(key))
+			sb.WriteByte('=')
+			sb.WriteString(url.QueryEscape(val))
+		}
+	}
+	return sb.String()
+}
+
+// StringToSign 待签名字符串，各部分以换行符连接
+func (r *Request) StringToSign() string {
+	return strings.Join([]string{
+		strings.ToUpper(r.Method),
+		r.Path,
+		r.Query,
+		r.AppKey,
+		r.Timestamp,
+		r.Nonce,
+		r.BodyHash,
+	}, "\n")
+}
+
+// Sign 使用AppSecret计算签名，返回十六进制小写字符串
+func Sign(secret string, r *Request) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(r.StringToSign()))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Verify 校验签名，签名不区分大小写，使用常量时间比较
+func Verify(secret string, r *Request, signature string) bool {
+	if secret == "" {
+		return false
+	}
+	expected := Sign(secret, r)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
@@ -0,0 +1,91 @@
+package sms
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cuiyuanxin/kunpeng/pkg/config"
+	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// 短信发送驱动
+const (
+	DriverLog = "log" // 仅输出到日志，适用于开发环境
+)
+
+// CodeLength 短信验证码长度
+const CodeLength = 6
+
+// SMSSender 短信发送接口，可基于阿里云、腾讯云等短信平台实现
+// 自定义实现需在服务启动时通过 Register 注册，并将 sms.driver 配置为注册名称
+type SMSSender interface {
+	// SendCode 发送验证码短信
+	SendCode(mobile string, code string, ttl time.Duration) error
+}
+
+var (
+	senders = map[string]SMSSender{
+		DriverLog: &LogSender{},
+	}
+	sendersMu sync.RWMutex
+)
+
+// Register 注册短信发送实现
+func Register(driver string, sender SMSSender) {
+	sendersMu.Lock()
+	defer sendersMu.Unlock()
+	senders[driver] = sender
+}
+
+// GetSender 获取当前配置的短信发送实现
+func GetSender() (SMSSender, error) {
+	driver := config.GetSMSConfig().Driver
+	if driver == "" {
+		driver = DriverLog
+	}
+
+	sendersMu.RLock()
+	defer sendersMu.RUnlock()
+	sender, ok := senders[driver]
+	if !ok {
+		return nil, kperrors.New(kperrors.ErrSystem, nil).WithMessage(fmt.Sprintf("不支持的短信发送驱动: %s", driver))
+	}
+	return sender, nil
+}
+
+// GenerateCode 生成数字短信验证码
+func GenerateCode() (string, error) {
+	var sb strings.Builder
+	for i := 0; i < CodeLength; i++ {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", kperrors.New(kperrors.ErrSystem, err)
+		}
+		sb.WriteString(n.String())
+	}
+	return sb.String(), nil
+}
+
+// LogSender 将验证码输出到日志的短信发送实现，禁止在生产环境使用
+type LogSender struct{}
+
+// SendCode 将验证码输出到日志
+func (s *LogSender) SendCode(mobile string, code string, ttl time.Duration) error {
+	// 验证码明文会写入日志，生产环境禁止使用
+	if config.IsProduction() {
+		return kperrors.New(kperrors.ErrSystem, nil).WithMessage("生产环境不允许使用日志短信驱动")
+	}
+
+	logger.GetLogger().Info("短信验证码",
+		zap.String("mobile", mobile),
+		zap.String("code", code),
+		zap.Duration("ttl", ttl),
+	)
+	return nil
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// UserAgent User-Agent解析结果
+type UserAgent struct {
+	Browser string // 浏览器及主版本号
+	OS      string // 操作系统
+	Device  string // 设备类型 Desktop, Mobile, Tablet, Bot
+}
+
+// uaRule 按顺序匹配的User-Agent规则，先匹配到的生效
+type uaRule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// browserRules 浏览器规则，基于Chromium的浏览器需排在Chrome之前，Chrome需排在Safari之前
+var browserRules = []uaRule{
+	{"Edge", regexp.MustCompile(`Edg(?:e|A|iOS)?/(\d+)`)},
+	{"Opera", regexp.MustCompile(`(?:OPR|Opera)/(\d+)`)},
+	{"WeChat", regexp.MustCompile(`MicroMessenger/(\d+)`)},
+	{"QQBrowser", regexp.MustCompile(`QQBrowser/(\d+)`)},
+	{"UCBrowser", regexp.MustCompile(`UCBrowser/(\d+)`)},
+	{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/(\d+)`)},
+	{"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/(\d+)`)},
+	{"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/(\d+)`)},
+	{"Safari", regexp.MustCompile(`Version/(\d+).*Safari/`)},
+	{"IE", regexp.MustCompile(`(?:MSIE |Trident/.*rv:)(\d+)`)},
+}
+
+// osRules 操作系统规则，移动系统需排在桌面系统之前
+var osRules = []uaRule{
+	{"Android", regexp.MustCompile(`Android`)},
+	{"iOS", regexp.MustCompile(`iPhone|iPad|iPod`)},
+	{"HarmonyOS", regexp.MustCompile(`HarmonyOS|OpenHarmony`)},
+	{"Windows", regexp.MustCompile(`Windows`)},
+	{"macOS", regexp.MustCompile(`Macintosh|Mac OS X`)},
+	{"Chrome OS", regexp.MustCompile(`CrOS`)},
+	{"Linux", regexp.MustCompile(`Linux`)},
+}
+
+// botPattern 爬虫及命令行工具
+var botPattern = regexp.MustCompile(`(?i)bot|spider|crawler|curl|wget|python|go-http-client|postman`)
+
+// ParseUserAgent 简单解析User-Agent，仅识别常见浏览器、操作系统和设备类型，无法识别时返回Unknown
+func ParseUserAgent(ua string) UserAgent {
+	result := UserAgent{Browser: "Unknown", OS: "Unknown", Device: "Unknown"}
+	if ua == "" {
+		return result
+	}
+
+	for _, rule := range browserRules {
+		if m := rule.pattern.FindStringSubmatch(ua); m != nil {
+			result.Browser = rule.name + " " + m[1]
+			break
+		}
+	}
+
+	for _, rule := range osRules {
+		if rule.pattern.MatchString(ua) {
+			result.OS = rule.name
+			break
+		}
+	}
+
+	switch {
+	case botPattern.MatchString(ua):
+		result.Device = "Bot"
+	case strings.Contains(ua, "iPad") || strings.Contains(ua, "Tablet") ||
+		(strings.Contains(ua, "Android") && !strings.Contains(ua, "Mobile")):
+		result.Device = "Tablet"
+	case strings.Contains(ua, "Mobile") || strings.Contains(ua, "iPhone"):
+		result.Device = "Mobile"
+	default:
+		result.Device = "Desktop"
+	}
+
+	return result
+}
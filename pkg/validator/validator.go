@@ -8,6 +8,7 @@ import (
 
 	"github.com/cuiyuanxin/kunpeng/pkg/constants"
 	kperrors "github.com/cuiyuanxin/kunpeng/pkg/errors"
+	"github.com/cuiyuanxin/kunpeng/pkg/password"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/locales/en"
@@ -73,12 +74,9 @@ func validateUsername(fl validator.FieldLevel) bool {
 	return matched
 }
 
-// validatePassword 验证密码格式
+// validatePassword 验证密码是否符合密码策略（长度、字符类型及常见弱密码）
 func validatePassword(fl validator.FieldLevel) bool {
-	password := fl.Field().String()
-	// 验证密码格式：包含大小写字母、数字和特殊字符，长度6-25位
-	matched, _ := regexp.MatchString(constants.PasswordRegex, password)
-	return matched
+	return password.Validate(fl.Field().String(), "") == nil
 }
 
 // validateMobile 验证手机号格式
@@ -20,21 +20,27 @@ CREATE TABLE IF NOT EXISTS `kp_user` (
   `mobile` varchar(20) DEFAULT NULL COMMENT '手机号',
   `dept_id` bigint(20) UNSIGNED DEFAULT NULL COMMENT '部门ID',
   `post_id` bigint(20) UNSIGNED DEFAULT NULL COMMENT '岗位ID',
-  `role_id` bigint(20) UNSIGNED DEFAULT NULL COMMENT '角色ID',
   `status` tinyint(1) DEFAULT 1 COMMENT '状态(0:禁用 1:启用)',
+  `is_builtin` tinyint(1) DEFAULT 0 COMMENT '是否内置(内置用户不允许删除或禁用)',
   `login_ip` varchar(50) DEFAULT NULL COMMENT '最后登录IP',
   `login_time` datetime DEFAULT NULL COMMENT '最后登录时间',
   `app_key` varchar(50) DEFAULT NULL COMMENT 'AppKey',
   `app_secret` varchar(100) DEFAULT NULL COMMENT 'AppSecret',
+  `prev_app_secret` varchar(100) DEFAULT NULL COMMENT '轮换前的AppSecret，过渡期内仍可用于签名',
+  `prev_app_secret_until` datetime DEFAULT NULL COMMENT '轮换前的AppSecret的有效截止时间',
+  `app_secret_rotated_at` datetime DEFAULT NULL COMMENT 'AppSecret最近轮换时间',
+  `token_version` int(10) UNSIGNED NOT NULL DEFAULT 0 COMMENT 'token版本号，递增后此前签发的token全部失效',
+  `must_change_password` tinyint(1) NOT NULL DEFAULT 0 COMMENT '登录后必须修改密码(管理员创建用户或重置密码后为1)',
+  `password_changed_at` datetime DEFAULT NULL COMMENT '密码最近修改时间',
   `remark` varchar(255) DEFAULT NULL COMMENT '备注',
   `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   `deleted_at` datetime DEFAULT NULL COMMENT '删除时间',
   PRIMARY KEY (`id`),
   UNIQUE KEY `idx_username` (`username`),
+  KEY `idx_app_key` (`app_key`),
   KEY `idx_dept_id` (`dept_id`),
   KEY `idx_post_id` (`post_id`),
-  KEY `idx_role_id` (`role_id`),
   KEY `idx_status` (`status`),
   KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户表';
@@ -42,16 +48,21 @@ CREATE TABLE IF NOT EXISTS `kp_user` (
 -- 创建角色表
 CREATE TABLE IF NOT EXISTS `kp_role` (
   `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '角色ID',
+  `parent_id` bigint(20) UNSIGNED DEFAULT 0 COMMENT '上级角色ID',
   `name` varchar(50) NOT NULL COMMENT '角色名称',
   `code` varchar(50) NOT NULL COMMENT '角色编码',
   `sort` int(11) DEFAULT 0 COMMENT '排序',
+  `data_scope` tinyint(1) DEFAULT 1 COMMENT '数据权限范围(1:全部 2:自定义部门 3:本部门 4:本部门及以下 5:仅本人)',
   `status` tinyint(1) DEFAULT 1 COMMENT '状态(0:禁用 1:启用)',
+  `is_builtin` tinyint(1) DEFAULT 0 COMMENT '是否内置(内置角色不允许删除、禁用或修改编码)',
+  `require_two_factor` tinyint(1) DEFAULT 0 COMMENT '是否要求双因素认证',
   `remark` varchar(255) DEFAULT NULL COMMENT '备注',
   `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   `deleted_at` datetime DEFAULT NULL COMMENT '删除时间',
   PRIMARY KEY (`id`),
   UNIQUE KEY `idx_code` (`code`),
+  KEY `idx_parent_id` (`parent_id`),
   KEY `idx_status` (`status`),
   KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='角色表';
@@ -99,6 +110,7 @@ CREATE TABLE IF NOT EXISTS `kp_api` (
   `method` varchar(10) NOT NULL COMMENT '请求方法',
   `path` varchar(100) NOT NULL COMMENT '请求路径',
   `status` tinyint(1) DEFAULT 1 COMMENT '状态(0:禁用 1:启用)',
+  `stale` tinyint(1) DEFAULT 0 COMMENT '对应路由是否已不存在(0:否 1:是)',
   `remark` varchar(255) DEFAULT NULL COMMENT '备注',
   `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
@@ -120,6 +132,28 @@ CREATE TABLE IF NOT EXISTS `kp_role_api` (
   UNIQUE KEY `idx_role_api` (`role_id`,`api_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='角色API关联表';
 
+-- 创建用户角色关联表
+CREATE TABLE IF NOT EXISTS `kp_user_role` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` bigint(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `role_id` bigint(20) UNSIGNED NOT NULL COMMENT '角色ID',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_user_role` (`user_id`,`role_id`),
+  KEY `idx_role_id` (`role_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户角色关联表';
+
+-- 创建角色部门关联表
+CREATE TABLE IF NOT EXISTS `kp_role_dept` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `role_id` bigint(20) UNSIGNED NOT NULL COMMENT '角色ID',
+  `dept_id` bigint(20) UNSIGNED NOT NULL COMMENT '部门ID',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_role_dept` (`role_id`,`dept_id`),
+  KEY `idx_dept_id` (`dept_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='角色部门关联表';
+
 -- 创建部门表
 CREATE TABLE IF NOT EXISTS `kp_dept` (
   `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '部门ID',
@@ -207,21 +241,21 @@ CREATE TABLE IF NOT EXISTS `kp_login_log` (
 -- 创建登录尝试记录表
 CREATE TABLE IF NOT EXISTS `kp_login_attempt` (
   `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '记录ID',
-  `account` varchar(100) NOT NULL COMMENT '登录账号（用户名或手机号）',
-  `ip` varchar(50) NOT NULL COMMENT '登录IP',
-  `attempts` int(11) DEFAULT 1 COMMENT '失败次数',
-  `last_try` datetime NOT NULL COMMENT '最后尝试时间',
-  `blocked_at` datetime DEFAULT NULL COMMENT '拉黑时间',
+  `scope` varchar(20) NOT NULL COMMENT '计数范围 account:账号 ip:IP',
+  `subject` varchar(100) NOT NULL COMMENT '登录账号（用户名或手机号）或IP',
+  `attempts` int(11) NOT NULL DEFAULT 0 COMMENT '连续失败次数',
+  `lock_count` int(11) NOT NULL DEFAULT 0 COMMENT '连续锁定次数',
+  `last_try` datetime NOT NULL COMMENT '最后失败时间',
+  `last_account` varchar(100) DEFAULT NULL COMMENT '最后尝试的账号',
+  `last_ip` varchar(50) DEFAULT NULL COMMENT '最后尝试的IP',
+  `blocked_at` datetime DEFAULT NULL COMMENT '锁定时间',
+  `locked_until` datetime DEFAULT NULL COMMENT '锁定截止时间',
   `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
-  `deleted_at` datetime DEFAULT NULL COMMENT '删除时间',
   PRIMARY KEY (`id`),
-  KEY `idx_account` (`account`),
-  KEY `idx_ip` (`ip`),
-  KEY `idx_account_ip` (`account`, `ip`),
-  KEY `idx_blocked_at` (`blocked_at`),
-  KEY `idx_created_at` (`created_at`),
-  KEY `idx_deleted_at` (`deleted_at`)
+  UNIQUE KEY `idx_scope_subject` (`scope`, `subject`),
+  KEY `idx_last_try` (`last_try`),
+  KEY `idx_locked_until` (`locked_until`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='登录尝试记录表';
 
 -- 创建系统配置表
@@ -282,13 +316,17 @@ CREATE TABLE IF NOT EXISTS `kp_dict_data` (
 -- 插入初始数据
 
 -- 插入管理员用户
-INSERT INTO `kp_user` (`id`, `username`, `password`, `nickname`, `real_name`, `avatar`, `gender`, `email`, `mobile`, `dept_id`, `post_id`, `role_id`, `status`, `app_key`, `app_secret`, `remark`) VALUES
-(1, 'admin', '$2a$10$YEzOYVCz6jBhwgCHJEQXG.0/FROxhA/MxQYV0F1hUWvtQgV1CvZT.', '管理员', '系统管理员', NULL, 1, 'admin@example.com', '13800138000', 1, 1, 1, 1, 'admin', 'c5e330214fb33e2d485f207b33e4c92f', '系统管理员');
+INSERT INTO `kp_user` (`id`, `username`, `password`, `nickname`, `real_name`, `avatar`, `gender`, `email`, `mobile`, `dept_id`, `post_id`, `status`, `is_builtin`, `app_key`, `app_secret`, `must_change_password`, `password_changed_at`, `remark`) VALUES
+(1, 'admin', '$2a$10$YEzOYVCz6jBhwgCHJEQXG.0/FROxhA/MxQYV0F1hUWvtQgV1CvZT.', '管理员', '系统管理员', NULL, 1, 'admin@example.com', '13800138000', 1, 1, 1, 1, 'admin', LOWER(HEX(RANDOM_BYTES(16))), 1, NOW(), '系统管理员');
 
 -- 插入角色
-INSERT INTO `kp_role` (`id`, `name`, `code`, `sort`, `status`, `remark`) VALUES
-(1, '超级管理员', 'admin', 1, 1, '超级管理员'),
-(2, '普通用户', 'user', 2, 1, '普通用户');
+INSERT INTO `kp_role` (`id`, `name`, `code`, `sort`, `status`, `is_builtin`, `remark`) VALUES
+(1, '超级管理员', 'admin', 1, 1, 1, '超级管理员'),
+(2, '普通用户', 'user', 2, 1, 0, '普通用户');
+
+-- 插入用户角色关联
+INSERT INTO `kp_user_role` (`user_id`, `role_id`) VALUES
+(1, 1);
 
 -- 插入部门
 INSERT INTO `kp_dept` (`id`, `parent_id`, `name`, `leader`, `phone`, `email`, `sort`, `status`, `remark`) VALUES
@@ -318,11 +356,22 @@ INSERT INTO `kp_menu` (`id`, `parent_id`, `name`, `type`, `path`, `component`, `
 (8, 1, '参数设置', 1, 'config', 'system/config/index', 'system:config:list', 'edit', 7, 1, 1, 0, 0),
 (9, 1, '日志管理', 0, 'log', NULL, NULL, 'log', 8, 1, 1, 0, 0),
 (10, 9, '操作日志', 1, 'operlog', 'monitor/operlog/index', 'monitor:operlog:list', 'form', 1, 1, 1, 0, 0),
-(11, 9, '登录日志', 1, 'logininfor', 'monitor/logininfor/index', 'monitor:logininfor:list', 'logininfor', 2, 1, 1, 0, 0);
+(11, 9, '登录日志', 1, 'logininfor', 'monitor/logininfor/index', 'monitor:logininfor:list', 'logininfor', 2, 1, 1, 0, 0),
+(12, 2, '用户新增', 2, '', NULL, 'system:user:add', '#', 1, 1, 1, 0, 0),
+(13, 2, '用户修改', 2, '', NULL, 'system:user:edit', '#', 2, 1, 1, 0, 0),
+(14, 2, '用户删除', 2, '', NULL, 'system:user:remove', '#', 3, 1, 1, 0, 0),
+(15, 2, '重置密码', 2, '', NULL, 'system:user:resetPwd', '#', 4, 1, 1, 0, 0),
+(16, 2, '重置双因素认证', 2, '', NULL, 'system:user:reset2fa', '#', 5, 1, 1, 0, 0),
+(17, 2, '强制下线', 2, '', NULL, 'system:user:forceLogout', '#', 6, 1, 1, 0, 0),
+(18, 1, '定时任务', 1, 'job', 'monitor/job/index', 'monitor:job:list', 'job', 9, 1, 1, 0, 0),
+(19, 18, '任务执行', 2, '', NULL, 'monitor:job:run', '#', 1, 1, 1, 0, 0),
+(20, 18, '任务暂停恢复', 2, '', NULL, 'monitor:job:edit', '#', 2, 1, 1, 0, 0),
+(21, 2, '解除登录锁定', 2, '', NULL, 'system:user:unlock', '#', 7, 1, 1, 0, 0),
+(22, 2, '重置AppSecret', 2, '', NULL, 'system:user:resetSecret', '#', 8, 1, 1, 0, 0);
 
 -- 插入角色菜单关联
 INSERT INTO `kp_role_menu` (`role_id`, `menu_id`) VALUES
-(1, 1), (1, 2), (1, 3), (1, 4), (1, 5), (1, 6), (1, 7), (1, 8), (1, 9), (1, 10), (1, 11),
+(1, 1), (1, 2), (1, 3), (1, 4), (1, 5), (1, 6), (1, 7), (1, 8), (1, 9), (1, 10), (1, 11), (1, 12), (1, 13), (1, 14), (1, 15), (1, 16), (1, 17), (1, 18), (1, 19), (1, 20), (1, 21), (1, 22),
 (2, 1), (2, 2), (2, 3), (2, 4), (2, 5), (2, 6), (2, 7);
 
 -- 插入API
@@ -371,7 +420,8 @@ INSERT INTO `kp_dict_data` (`dict_type`, `label`, `value`, `sort`, `status`, `cs
 -- 创建token黑名单表
 CREATE TABLE IF NOT EXISTS `kp_token_blacklist` (
   `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
-  `token` text NOT NULL COMMENT 'JWT token',
+  `token_key` varchar(80) NOT NULL COMMENT '撤销key，token的SHA-256哈希或token族ID',
+  `family_id` varchar(64) DEFAULT NULL COMMENT '被撤销的token族ID',
   `user_id` bigint(20) UNSIGNED NOT NULL COMMENT '用户ID',
   `username` varchar(50) NOT NULL COMMENT '用户名',
   `reason` varchar(255) DEFAULT NULL COMMENT '加入黑名单原因',
@@ -380,12 +430,209 @@ CREATE TABLE IF NOT EXISTS `kp_token_blacklist` (
   `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   `deleted_at` datetime DEFAULT NULL COMMENT '删除时间',
   PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_token_key` (`token_key`),
   KEY `idx_user_id` (`user_id`),
+  KEY `idx_family_id` (`family_id`),
   KEY `idx_username` (`username`),
   KEY `idx_expires_at` (`expires_at`),
   KEY `idx_created_at` (`created_at`),
+  KEY `idx_updated_at` (`updated_at`),
   KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='token黑名单表';
 
+-- 创建短信验证码表
+CREATE TABLE IF NOT EXISTS `kp_sms_code` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `mobile` varchar(20) NOT NULL COMMENT '手机号',
+  `scene` varchar(20) NOT NULL COMMENT '使用场景',
+  `code_hash` varchar(64) NOT NULL COMMENT '验证码哈希',
+  `ip` varchar(50) NOT NULL COMMENT '请求IP',
+  `attempts` int(11) NOT NULL DEFAULT 0 COMMENT '校验失败次数',
+  `expires_at` datetime NOT NULL COMMENT '过期时间',
+  `used_at` datetime DEFAULT NULL COMMENT '使用时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_mobile_scene` (`mobile`, `scene`),
+  KEY `idx_mobile_created_at` (`mobile`, `created_at`),
+  KEY `idx_ip_created_at` (`ip`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='短信验证码表';
+
+-- 创建用户双因素认证表
+CREATE TABLE IF NOT EXISTS `kp_user_two_factor` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` bigint(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `secret` varchar(64) NOT NULL COMMENT 'TOTP密钥',
+  `enabled` tinyint(1) DEFAULT 0 COMMENT '是否已启用',
+  `last_step` bigint(20) NOT NULL DEFAULT 0 COMMENT '最近一次校验通过的时间步',
+  `enabled_at` datetime DEFAULT NULL COMMENT '启用时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户双因素认证表';
+
+-- 创建双因素认证恢复码表
+CREATE TABLE IF NOT EXISTS `kp_user_recovery_code` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` bigint(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `code_hash` varchar(64) NOT NULL COMMENT '恢复码哈希',
+  `used_at` datetime DEFAULT NULL COMMENT '使用时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id_code_hash` (`user_id`, `code_hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='双因素认证恢复码表';
+
+-- 创建refresh token签发记录表
+CREATE TABLE IF NOT EXISTS `kp_refresh_token` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `jti` varchar(64) NOT NULL COMMENT 'refresh token的jti',
+  `family_id` varchar(64) NOT NULL COMMENT 'token族ID',
+  `user_id` bigint(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `used_at` datetime DEFAULT NULL COMMENT '轮换时间',
+  `revoked_at` datetime DEFAULT NULL COMMENT '撤销时间',
+  `expires_at` datetime NOT NULL COMMENT '过期时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_jti` (`jti`),
+  KEY `idx_family_id` (`family_id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='refresh token签发记录表';
+
+-- 创建用户登录会话表
+CREATE TABLE IF NOT EXISTS `kp_user_session` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `session_id` varchar(64) NOT NULL COMMENT '会话ID，即token族ID',
+  `user_id` bigint(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `username` varchar(50) NOT NULL COMMENT '用户名',
+  `jti` varchar(64) NOT NULL COMMENT '最近签发的refresh token的jti',
+  `ip` varchar(50) DEFAULT NULL COMMENT '最近访问IP',
+  `browser` varchar(50) DEFAULT NULL COMMENT '浏览器',
+  `os` varchar(50) DEFAULT NULL COMMENT '操作系统',
+  `device` varchar(50) DEFAULT NULL COMMENT '设备类型',
+  `user_agent` varchar(500) DEFAULT NULL COMMENT '原始User-Agent',
+  `login_at` datetime NOT NULL COMMENT '登录时间',
+  `last_seen_at` datetime NOT NULL COMMENT '最近活跃时间',
+  `expires_at` datetime NOT NULL COMMENT '过期时间',
+  `revoked_at` datetime DEFAULT NULL COMMENT '撤销时间',
+  `revoke_reason` varchar(255) DEFAULT NULL COMMENT '撤销原因',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_session_id` (`session_id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户登录会话表';
+
+-- 创建定时任务表
+CREATE TABLE IF NOT EXISTS `kp_job` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `name` varchar(50) NOT NULL COMMENT '任务名称',
+  `status` tinyint(1) NOT NULL DEFAULT 1 COMMENT '状态 0:已暂停 1:正常',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='定时任务表';
+
+-- 创建定时任务执行记录表
+CREATE TABLE IF NOT EXISTS `kp_job_log` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `job_name` varchar(50) NOT NULL COMMENT '任务名称',
+  `trigger` varchar(20) NOT NULL COMMENT '触发方式 schedule:按计划 manual:手动',
+  `operator` varchar(50) DEFAULT NULL COMMENT '手动触发的操作人',
+  `status` tinyint(1) NOT NULL COMMENT '状态 0:失败 1:成功 2:执行中',
+  `message` varchar(500) DEFAULT NULL COMMENT '执行结果或错误信息',
+  `started_at` datetime NOT NULL COMMENT '开始时间',
+  `finished_at` datetime DEFAULT NULL COMMENT '结束时间',
+  `duration` bigint(20) DEFAULT 0 COMMENT '耗时（毫秒）',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_job_name` (`job_name`),
+  KEY `idx_started_at` (`started_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='定时任务执行记录表';
+
+-- 创建密码历史表
+CREATE TABLE IF NOT EXISTS `kp_password_history` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `user_id` bigint(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `password` varchar(100) NOT NULL COMMENT '密码哈希',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '设置时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='密码历史表';
+
+-- 创建找回密码请求记录表
+CREATE TABLE IF NOT EXISTS `kp_password_reset` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `account` varchar(100) NOT NULL COMMENT '请求时填写的用户名或邮箱',
+  `user_id` bigint(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '用户ID，账号不存在、未绑定邮箱或超出发送限制时为0',
+  `jti` varchar(64) DEFAULT NULL COMMENT '重置凭证的jti，未发送邮件时为空',
+  `ip` varchar(50) NOT NULL COMMENT '请求IP',
+  `expires_at` datetime NOT NULL COMMENT '过期时间',
+  `used_at` datetime DEFAULT NULL COMMENT '使用时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_account` (`account`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_jti` (`jti`),
+  KEY `idx_ip` (`ip`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='找回密码请求记录表';
+
+-- 创建单点登录授权请求记录表
+CREATE TABLE IF NOT EXISTS `kp_oidc_state` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `state` varchar(64) NOT NULL COMMENT '授权请求标识',
+  `nonce` varchar(64) NOT NULL COMMENT 'ID token中的nonce',
+  `code_verifier` varchar(128) NOT NULL COMMENT 'PKCE code_verifier',
+  `user_id` bigint(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '发起关联的用户ID，登录时为0',
+  `ip` varchar(50) NOT NULL COMMENT '请求IP',
+  `expires_at` datetime NOT NULL COMMENT '过期时间',
+  `used_at` datetime DEFAULT NULL COMMENT '使用时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_state` (`state`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='单点登录授权请求记录表';
+
+-- 创建用户外部身份表
+CREATE TABLE IF NOT EXISTS `kp_user_identity` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `user_id` bigint(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `issuer` varchar(255) NOT NULL COMMENT '身份提供方标识',
+  `subject` varchar(255) NOT NULL COMMENT '身份提供方的用户唯一标识',
+  `email` varchar(100) DEFAULT NULL COMMENT '身份提供方返回的邮箱',
+  `last_login_at` datetime DEFAULT NULL COMMENT '最近登录时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '关联时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_issuer_subject` (`issuer`, `subject`),
+  UNIQUE KEY `idx_user_issuer` (`user_id`, `issuer`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户外部身份表';
+
+-- 创建签名请求随机串表
+CREATE TABLE IF NOT EXISTS `kp_api_nonce` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `app_key` varchar(50) NOT NULL COMMENT 'AppKey',
+  `nonce` varchar(64) NOT NULL COMMENT '签名请求的随机串',
+  `expires_at` datetime NOT NULL COMMENT '过期时间，超过后请求时间戳已不再有效',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_app_key_nonce` (`app_key`, `nonce`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='签名请求随机串表';
+
+-- 创建Casbin策略版本表
+CREATE TABLE IF NOT EXISTS `kp_casbin_version` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `version` bigint(20) NOT NULL DEFAULT 0 COMMENT '策略版本号，策略变更时递增',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Casbin策略版本表';
+
+INSERT INTO `kp_casbin_version` (`id`, `version`) VALUES (1, 0);
+
 -- 设置外键检查
 SET FOREIGN_KEY_CHECKS = 1;
\ No newline at end of file
@@ -0,0 +1,39 @@
+-- 鲲鹏后台管理系统升级脚本：AppKey/AppSecret签名认证及AppSecret轮换
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 用户表增加轮换前的AppSecret及轮换时间，按AppKey查找用户
+ALTER TABLE `kp_user`
+  ADD COLUMN `prev_app_secret` varchar(100) DEFAULT NULL COMMENT '轮换前的AppSecret，过渡期内仍可用于签名' AFTER `app_secret`,
+  ADD COLUMN `prev_app_secret_until` datetime DEFAULT NULL COMMENT '轮换前的AppSecret的有效截止时间' AFTER `prev_app_secret`,
+  ADD COLUMN `app_secret_rotated_at` datetime DEFAULT NULL COMMENT 'AppSecret最近轮换时间' AFTER `prev_app_secret_until`,
+  ADD KEY `idx_app_key` (`app_key`);
+
+-- 初始化脚本中admin用户的AppSecret已公开，替换为随机值，需轮换后才能用于签名认证
+UPDATE `kp_user`
+SET `app_secret` = LOWER(HEX(RANDOM_BYTES(16))), `prev_app_secret` = NULL, `prev_app_secret_until` = NULL, `app_secret_rotated_at` = NULL
+WHERE `app_secret` = 'c5e330214fb33e2d485f207b33e4c92f';
+
+-- 创建签名请求随机串表
+CREATE TABLE IF NOT EXISTS `kp_api_nonce` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `app_key` varchar(50) NOT NULL COMMENT 'AppKey',
+  `nonce` varchar(64) NOT NULL COMMENT '签名请求的随机串',
+  `expires_at` datetime NOT NULL COMMENT '过期时间，超过后请求时间戳已不再有效',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_app_key_nonce` (`app_key`, `nonce`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='签名请求随机串表';
+
+-- 在用户管理菜单下插入重置AppSecret按钮，需要时由管理员为角色授权
+INSERT INTO `kp_menu` (`parent_id`, `name`, `type`, `path`, `component`, `permission`, `icon`, `sort`, `visible`, `status`, `is_cache`, `is_frame`)
+SELECT m.`parent_id`, '重置AppSecret', 2, '', NULL, 'system:user:resetSecret', '#', 8, 1, 1, 0, 0
+FROM `kp_menu` m
+WHERE m.`permission` = 'system:user:resetPwd' AND m.`deleted_at` IS NULL
+  AND NOT EXISTS (SELECT 1 FROM `kp_menu` e WHERE e.`permission` = 'system:user:resetSecret' AND e.`deleted_at` IS NULL)
+LIMIT 1;
@@ -0,0 +1,11 @@
+-- 鲲鹏后台管理系统升级脚本：API目录自动注册
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- API表增加失效标记，启动时同步路由后，对应路由已不存在的API标记为失效
+ALTER TABLE `kp_api`
+  ADD COLUMN `stale` tinyint(1) DEFAULT 0 COMMENT '对应路由是否已不存在(0:否 1:是)' AFTER `status`;
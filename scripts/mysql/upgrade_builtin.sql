@@ -0,0 +1,18 @@
+-- 鲲鹏后台管理系统升级脚本：内置用户和角色保护
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 用户表和角色表增加内置标识字段
+ALTER TABLE `kp_user`
+  ADD COLUMN `is_builtin` tinyint(1) DEFAULT 0 COMMENT '是否内置(内置用户不允许删除或禁用)' AFTER `status`;
+
+ALTER TABLE `kp_role`
+  ADD COLUMN `is_builtin` tinyint(1) DEFAULT 0 COMMENT '是否内置(内置角色不允许删除、禁用或修改编码)' AFTER `status`;
+
+-- 将初始管理员用户和超级管理员角色标记为内置
+UPDATE `kp_user` SET `is_builtin` = 1 WHERE `username` = 'admin';
+UPDATE `kp_role` SET `is_builtin` = 1 WHERE `code` = 'admin';
@@ -0,0 +1,17 @@
+-- 鲲鹏后台管理系统升级脚本：多实例Casbin策略同步
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 创建Casbin策略版本表
+CREATE TABLE IF NOT EXISTS `kp_casbin_version` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `version` bigint(20) NOT NULL DEFAULT 0 COMMENT '策略版本号，策略变更时递增',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Casbin策略版本表';
+
+INSERT IGNORE INTO `kp_casbin_version` (`id`, `version`) VALUES (1, 0);
@@ -0,0 +1,43 @@
+-- 鲲鹏后台管理系统升级脚本：登录锁定策略及管理员解除锁定
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 重建登录尝试记录表，按账号和IP分别计数，原有失败记录不再保留
+DROP TABLE IF EXISTS `kp_login_attempt`;
+CREATE TABLE IF NOT EXISTS `kp_login_attempt` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `scope` varchar(20) NOT NULL COMMENT '计数范围 account:账号 ip:IP',
+  `subject` varchar(100) NOT NULL COMMENT '登录账号（用户名或手机号）或IP',
+  `attempts` int(11) NOT NULL DEFAULT 0 COMMENT '连续失败次数',
+  `lock_count` int(11) NOT NULL DEFAULT 0 COMMENT '连续锁定次数',
+  `last_try` datetime NOT NULL COMMENT '最后失败时间',
+  `last_account` varchar(100) DEFAULT NULL COMMENT '最后尝试的账号',
+  `last_ip` varchar(50) DEFAULT NULL COMMENT '最后尝试的IP',
+  `blocked_at` datetime DEFAULT NULL COMMENT '锁定时间',
+  `locked_until` datetime DEFAULT NULL COMMENT '锁定截止时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_scope_subject` (`scope`, `subject`),
+  KEY `idx_last_try` (`last_try`),
+  KEY `idx_locked_until` (`locked_until`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='登录尝试记录表';
+
+-- 在用户管理菜单下插入解除登录锁定按钮
+INSERT INTO `kp_menu` (`parent_id`, `name`, `type`, `path`, `component`, `permission`, `icon`, `sort`, `visible`, `status`, `is_cache`, `is_frame`)
+SELECT m.`parent_id`, '解除登录锁定', 2, '', NULL, 'system:user:unlock', '#', 7, 1, 1, 0, 0
+FROM `kp_menu` m
+WHERE m.`permission` = 'system:user:resetPwd' AND m.`deleted_at` IS NULL
+  AND NOT EXISTS (SELECT 1 FROM `kp_menu` e WHERE e.`permission` = 'system:user:unlock' AND e.`deleted_at` IS NULL)
+LIMIT 1;
+
+-- 已拥有重置密码按钮的角色授予解除登录锁定按钮
+INSERT IGNORE INTO `kp_role_menu` (`role_id`, `menu_id`)
+SELECT rm.`role_id`, b.`id`
+FROM `kp_role_menu` rm
+JOIN `kp_menu` m ON m.`id` = rm.`menu_id` AND m.`permission` = 'system:user:resetPwd'
+JOIN `kp_menu` b ON b.`permission` = 'system:user:unlock' AND b.`deleted_at` IS NULL;
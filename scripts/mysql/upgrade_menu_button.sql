@@ -0,0 +1,27 @@
+-- 鲲鹏后台管理系统升级脚本：用户管理按钮权限
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 在用户管理菜单下插入按钮权限
+INSERT INTO `kp_menu` (`parent_id`, `name`, `type`, `path`, `component`, `permission`, `icon`, `sort`, `visible`, `status`, `is_cache`, `is_frame`)
+SELECT m.`id`, b.`name`, 2, '', NULL, b.`permission`, '#', b.`sort`, 1, 1, 0, 0
+FROM `kp_menu` m
+JOIN (
+  SELECT '用户新增' AS `name`, 'system:user:add' AS `permission`, 1 AS `sort`
+  UNION ALL SELECT '用户修改', 'system:user:edit', 2
+  UNION ALL SELECT '用户删除', 'system:user:remove', 3
+  UNION ALL SELECT '重置密码', 'system:user:resetPwd', 4
+) b
+WHERE m.`permission` = 'system:user:list' AND m.`deleted_at` IS NULL
+  AND NOT EXISTS (SELECT 1 FROM `kp_menu` e WHERE e.`permission` = b.`permission` AND e.`deleted_at` IS NULL);
+
+-- 已拥有用户管理菜单的角色授予按钮权限，保持升级前的操作权限不变
+INSERT IGNORE INTO `kp_role_menu` (`role_id`, `menu_id`)
+SELECT rm.`role_id`, b.`id`
+FROM `kp_role_menu` rm
+JOIN `kp_menu` m ON m.`id` = rm.`menu_id` AND m.`permission` = 'system:user:list'
+JOIN `kp_menu` b ON b.`parent_id` = m.`id` AND b.`type` = 2 AND b.`deleted_at` IS NULL;
@@ -0,0 +1,38 @@
+-- 鲲鹏后台管理系统升级脚本：OIDC单点登录
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 创建单点登录授权请求记录表
+CREATE TABLE IF NOT EXISTS `kp_oidc_state` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `state` varchar(64) NOT NULL COMMENT '授权请求标识',
+  `nonce` varchar(64) NOT NULL COMMENT 'ID token中的nonce',
+  `code_verifier` varchar(128) NOT NULL COMMENT 'PKCE code_verifier',
+  `user_id` bigint(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '发起关联的用户ID，登录时为0',
+  `ip` varchar(50) NOT NULL COMMENT '请求IP',
+  `expires_at` datetime NOT NULL COMMENT '过期时间',
+  `used_at` datetime DEFAULT NULL COMMENT '使用时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_state` (`state`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='单点登录授权请求记录表';
+
+-- 创建用户外部身份表
+CREATE TABLE IF NOT EXISTS `kp_user_identity` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `user_id` bigint(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `issuer` varchar(255) NOT NULL COMMENT '身份提供方标识',
+  `subject` varchar(255) NOT NULL COMMENT '身份提供方的用户唯一标识',
+  `email` varchar(100) DEFAULT NULL COMMENT '身份提供方返回的邮箱',
+  `last_login_at` datetime DEFAULT NULL COMMENT '最近登录时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '关联时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_issuer_subject` (`issuer`, `subject`),
+  UNIQUE KEY `idx_user_issuer` (`user_id`, `issuer`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户外部身份表';
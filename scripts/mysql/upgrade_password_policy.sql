@@ -0,0 +1,26 @@
+-- 鲲鹏后台管理系统升级脚本：密码策略、密码历史及强制修改密码
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 用户表增加必须修改密码标记及密码修改时间字段
+ALTER TABLE `kp_user`
+  ADD COLUMN `must_change_password` tinyint(1) NOT NULL DEFAULT 0 COMMENT '登录后必须修改密码(管理员创建用户或重置密码后为1)' AFTER `token_version`,
+  ADD COLUMN `password_changed_at` datetime DEFAULT NULL COMMENT '密码最近修改时间' AFTER `must_change_password`;
+
+-- 已有用户的密码有效期从升级时开始计算，避免升级后所有用户立即被要求修改密码
+UPDATE `kp_user` SET `password_changed_at` = NOW() WHERE `password_changed_at` IS NULL;
+
+-- 创建密码历史表
+CREATE TABLE IF NOT EXISTS `kp_password_history` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `user_id` bigint(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `password` varchar(100) NOT NULL COMMENT '密码哈希',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '设置时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='密码历史表';
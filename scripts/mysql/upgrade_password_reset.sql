@@ -0,0 +1,25 @@
+-- 鲲鹏后台管理系统升级脚本：邮件找回密码
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 创建找回密码请求记录表
+CREATE TABLE IF NOT EXISTS `kp_password_reset` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `account` varchar(100) NOT NULL COMMENT '请求时填写的用户名或邮箱',
+  `user_id` bigint(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '用户ID，账号不存在、未绑定邮箱或超出发送限制时为0',
+  `jti` varchar(64) DEFAULT NULL COMMENT '重置凭证的jti，未发送邮件时为空',
+  `ip` varchar(50) NOT NULL COMMENT '请求IP',
+  `expires_at` datetime NOT NULL COMMENT '过期时间',
+  `used_at` datetime DEFAULT NULL COMMENT '使用时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_account` (`account`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_jti` (`jti`),
+  KEY `idx_ip` (`ip`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='找回密码请求记录表';
@@ -0,0 +1,31 @@
+-- 鲲鹏后台管理系统升级脚本：refresh token轮换及重复使用检测
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- token黑名单表增加token族字段，用于撤销同一次登录签发的全部token
+ALTER TABLE `kp_token_blacklist`
+  ADD COLUMN `family_id` varchar(64) DEFAULT NULL COMMENT '被撤销的token族ID' AFTER `token`,
+  ADD KEY `idx_family_id` (`family_id`);
+
+-- 创建refresh token签发记录表
+CREATE TABLE IF NOT EXISTS `kp_refresh_token` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `jti` varchar(64) NOT NULL COMMENT 'refresh token的jti',
+  `family_id` varchar(64) NOT NULL COMMENT 'token族ID',
+  `user_id` bigint(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `used_at` datetime DEFAULT NULL COMMENT '轮换时间',
+  `revoked_at` datetime DEFAULT NULL COMMENT '撤销时间',
+  `expires_at` datetime NOT NULL COMMENT '过期时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_jti` (`jti`),
+  KEY `idx_family_id` (`family_id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='refresh token签发记录表';
+
+-- 升级前签发的refresh token没有jti，升级后需重新登录
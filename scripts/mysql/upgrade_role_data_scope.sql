@@ -0,0 +1,22 @@
+-- 鲲鹏后台管理系统升级脚本：角色数据权限
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 角色表增加数据权限范围字段，已有角色默认为全部数据权限
+ALTER TABLE `kp_role`
+  ADD COLUMN `data_scope` tinyint(1) DEFAULT 1 COMMENT '数据权限范围(1:全部 2:自定义部门 3:本部门 4:本部门及以下 5:仅本人)' AFTER `sort`;
+
+-- 创建角色部门关联表
+CREATE TABLE IF NOT EXISTS `kp_role_dept` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `role_id` bigint(20) UNSIGNED NOT NULL COMMENT '角色ID',
+  `dept_id` bigint(20) UNSIGNED NOT NULL COMMENT '部门ID',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_role_dept` (`role_id`,`dept_id`),
+  KEY `idx_dept_id` (`dept_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='角色部门关联表';
@@ -0,0 +1,12 @@
+-- 鲲鹏后台管理系统升级脚本：角色继承
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 角色表增加上级角色字段
+ALTER TABLE `kp_role`
+  ADD COLUMN `parent_id` bigint(20) UNSIGNED DEFAULT 0 COMMENT '上级角色ID' AFTER `id`,
+  ADD KEY `idx_parent_id` (`parent_id`);
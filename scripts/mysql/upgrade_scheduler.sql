@@ -0,0 +1,62 @@
+-- 鲲鹏后台管理系统升级脚本：定时任务调度及执行记录
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 创建定时任务表
+CREATE TABLE IF NOT EXISTS `kp_job` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `name` varchar(50) NOT NULL COMMENT '任务名称',
+  `status` tinyint(1) NOT NULL DEFAULT 1 COMMENT '状态 0:已暂停 1:正常',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='定时任务表';
+
+-- 创建定时任务执行记录表
+CREATE TABLE IF NOT EXISTS `kp_job_log` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `job_name` varchar(50) NOT NULL COMMENT '任务名称',
+  `trigger` varchar(20) NOT NULL COMMENT '触发方式 schedule:按计划 manual:手动',
+  `operator` varchar(50) DEFAULT NULL COMMENT '手动触发的操作人',
+  `status` tinyint(1) NOT NULL COMMENT '状态 0:失败 1:成功 2:执行中',
+  `message` varchar(500) DEFAULT NULL COMMENT '执行结果或错误信息',
+  `started_at` datetime NOT NULL COMMENT '开始时间',
+  `finished_at` datetime DEFAULT NULL COMMENT '结束时间',
+  `duration` bigint(20) DEFAULT 0 COMMENT '耗时（毫秒）',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_job_name` (`job_name`),
+  KEY `idx_started_at` (`started_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='定时任务执行记录表';
+
+-- 在系统管理下插入定时任务菜单
+INSERT INTO `kp_menu` (`parent_id`, `name`, `type`, `path`, `component`, `permission`, `icon`, `sort`, `visible`, `status`, `is_cache`, `is_frame`)
+SELECT m.`id`, '定时任务', 1, 'job', 'monitor/job/index', 'monitor:job:list', 'job', 9, 1, 1, 0, 0
+FROM `kp_menu` m
+WHERE m.`parent_id` = 0 AND m.`path` = '/system' AND m.`deleted_at` IS NULL
+  AND NOT EXISTS (SELECT 1 FROM `kp_menu` e WHERE e.`permission` = 'monitor:job:list' AND e.`deleted_at` IS NULL);
+
+-- 在定时任务菜单下插入按钮
+INSERT INTO `kp_menu` (`parent_id`, `name`, `type`, `path`, `component`, `permission`, `icon`, `sort`, `visible`, `status`, `is_cache`, `is_frame`)
+SELECT m.`id`, '任务执行', 2, '', NULL, 'monitor:job:run', '#', 1, 1, 1, 0, 0
+FROM `kp_menu` m
+WHERE m.`permission` = 'monitor:job:list' AND m.`deleted_at` IS NULL
+  AND NOT EXISTS (SELECT 1 FROM `kp_menu` e WHERE e.`permission` = 'monitor:job:run' AND e.`deleted_at` IS NULL);
+
+INSERT INTO `kp_menu` (`parent_id`, `name`, `type`, `path`, `component`, `permission`, `icon`, `sort`, `visible`, `status`, `is_cache`, `is_frame`)
+SELECT m.`id`, '任务暂停恢复', 2, '', NULL, 'monitor:job:edit', '#', 2, 1, 1, 0, 0
+FROM `kp_menu` m
+WHERE m.`permission` = 'monitor:job:list' AND m.`deleted_at` IS NULL
+  AND NOT EXISTS (SELECT 1 FROM `kp_menu` e WHERE e.`permission` = 'monitor:job:edit' AND e.`deleted_at` IS NULL);
+
+-- 已拥有重置密码按钮的角色授予定时任务菜单及按钮
+INSERT IGNORE INTO `kp_role_menu` (`role_id`, `menu_id`)
+SELECT rm.`role_id`, b.`id`
+FROM `kp_role_menu` rm
+JOIN `kp_menu` m ON m.`id` = rm.`menu_id` AND m.`permission` = 'system:user:resetPwd'
+JOIN `kp_menu` b ON b.`permission` IN ('monitor:job:list', 'monitor:job:run', 'monitor:job:edit') AND b.`deleted_at` IS NULL;
@@ -0,0 +1,24 @@
+-- 鲲鹏后台管理系统升级脚本：手机号验证码登录
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 创建短信验证码表
+CREATE TABLE IF NOT EXISTS `kp_sms_code` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `mobile` varchar(20) NOT NULL COMMENT '手机号',
+  `scene` varchar(20) NOT NULL COMMENT '使用场景',
+  `code_hash` varchar(64) NOT NULL COMMENT '验证码哈希',
+  `ip` varchar(50) NOT NULL COMMENT '请求IP',
+  `attempts` int(11) NOT NULL DEFAULT 0 COMMENT '校验失败次数',
+  `expires_at` datetime NOT NULL COMMENT '过期时间',
+  `used_at` datetime DEFAULT NULL COMMENT '使用时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_mobile_scene` (`mobile`, `scene`),
+  KEY `idx_mobile_created_at` (`mobile`, `created_at`),
+  KEY `idx_ip_created_at` (`ip`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='短信验证码表';
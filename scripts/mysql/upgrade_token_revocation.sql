@@ -0,0 +1,29 @@
+-- 鲲鹏后台管理系统升级脚本：token黑名单改为按哈希key存储，不再保存token原文
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 增加撤销key字段
+ALTER TABLE `kp_token_blacklist`
+  ADD COLUMN `token_key` varchar(80) DEFAULT NULL COMMENT '撤销key，token的SHA-256哈希或token族ID' AFTER `id`;
+
+-- 回填撤销key：单个token使用token的SHA-256哈希，token族使用族ID
+UPDATE `kp_token_blacklist` SET `token_key` = CONCAT('fid:', `family_id`) WHERE `family_id` IS NOT NULL AND `family_id` <> '';
+UPDATE `kp_token_blacklist` SET `token_key` = CONCAT('tok:', SHA2(`token`, 256)) WHERE `token_key` IS NULL;
+
+-- 清理已过期的记录及重复的撤销key，重复时保留最晚过期的记录
+DELETE FROM `kp_token_blacklist` WHERE `expires_at` < NOW();
+DELETE b1 FROM `kp_token_blacklist` b1
+  INNER JOIN `kp_token_blacklist` b2
+  ON b1.`token_key` = b2.`token_key`
+  AND (b1.`expires_at` < b2.`expires_at` OR (b1.`expires_at` = b2.`expires_at` AND b1.`id` < b2.`id`));
+
+-- 删除token原文字段，撤销key改为非空并建立唯一索引
+ALTER TABLE `kp_token_blacklist`
+  DROP COLUMN `token`,
+  MODIFY COLUMN `token_key` varchar(80) NOT NULL COMMENT '撤销key，token的SHA-256哈希或token族ID',
+  ADD UNIQUE KEY `idx_token_key` (`token_key`),
+  ADD KEY `idx_updated_at` (`updated_at`);
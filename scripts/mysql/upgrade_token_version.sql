@@ -0,0 +1,13 @@
+-- 鲲鹏后台管理系统升级脚本：修改密码、重置密码、禁用或变更角色后使已签发的token失效
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 用户表增加token版本号字段
+ALTER TABLE `kp_user`
+  ADD COLUMN `token_version` int(10) UNSIGNED NOT NULL DEFAULT 0 COMMENT 'token版本号，递增后此前签发的token全部失效' AFTER `app_secret`;
+
+-- 升级前签发的token不包含版本号，视为版本号0，在用户首次修改密码等操作前仍然有效
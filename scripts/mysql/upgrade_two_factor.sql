@@ -0,0 +1,50 @@
+-- 鲲鹏后台管理系统升级脚本：双因素认证
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 角色表增加双因素认证要求字段
+ALTER TABLE `kp_role`
+  ADD COLUMN `require_two_factor` tinyint(1) DEFAULT 0 COMMENT '是否要求双因素认证' AFTER `is_builtin`;
+
+-- 创建用户双因素认证表
+CREATE TABLE IF NOT EXISTS `kp_user_two_factor` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` bigint(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `secret` varchar(64) NOT NULL COMMENT 'TOTP密钥',
+  `enabled` tinyint(1) DEFAULT 0 COMMENT '是否已启用',
+  `last_step` bigint(20) NOT NULL DEFAULT 0 COMMENT '最近一次校验通过的时间步',
+  `enabled_at` datetime DEFAULT NULL COMMENT '启用时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户双因素认证表';
+
+-- 创建双因素认证恢复码表
+CREATE TABLE IF NOT EXISTS `kp_user_recovery_code` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` bigint(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `code_hash` varchar(64) NOT NULL COMMENT '恢复码哈希',
+  `used_at` datetime DEFAULT NULL COMMENT '使用时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id_code_hash` (`user_id`, `code_hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='双因素认证恢复码表';
+
+-- 在用户管理菜单下插入重置双因素认证按钮
+INSERT INTO `kp_menu` (`parent_id`, `name`, `type`, `path`, `component`, `permission`, `icon`, `sort`, `visible`, `status`, `is_cache`, `is_frame`)
+SELECT m.`id`, '重置双因素认证', 2, '', NULL, 'system:user:reset2fa', '#', 5, 1, 1, 0, 0
+FROM `kp_menu` m
+WHERE m.`permission` = 'system:user:list' AND m.`deleted_at` IS NULL
+  AND NOT EXISTS (SELECT 1 FROM `kp_menu` e WHERE e.`permission` = 'system:user:reset2fa' AND e.`deleted_at` IS NULL);
+
+-- 已拥有重置密码按钮的角色授予重置双因素认证按钮
+INSERT IGNORE INTO `kp_role_menu` (`role_id`, `menu_id`)
+SELECT rm.`role_id`, b.`id`
+FROM `kp_role_menu` rm
+JOIN `kp_menu` m ON m.`id` = rm.`menu_id` AND m.`permission` = 'system:user:resetPwd'
+JOIN `kp_menu` b ON b.`permission` = 'system:user:reset2fa' AND b.`deleted_at` IS NULL;
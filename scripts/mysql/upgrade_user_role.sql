@@ -0,0 +1,27 @@
+-- 鲲鹏后台管理系统升级脚本：用户单角色迁移为多角色
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 创建用户角色关联表
+CREATE TABLE IF NOT EXISTS `kp_user_role` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` bigint(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `role_id` bigint(20) UNSIGNED NOT NULL COMMENT '角色ID',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_user_role` (`user_id`,`role_id`),
+  KEY `idx_role_id` (`role_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户角色关联表';
+
+-- 迁移用户原有角色
+INSERT IGNORE INTO `kp_user_role` (`user_id`, `role_id`)
+SELECT `id`, `role_id` FROM `kp_user` WHERE `role_id` IS NOT NULL AND `role_id` > 0;
+
+-- 删除用户表中的角色字段
+ALTER TABLE `kp_user` DROP INDEX `idx_role_id`, DROP COLUMN `role_id`;
+
+-- 执行完成后，调用 POST /api/v1/roles/policies/rebuild 重建Casbin策略和用户角色分组
@@ -0,0 +1,48 @@
+-- 鲲鹏后台管理系统升级脚本：用户登录会话管理
+
+-- 使用数据库
+USE kunpeng;
+
+-- 设置字符集
+SET NAMES utf8mb4;
+
+-- 创建用户登录会话表
+CREATE TABLE IF NOT EXISTS `kp_user_session` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `session_id` varchar(64) NOT NULL COMMENT '会话ID，即token族ID',
+  `user_id` bigint(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `username` varchar(50) NOT NULL COMMENT '用户名',
+  `jti` varchar(64) NOT NULL COMMENT '最近签发的refresh token的jti',
+  `ip` varchar(50) DEFAULT NULL COMMENT '最近访问IP',
+  `browser` varchar(50) DEFAULT NULL COMMENT '浏览器',
+  `os` varchar(50) DEFAULT NULL COMMENT '操作系统',
+  `device` varchar(50) DEFAULT NULL COMMENT '设备类型',
+  `user_agent` varchar(500) DEFAULT NULL COMMENT '原始User-Agent',
+  `login_at` datetime NOT NULL COMMENT '登录时间',
+  `last_seen_at` datetime NOT NULL COMMENT '最近活跃时间',
+  `expires_at` datetime NOT NULL COMMENT '过期时间',
+  `revoked_at` datetime DEFAULT NULL COMMENT '撤销时间',
+  `revoke_reason` varchar(255) DEFAULT NULL COMMENT '撤销原因',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_session_id` (`session_id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户登录会话表';
+
+-- 在用户管理菜单下插入强制下线按钮
+INSERT INTO `kp_menu` (`parent_id`, `name`, `type`, `path`, `component`, `permission`, `icon`, `sort`, `visible`, `status`, `is_cache`, `is_frame`)
+SELECT m.`id`, '强制下线', 2, '', NULL, 'system:user:forceLogout', '#', 6, 1, 1, 0, 0
+FROM `kp_menu` m
+WHERE m.`permission` = 'system:user:list' AND m.`deleted_at` IS NULL
+  AND NOT EXISTS (SELECT 1 FROM `kp_menu` e WHERE e.`permission` = 'system:user:forceLogout' AND e.`deleted_at` IS NULL);
+
+-- 已拥有重置密码按钮的角色授予强制下线按钮
+INSERT IGNORE INTO `kp_role_menu` (`role_id`, `menu_id`)
+SELECT rm.`role_id`, b.`id`
+FROM `kp_role_menu` rm
+JOIN `kp_menu` m ON m.`id` = rm.`menu_id` AND m.`permission` = 'system:user:resetPwd'
+JOIN `kp_menu` b ON b.`permission` = 'system:user:forceLogout' AND b.`deleted_at` IS NULL;
+
+-- 升级前的登录没有会话记录，在token过期前仍然有效，但不会出现在会话列表中